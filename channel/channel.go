@@ -0,0 +1,227 @@
+// Package channel implements the channel which routes the probe results to the notifiers
+package channel
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/megaease/easeprobe/notify"
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// Channel implements the channel interface
+type Channel struct {
+	Name      string                   `yaml:"name"`
+	Probers   map[string]probe.Prober  `yaml:"probers"`
+	Notifiers map[string]notify.Notify `yaml:"notifiers"`
+	// DigestWindow groups the notifications in the window into one digest, 0 means no digest
+	DigestWindow time.Duration `yaml:"digest"`
+	isWatch      int32
+	done         chan bool
+	channel      chan probe.Result
+}
+
+// NewEmpty creates a new empty channel with the name
+func NewEmpty(name string) *Channel {
+	return &Channel{
+		Name:      name,
+		Probers:   map[string]probe.Prober{},
+		Notifiers: map[string]notify.Notify{},
+		isWatch:   0,
+		done:      make(chan bool),
+		channel:   make(chan probe.Result),
+	}
+}
+
+// Config configures the channel, the buffer of the channel is the number of probers
+func (c *Channel) Config() {
+	c.channel = make(chan probe.Result, len(c.Probers))
+}
+
+// SetProber sets the prober into the channel
+func (c *Channel) SetProber(p probe.Prober) {
+	c.Probers[p.Name()] = p
+}
+
+// SetProbers sets the probers into the channel
+func (c *Channel) SetProbers(probers []probe.Prober) {
+	for _, p := range probers {
+		c.SetProber(p)
+	}
+}
+
+// GetProber returns the prober by the name
+func (c *Channel) GetProber(name string) probe.Prober {
+	if p, ok := c.Probers[name]; ok {
+		return p
+	}
+	return nil
+}
+
+// SetNotify sets the notifier into the channel
+func (c *Channel) SetNotify(n notify.Notify) {
+	c.Notifiers[n.Name()] = n
+}
+
+// SetNotifiers sets the notifiers into the channel
+func (c *Channel) SetNotifiers(notifiers []notify.Notify) {
+	for _, n := range notifiers {
+		c.SetNotify(n)
+	}
+}
+
+// GetNotify returns the notifier by the name
+func (c *Channel) GetNotify(name string) notify.Notify {
+	if n, ok := c.Notifiers[name]; ok {
+		return n
+	}
+	return nil
+}
+
+// IsWatching returns true if the channel is watching the events
+func (c *Channel) IsWatching() bool {
+	return atomic.LoadInt32(&c.isWatch) == 1
+}
+
+// Send sends the result to the channel
+func (c *Channel) Send(result probe.Result) {
+	if !c.IsWatching() {
+		log.Warnf("[%s / %s] - the channel is not watching, drop the result of [%s]",
+			kind, c.Name, result.Name)
+		return
+	}
+	c.channel <- result
+}
+
+// Done stops the channel watching
+func (c *Channel) Done() {
+	if !c.IsWatching() {
+		return
+	}
+	c.done <- true
+}
+
+// NeedToNotify returns true if the result need to be sent to the notifiers
+// - the status is changed, e.g. UP to DOWN (failure) or DOWN to UP (recovery)
+// - the notification strategy decides to send the alert again
+// no notification is sent if the probe is in a maintenance window or muted
+func NeedToNotify(result probe.Result) bool {
+	if result.Maintenance != "" || result.Control.IsMuted(result.StartTime) {
+		return false
+	}
+	if result.PreStatus != result.Status {
+		return true
+	}
+	return result.Stat.NotificationStrategyData.NeedToSendNotification()
+}
+
+// NeedToNotifyBurn returns true if the error budget burn rate alert is raised, changed or resolved,
+// the same as the status notification, it is not sent in a maintenance window or muted
+func NeedToNotifyBurn(result probe.Result) bool {
+	if result.Maintenance != "" || result.Control.IsMuted(result.StartTime) {
+		return false
+	}
+	return result.SLO.IsChanged()
+}
+
+// WatchEvent starts a goroutine to watch the probe results, and dispatches them to the notifiers
+func (c *Channel) WatchEvent(wg *sync.WaitGroup) {
+	if !atomic.CompareAndSwapInt32(&c.isWatch, 0, 1) {
+		log.Warnf("[%s / %s] - the channel is already watching!", kind, c.Name)
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer atomic.StoreInt32(&c.isWatch, 0)
+		c.watch()
+	}()
+}
+
+func (c *Channel) watch() {
+	log.Infof("[%s / %s] - start watching the events, %d probers, %d notifiers",
+		kind, c.Name, len(c.Probers), len(c.Notifiers))
+	var (
+		buf   digest
+		flush <-chan time.Time
+	)
+	for {
+		select {
+		case <-c.done:
+			log.Infof("[%s / %s] - received the done signal, exiting...", kind, c.Name)
+			c.dispatchDigest(&buf)
+			return
+		case <-flush:
+			c.dispatchDigest(&buf)
+			flush = nil
+		case result := <-c.channel:
+			if NeedToNotifyBurn(result) {
+				c.dispatchBurn(result)
+			}
+			if !NeedToNotify(result) {
+				log.Debugf("[%s / %s] - %s (%s) no need to notify - status [%s], alert %+v",
+					kind, c.Name, result.Name, result.Endpoint, result.Status,
+					result.Stat.NotificationStrategyData)
+				continue
+			}
+			if c.DigestWindow <= 0 {
+				c.dispatch(result)
+				continue
+			}
+			log.Debugf("[%s / %s] - %s (%s) status [%s] -> [%s], added into the digest",
+				kind, c.Name, result.Name, result.Endpoint, result.PreStatus, result.Status)
+			buf.add(result)
+			if flush == nil {
+				flush = time.After(c.DigestWindow)
+			}
+		}
+	}
+}
+
+// dispatch sends the result to all of the notifiers
+func (c *Channel) dispatch(result probe.Result) {
+	log.Infof("[%s / %s] - %s (%s) status [%s] -> [%s], dispatching to %d notifiers",
+		kind, c.Name, result.Name, result.Endpoint, result.PreStatus, result.Status, len(c.Notifiers))
+	for _, n := range c.Notifiers {
+		go n.Notify(result)
+	}
+}
+
+// dispatchBurn sends the burn rate alert to the notifiers which support it, it is never grouped into the digest
+func (c *Channel) dispatchBurn(result probe.Result) {
+	log.Infof("[%s / %s] - %s (%s) error budget burn [%s] -> [%s], dispatching to %d notifiers",
+		kind, c.Name, result.Name, result.Endpoint, result.SLO.PreAlert, result.SLO.Alert, len(c.Notifiers))
+	for _, n := range c.Notifiers {
+		if b, ok := n.(notify.Burn); ok {
+			go b.NotifyBurn(result)
+		}
+	}
+}
+
+// dispatchDigest sends the grouped failures and recoveries to all of the notifiers, and resets the digest
+// the single result is sent as the normal notification
+func (c *Channel) dispatchDigest(buf *digest) {
+	defer buf.reset()
+	for _, results := range [][]probe.Result{buf.failures, buf.recoveries} {
+		if len(results) == 0 {
+			continue
+		}
+		if len(results) == 1 {
+			c.dispatch(results[0])
+			continue
+		}
+		log.Infof("[%s / %s] - %d probes are grouped into the digest, dispatching to %d notifiers",
+			kind, c.Name, len(results), len(c.Notifiers))
+		for _, n := range c.Notifiers {
+			if d, ok := n.(notify.Digest); ok {
+				go d.NotifyDigest(results)
+				continue
+			}
+			for _, r := range results {
+				go n.Notify(r)
+			}
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package channel
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyProber struct {
+	name     string
+	channels []string
+	result   *probe.Result
+}
+
+func (d *dummyProber) LabelMap() prometheus.Labels       { return nil }
+func (d *dummyProber) SetLabelMap(prometheus.Labels)     {}
+func (d *dummyProber) Kind() string                      { return "dummy" }
+func (d *dummyProber) Name() string                      { return d.name }
+func (d *dummyProber) Channels() []string                { return d.channels }
+func (d *dummyProber) Timeout() time.Duration            { return time.Second }
+func (d *dummyProber) Interval() time.Duration           { return time.Second }
+func (d *dummyProber) Result() *probe.Result             { return d.result }
+func (d *dummyProber) Config(global.ProbeSettings) error { return nil }
+func (d *dummyProber) Probe() probe.Result               { return *d.result }
+
+func newDummyProber(name string, channels ...string) *dummyProber {
+	r := probe.NewResult()
+	r.Name = name
+	return &dummyProber{name: name, channels: channels, result: r}
+}
+
+type dummyNotify struct {
+	name     string
+	channels []string
+	mutex    sync.Mutex
+	results  []probe.Result
+}
+
+func (d *dummyNotify) Kind() string                       { return "dummy" }
+func (d *dummyNotify) Name() string                       { return d.name }
+func (d *dummyNotify) Channels() []string                 { return d.channels }
+func (d *dummyNotify) Config(global.NotifySettings) error { return nil }
+func (d *dummyNotify) Notify(r probe.Result) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.results = append(d.results, r)
+}
+func (d *dummyNotify) Count() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.results)
+}
+
+func newDummyNotify(name string, channels ...string) *dummyNotify {
+	return &dummyNotify{name: name, channels: channels}
+}
+
+var _ notify.Notify = (*dummyNotify)(nil)
+var _ probe.Prober = (*dummyProber)(nil)
+
+func TestNeedToNotify(t *testing.T) {
+	r := probe.NewResult()
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusUp
+	assert.False(t, NeedToNotify(*r))
+
+	r.Status = probe.StatusDown
+	assert.True(t, NeedToNotify(*r))
+
+	r.PreStatus = probe.StatusDown
+	assert.False(t, NeedToNotify(*r))
+
+	r.Stat.NotificationStrategyData.IsSent = true
+	assert.True(t, NeedToNotify(*r))
+
+	// no notification in the maintenance window
+	r.Maintenance = "upgrade"
+	assert.False(t, NeedToNotify(*r))
+	r.PreStatus = probe.StatusUp
+	assert.False(t, NeedToNotify(*r))
+
+	// no notification if the probe is muted
+	r.Maintenance = ""
+	r.StartTime = time.Now()
+	assert.True(t, NeedToNotify(*r))
+	r.Control.MutedUntil = r.StartTime.Add(time.Hour)
+	assert.False(t, NeedToNotify(*r))
+	r.Control.MutedUntil = r.StartTime.Add(-time.Hour)
+	assert.True(t, NeedToNotify(*r))
+}
+
+func TestChannel(t *testing.T) {
+	c := NewEmpty("test")
+	p := newDummyProber("probe")
+	c.SetProbers([]probe.Prober{p})
+	assert.Equal(t, p, c.GetProber("probe"))
+	assert.Nil(t, c.GetProber("none"))
+
+	n := newDummyNotify("notify")
+	c.SetNotifiers([]notify.Notify{n})
+	assert.Equal(t, n, c.GetNotify("notify"))
+	assert.Nil(t, c.GetNotify("none"))
+
+	c.Config()
+
+	// not watching, the result is dropped
+	r := *p.result
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	c.Send(r)
+	c.Done()
+	assert.Equal(t, 0, n.Count())
+
+	var wg sync.WaitGroup
+	c.WatchEvent(&wg)
+	assert.True(t, c.IsWatching())
+	// watch twice does nothing
+	c.WatchEvent(&wg)
+
+	c.Send(r)
+	// no status change, no notification
+	r.PreStatus = probe.StatusDown
+	c.Send(r)
+	// recovery
+	r.Status = probe.StatusUp
+	c.Send(r)
+
+	assert.Eventually(t, func() bool { return n.Count() == 2 }, time.Second, 10*time.Millisecond)
+
+	c.Done()
+	wg.Wait()
+	assert.False(t, c.IsWatching())
+}
+
+type dummyBurnNotify struct {
+	dummyNotify
+	burns []probe.Result
+}
+
+func (d *dummyBurnNotify) NotifyBurn(r probe.Result) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.burns = append(d.burns, r)
+}
+
+func (d *dummyBurnNotify) Burns() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.burns)
+}
+
+var _ notify.Burn = (*dummyBurnNotify)(nil)
+
+func TestNeedToNotifyBurn(t *testing.T) {
+	r := probe.NewResult()
+	assert.False(t, NeedToNotifyBurn(*r))
+
+	r.SLO = &probe.SLOStatus{Alert: probe.BurnFast, PreAlert: probe.BurnNone}
+	assert.True(t, NeedToNotifyBurn(*r))
+	r.SLO.PreAlert = probe.BurnFast
+	assert.False(t, NeedToNotifyBurn(*r))
+	r.SLO.Alert = probe.BurnNone
+	assert.True(t, NeedToNotifyBurn(*r))
+
+	r.Maintenance = "upgrade"
+	assert.False(t, NeedToNotifyBurn(*r))
+	r.Maintenance = ""
+	r.StartTime = time.Now()
+	r.Control.MutedUntil = r.StartTime.Add(time.Hour)
+	assert.False(t, NeedToNotifyBurn(*r))
+}
+
+func TestBurnChannel(t *testing.T) {
+	c := NewEmpty("burn")
+	n1 := &dummyBurnNotify{dummyNotify: dummyNotify{name: "burn"}}
+	n2 := newDummyNotify("plain")
+	c.SetNotifiers([]notify.Notify{n1, n2})
+	c.DigestWindow = time.Hour
+	c.Config()
+
+	var wg sync.WaitGroup
+	c.WatchEvent(&wg)
+
+	// the burn alert is sent immediately, and only to the notifiers which support it
+	r := newResult("probe", probe.StatusUp, probe.StatusUp)
+	r.SLO = &probe.SLOStatus{Alert: probe.BurnSlow, PreAlert: probe.BurnNone}
+	c.Send(r)
+	assert.Eventually(t, func() bool { return n1.Burns() == 1 }, time.Second, 10*time.Millisecond)
+
+	c.Done()
+	wg.Wait()
+	assert.Equal(t, 0, n1.Count())
+	assert.Equal(t, 0, n2.Count())
+}
@@ -0,0 +1,31 @@
+package channel
+
+import "github.com/megaease/easeprobe/probe"
+
+// digest buffers the results of the channel in the digest window,
+// the failures and the recoveries are grouped into the different messages
+type digest struct {
+	failures   []probe.Result
+	recoveries []probe.Result
+}
+
+// add adds the result into the digest, the previous result of the same probe is replaced
+func (d *digest) add(r probe.Result) {
+	list := &d.failures
+	if r.Status == probe.StatusUp {
+		list = &d.recoveries
+	}
+	for i := range *list {
+		if (*list)[i].Name == r.Name {
+			(*list)[i] = r
+			return
+		}
+	}
+	*list = append(*list, r)
+}
+
+// reset clears the digest
+func (d *digest) reset() {
+	d.failures = nil
+	d.recoveries = nil
+}
@@ -0,0 +1,109 @@
+package channel
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyDigestNotify struct {
+	dummyNotify
+	digests [][]probe.Result
+}
+
+func (d *dummyDigestNotify) NotifyDigest(results []probe.Result) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.digests = append(d.digests, results)
+}
+
+func (d *dummyDigestNotify) Digests() [][]probe.Result {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.digests
+}
+
+var _ notify.Digest = (*dummyDigestNotify)(nil)
+
+func newResult(name string, pre, status probe.Status) probe.Result {
+	r := probe.NewResult()
+	r.Name = name
+	r.PreStatus = pre
+	r.Status = status
+	return *r
+}
+
+func TestDigestAdd(t *testing.T) {
+	var d digest
+	d.add(newResult("a", probe.StatusUp, probe.StatusDown))
+	d.add(newResult("b", probe.StatusUp, probe.StatusDown))
+	d.add(newResult("a", probe.StatusDown, probe.StatusDown))
+	d.add(newResult("c", probe.StatusDown, probe.StatusUp))
+	assert.Equal(t, 2, len(d.failures))
+	assert.Equal(t, probe.StatusDown, d.failures[0].PreStatus)
+	assert.Equal(t, 1, len(d.recoveries))
+
+	d.reset()
+	assert.Equal(t, 0, len(d.failures)+len(d.recoveries))
+}
+
+func TestDigest(t *testing.T) {
+	c := NewEmpty("digest")
+	n1 := &dummyDigestNotify{dummyNotify: dummyNotify{name: "digest"}}
+	n2 := newDummyNotify("plain")
+	c.SetNotifiers([]notify.Notify{n1, n2})
+	c.Config()
+	c.DigestWindow = 100 * time.Millisecond
+
+	var wg sync.WaitGroup
+	c.WatchEvent(&wg)
+
+	// the failures in the window are grouped
+	for _, name := range []string{"a", "b", "c"} {
+		c.Send(newResult(name, probe.StatusUp, probe.StatusDown))
+	}
+	assert.Eventually(t, func() bool { return len(n1.Digests()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, len(n1.Digests()[0]))
+	assert.Equal(t, 0, n1.Count())
+	// the notifier without the digest receives the results one by one
+	assert.Eventually(t, func() bool { return n2.Count() == 3 }, time.Second, 10*time.Millisecond)
+
+	// the recoveries are grouped as well
+	for _, name := range []string{"a", "b", "c"} {
+		c.Send(newResult(name, probe.StatusDown, probe.StatusUp))
+	}
+	assert.Eventually(t, func() bool { return len(n1.Digests()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.True(t, n1.Digests()[1][0].Status == probe.StatusUp)
+
+	// the single result is sent as the normal notification
+	c.Send(newResult("a", probe.StatusUp, probe.StatusDown))
+	assert.Eventually(t, func() bool { return n1.Count() == 1 }, time.Second, 10*time.Millisecond)
+
+	// the pending results are sent when the channel is done
+	c.DigestWindow = time.Hour
+	c.Send(newResult("b", probe.StatusUp, probe.StatusDown))
+	c.Send(newResult("c", probe.StatusUp, probe.StatusDown))
+	c.Done()
+	wg.Wait()
+	assert.Eventually(t, func() bool { return len(n1.Digests()) == 3 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSetDigest(t *testing.T) {
+	RemoveAllChannels()
+	defer RemoveAllChannels()
+
+	SetProbers([]probe.Prober{newDummyProber("p1", "dev", "ops")})
+	SetDigest(global.Digest{Window: time.Minute, Channels: []string{"ops"}})
+	assert.Equal(t, time.Duration(0), GetChannel("dev").DigestWindow)
+	assert.Equal(t, time.Minute, GetChannel("ops").DigestWindow)
+
+	SetDigest(global.Digest{Window: time.Minute})
+	assert.Equal(t, time.Minute, GetChannel("dev").DigestWindow)
+	SetDigest(global.Digest{})
+	assert.Equal(t, time.Duration(0), GetChannel("ops").DigestWindow)
+}
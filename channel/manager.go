@@ -0,0 +1,122 @@
+package channel
+
+import (
+	"sync"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify"
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+const kind = "channel"
+
+var (
+	channel = map[string]*Channel{}
+	wg      sync.WaitGroup
+)
+
+// GetAllChannels returns all channels
+func GetAllChannels() map[string]*Channel {
+	return channel
+}
+
+// GetChannel returns the channel by the name
+func GetChannel(name string) *Channel {
+	if c, ok := channel[name]; ok {
+		return c
+	}
+	return nil
+}
+
+// RemoveAllChannels removes all channels
+// Note: it is only used by the test and the graceful restart
+func RemoveAllChannels() {
+	channel = map[string]*Channel{}
+}
+
+func getOrCreate(name string) *Channel {
+	if _, ok := channel[name]; !ok {
+		channel[name] = NewEmpty(name)
+	}
+	return channel[name]
+}
+
+// SetProber sets the prober into all of its channels
+func SetProber(p probe.Prober) {
+	for _, name := range p.Channels() {
+		getOrCreate(name).SetProber(p)
+	}
+}
+
+// SetProbers sets the probers into their channels
+func SetProbers(probers []probe.Prober) {
+	for _, p := range probers {
+		SetProber(p)
+	}
+}
+
+// SendResult sends the probe result to all of the channels of the prober
+func SendResult(p probe.Prober, result probe.Result) {
+	for _, name := range p.Channels() {
+		if c := GetChannel(name); c != nil {
+			c.Send(result)
+		}
+	}
+}
+
+// SetNotify sets the notifier into all of its channels
+func SetNotify(n notify.Notify) {
+	for _, name := range n.Channels() {
+		getOrCreate(name).SetNotify(n)
+	}
+}
+
+// SetNotifiers sets the notifiers into their channels
+func SetNotifiers(notifiers []notify.Notify) {
+	for _, n := range notifiers {
+		SetNotify(n)
+	}
+}
+
+// ConfigAllChannels configures all of the channels
+func ConfigAllChannels() {
+	for name, c := range channel {
+		if len(c.Notifiers) == 0 {
+			log.Warnf("[%s / %s] - no notifiers in the channel, %d probers' results would be ignored",
+				kind, name, len(c.Probers))
+		}
+		if len(c.Probers) == 0 {
+			log.Warnf("[%s / %s] - no probers in the channel, %d notifiers would never be triggered",
+				kind, name, len(c.Notifiers))
+		}
+		c.Config()
+	}
+}
+
+// SetDigest sets the digest window of the channels
+func SetDigest(d global.Digest) {
+	for name, c := range channel {
+		if !d.Enabled(name) {
+			c.DigestWindow = 0
+			continue
+		}
+		c.DigestWindow = d.Window
+		log.Infof("[%s / %s] - the notifications are grouped into the digest every %s", kind, name, d.Window)
+	}
+}
+
+// WatchForAllEvents starts all of the channels to watch the events
+func WatchForAllEvents() {
+	for _, c := range channel {
+		c.WatchEvent(&wg)
+	}
+}
+
+// AllDone stops all of the channels, and waits for them to exit
+func AllDone() {
+	for _, c := range channel {
+		c.Done()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,76 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager(t *testing.T) {
+	RemoveAllChannels()
+	defer RemoveAllChannels()
+
+	p1 := newDummyProber("p1", "dev")
+	p2 := newDummyProber("p2", "dev", "ops")
+	p3 := newDummyProber("p3", global.DefaultChannelName)
+	SetProbers([]probe.Prober{p1, p2, p3})
+
+	n1 := newDummyNotify("n1", "dev")
+	n2 := newDummyNotify("n2", "ops")
+	n3 := newDummyNotify("n3", "lonely")
+	SetNotifiers([]notify.Notify{n1, n2, n3})
+
+	assert.Equal(t, 4, len(GetAllChannels()))
+	assert.Equal(t, 2, len(GetChannel("dev").Probers))
+	assert.Equal(t, 1, len(GetChannel("ops").Probers))
+	assert.Equal(t, 0, len(GetChannel("lonely").Probers))
+	assert.Equal(t, 0, len(GetChannel(global.DefaultChannelName).Notifiers))
+	assert.Nil(t, GetChannel("none"))
+
+	ConfigAllChannels()
+	WatchForAllEvents()
+
+	r := *p2.result
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	for _, name := range p2.Channels() {
+		GetChannel(name).Send(r)
+	}
+	assert.Eventually(t, func() bool {
+		return n1.Count() == 1 && n2.Count() == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, n3.Count())
+
+	AllDone()
+	for _, c := range GetAllChannels() {
+		assert.False(t, c.IsWatching())
+	}
+}
+
+func TestSendResult(t *testing.T) {
+	RemoveAllChannels()
+	defer RemoveAllChannels()
+
+	p := newDummyProber("p", "dev", "ops")
+	SetProbers([]probe.Prober{p})
+	n1 := newDummyNotify("n1", "dev")
+	n2 := newDummyNotify("n2", "ops")
+	n3 := newDummyNotify("n3", "lonely")
+	SetNotifiers([]notify.Notify{n1, n2, n3})
+	ConfigAllChannels()
+	WatchForAllEvents()
+	defer AllDone()
+
+	r := *p.result
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	SendResult(p, r)
+	assert.Eventually(t, func() bool {
+		return n1.Count() == 1 && n2.Count() == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, n3.Count())
+}
@@ -12,11 +12,15 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/megaease/easeprobe/channel"
 	"github.com/megaease/easeprobe/conf"
 	"github.com/megaease/easeprobe/daemon"
 	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/health"
+	"github.com/megaease/easeprobe/maintenance"
 	"github.com/megaease/easeprobe/metric"
 	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/statuspage"
 	"github.com/megaease/easeprobe/web"
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -87,6 +91,7 @@ func main() {
 	}, prometheus.Labels{})).Set(float64(time.Now().Unix()))
 	currentTS := time.Now().Unix()
 	log.Infof("Current timestamp: %d (%s)", currentTS, time.Unix(currentTS, 0).Format(time.RFC3339))
+	health.SetConfig(*yamlFile)
 
 	// Create the pid file if the file name is not empty
 	c.Settings.PIDFile = strings.TrimSpace(c.Settings.PIDFile)
@@ -110,8 +115,21 @@ func main() {
 
 	// if dry notification mode is specified in command line, overwrite the configuration
 	if *dryNotify {
+		c.Settings.Notify.Dry = true
+	}
+	if c.Settings.Notify.Dry {
 		log.Infoln("Dry Notification Mode...")
 	}
+
+	////////////////////////////////////////////////////////////////////////////
+	//                   Load the probe results from data file                //
+	////////////////////////////////////////////////////////////////////////////
+	// the results must be loaded before the probers are configured
+	file := dataFile(c.Settings.SLAReport)
+	loadData(file, c.Settings.SLAReport.Backups)
+	// the time-series store of every probe execution
+	openStore(c.Settings.Store)
+
 	////////////////////////////////////////////////////////////////////////////
 	//                          Start the HTTP Server                         //
 	////////////////////////////////////////////////////////////////////////////
@@ -122,6 +140,11 @@ func main() {
 	//                  Configure all of Probers and Notifiers                //
 	////////////////////////////////////////////////////////////////////////////
 
+	// Maintenance Windows, they must be ready before the probers start
+	maintenance.SetWindows(c.Maintenance)
+	// Public Status Page
+	statuspage.SetPage(c.StatusPage)
+
 	// Probers
 	probers := c.AllProbers()
 	// Configure the Probes
@@ -129,6 +152,19 @@ func main() {
 	if len(probers) == 0 {
 		log.Fatal("No probes configured, exiting...")
 	}
+	// remove the results of the probers which are not in the configuration anymore
+	probe.CleanData(probers)
+
+	// Notifiers
+	notifies := c.AllNotifiers()
+	// Configure the Notifiers
+	notifies = configNotifiers(notifies)
+	if len(notifies) == 0 {
+		log.Warn("No notifiers configured, the probe results would not be sent...")
+	}
+
+	// Configure the Channels which route the probe results to the notifiers
+	configChannels(probers, notifies)
 
 	////////////////////////////////////////////////////////////////////////////
 	//                          Start the EaseProbe                           //
@@ -138,15 +174,25 @@ func main() {
 	var wg sync.WaitGroup
 	// the exit channel for all probers
 	doneProbe := make(chan bool, len(probers))
+	// 1) Start the Channels to watch the probe results
+	channel.WatchForAllEvents()
 	// 2) Start the Probers
 	doneSave := make(chan bool)
-	// the channel for saving the probe result data
-	saveChannel := make(chan probe.Result, len(probers))
 
-	runProbers(probers, &wg, doneProbe, saveChannel)
+	runProbers(probers, &wg, doneProbe)
+	// 3) Start the data saving
+	var wgSave sync.WaitGroup
+	wgSave.Add(1)
+	go saveData(file, probers, doneSave, &wgSave)
 	// 4) Set probers into web server
 	web.SetProbers(probers)
 
+	////////////////////////////////////////////////////////////////////////////
+	//                          Scheduled SLA Report                          //
+	////////////////////////////////////////////////////////////////////////////
+	doneSLA := make(chan bool, 1)
+	go scheduleSLA(probers, doneSLA)
+
 	////////////////////////////////////////////////////////////////////////////
 	//                          Rotate the log file                           //
 	////////////////////////////////////////////////////////////////////////////
@@ -161,11 +207,13 @@ func main() {
 				log.Info("Received the exit signal, Rotating log file process exiting...")
 				c.Settings.Log.Close()
 				c.Settings.HTTPServer.AccessLog.Close()
+				closeNotifiers(notifies)
 				return
 			case <-rotateLog:
 				log.Info("Received SIGHUP, rotating the log file...")
 				c.Settings.Log.Rotate()
 				c.Settings.HTTPServer.AccessLog.Rotate()
+				rotateNotifiers(notifies)
 			}
 		}
 	}()
@@ -180,12 +228,16 @@ func main() {
 	exit := func() {
 		web.Shutdown()
 		for i := 0; i < len(probers); i++ {
-			if probers[i].Result().Status != probe.StatusBad {
+			if probe.Snapshot(probers[i]).Status != probe.StatusBad {
 				doneProbe <- true
 			}
 		}
 		wg.Wait()
+		channel.AllDone()
+		doneSLA <- true
 		doneSave <- true
+		wgSave.Wait()
+		closeStore()
 		doneRotate <- true
 	}
 
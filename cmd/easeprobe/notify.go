@@ -0,0 +1,58 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/megaease/easeprobe/channel"
+	"github.com/megaease/easeprobe/conf"
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify"
+	"github.com/megaease/easeprobe/probe"
+)
+
+func configNotifiers(notifies []notify.Notify) []notify.Notify {
+	gNotifyConf := global.NotifySettings{
+		TimeFormat: conf.Get().Settings.TimeFormat,
+		Timeout:    conf.Get().Settings.Notify.Timeout,
+		Retry:      conf.Get().Settings.Notify.Retry,
+		Dry:        conf.Get().Settings.Notify.Dry,
+	}
+	log.Debugf("Global Notification Configuration: %+v", gNotifyConf)
+
+	validNotifiers := []notify.Notify{}
+	for i := 0; i < len(notifies); i++ {
+		n := notifies[i]
+		if err := n.Config(gNotifyConf); err != nil {
+			log.Errorf("Bad Notify Configuration for notifier %s %s: %v", n.Kind(), n.Name(), err)
+			continue
+		}
+		validNotifiers = append(validNotifiers, n)
+	}
+
+	return validNotifiers
+}
+
+func configChannels(probers []probe.Prober, notifies []notify.Notify) {
+	channel.SetProbers(probers)
+	channel.SetNotifiers(notifies)
+	channel.ConfigAllChannels()
+	channel.SetDigest(conf.Get().Settings.Notify.Digest)
+}
+
+// rotateNotifiers rotates the files which are written by the notifiers (e.g. log notification)
+func rotateNotifiers(notifies []notify.Notify) {
+	for _, n := range notifies {
+		if r, ok := n.(interface{ Rotate() }); ok {
+			r.Rotate()
+		}
+	}
+}
+
+// closeNotifiers closes the files or connections which are held by the notifiers
+func closeNotifiers(notifies []notify.Notify) {
+	for _, n := range notifies {
+		if c, ok := n.(interface{ Close() }); ok {
+			c.Close()
+		}
+	}
+}
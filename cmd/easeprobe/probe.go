@@ -6,9 +6,12 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/megaease/easeprobe/channel"
 	"github.com/megaease/easeprobe/conf"
 	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/health"
 	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/store"
 )
 
 func configProbers(probers []probe.Prober) []probe.Prober {
@@ -17,6 +20,7 @@ func configProbers(probers []probe.Prober) []probe.Prober {
 	gProbeConf := global.ProbeSettings{
 		Interval:                      conf.Get().Settings.Probe.Interval,
 		Timeout:                       conf.Get().Settings.Probe.Timeout,
+		History:                       conf.Get().Settings.Probe.History,
 		StatusChangeThresholdSettings: conf.Get().Settings.Probe.StatusChangeThresholdSettings,
 		NotificationStrategySettings:  conf.Get().Settings.Probe.NotificationStrategySettings,
 	}
@@ -41,7 +45,7 @@ func configProbers(probers []probe.Prober) []probe.Prober {
 	return validProbers
 }
 
-func runProbers(probers []probe.Prober, wg *sync.WaitGroup, done chan bool, saveChannel chan probe.Result) {
+func runProbers(probers []probe.Prober, wg *sync.WaitGroup, done chan bool) {
 	// we need to run all probers in equally distributed time, not at the same time.
 	timeGap := global.DefaultProbeInterval / time.Duration(len(probers))
 	// if less than or equal to 60 probers, use 1 second instead
@@ -63,8 +67,19 @@ func runProbers(probers []probe.Prober, wg *sync.WaitGroup, done chan bool, save
 		interval := time.NewTimer(p.Interval())
 		defer interval.Stop()
 		for {
-            res := p.Probe()
-			log.Debugf("%s: %s", p.Kind(), res.DebugJSON())
+			if probe.GetControl(p.Name()).Paused {
+				log.Debugf("%s / %s - The probe is paused, skip...", p.Kind(), p.Name())
+			} else {
+				res := p.Probe()
+				log.Debugf("%s: %s", p.Kind(), res.DebugJSON())
+				// send the probe result to all of the channels of the prober
+				channel.SendResult(p, res)
+				// append the probe execution into the time-series store
+				if err := store.Default().Append(res); err != nil {
+					log.Errorf("%s / %s - Cannot append the result into the store: %v", p.Kind(), p.Name(), err)
+				}
+			}
+			health.Beat(p.Name())
 			select {
 			case <-done:
 				log.Infof("%s / %s - Received the done signal, exiting...", p.Kind(), p.Name())
@@ -76,12 +91,21 @@ func runProbers(probers []probe.Prober, wg *sync.WaitGroup, done chan bool, save
 		}
 	}
 
+	expected := 0
+	for _, p := range probers {
+		if p.Result().Status != probe.StatusBad {
+			expected++
+		}
+	}
+	health.SetExpected(expected)
+
 	for i := 0; i < len(probers); i++ {
 		p := probers[i]
 		if p.Result().Status == probe.StatusBad {
 			continue
 		}
 		log.Infof("Ready to monitor(%s): %s - %s", p.Kind(), p.Result().Name, p.Result().Endpoint)
+		health.Schedule(p.Kind(), p.Name(), p.Interval(), p.Timeout(), time.Duration(i)*timeGap)
 		go probeFn(p, i)
 	}
 }
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/megaease/easeprobe/channel"
+	"github.com/megaease/easeprobe/conf"
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/megaease/easeprobe/store"
+)
+
+// slaNotifiers return the notifiers of the SLA report channels, empty channels means all of the channels
+func slaNotifiers(channels []string) []notify.Notify {
+	all := channel.GetAllChannels()
+	if len(channels) == 0 {
+		for name := range all {
+			channels = append(channels, name)
+		}
+	}
+	notifies := []notify.Notify{}
+	sent := map[notify.Notify]bool{}
+	for _, name := range channels {
+		c, ok := all[name]
+		if !ok {
+			log.Warnf("SLA Report - the channel [%s] is not found", name)
+			continue
+		}
+		for _, n := range c.Notifiers {
+			if !sent[n] {
+				sent[n] = true
+				notifies = append(notifies, n)
+			}
+		}
+	}
+	return notifies
+}
+
+// writeSLA writes the SLA report into the HTML, Markdown and CSV files of the directory
+func writeSLA(dir string, probers []probe.Prober, now time.Time) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Errorf("SLA Report - cannot create the directory [%s]: %v", dir, err)
+		return
+	}
+	prefix := "sla-" + now.Format("20060102-150405")
+	for ext, f := range map[string]report.Format{".html": report.HTML, ".md": report.Markdown, ".csv": report.CSV} {
+		file := filepath.Join(dir, prefix+ext)
+		if err := os.WriteFile(file, []byte(report.FormatFuncs[f].StatFn(probers)), 0644); err != nil {
+			log.Errorf("SLA Report - cannot write the file [%s]: %v", file, err)
+			continue
+		}
+		log.Infof("SLA Report - successfully wrote the file [%s]", file)
+	}
+}
+
+// sendSLA delivers the SLA report through the notifiers and writes it into the directory
+func sendSLA(s conf.SLAReport, probers []probe.Prober, now time.Time) {
+	for _, n := range slaNotifiers(s.Channels) {
+		if st, ok := n.(notify.Stat); ok {
+			st.NotifyStat(probers)
+		}
+	}
+	if s.Dir != "" {
+		writeSLA(s.Dir, probers, now)
+	}
+}
+
+// scheduleSLA sends the SLA report of the probers on the schedule, in the time zone of the settings
+func scheduleSLA(probers []probe.Prober, done chan bool) {
+	s := conf.Get().Settings.SLAReport
+	if !s.Enabled() {
+		log.Info("SLA Report is not scheduled")
+		return
+	}
+	if err := s.Check(); err != nil {
+		log.Errorf("SLA Report is disabled: %v", err)
+		return
+	}
+	for {
+		now := time.Now().In(global.GetTimeLocation())
+		next := s.Next(now)
+		log.Infof("SLA Report - the next %s report will be sent at %s", s.Schedule, next.Format(time.RFC3339))
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-done:
+			timer.Stop()
+			log.Info("Received the exit signal, SLA Report process exiting...")
+			return
+		case t := <-timer.C:
+			sendSLA(s, probers, t.In(global.GetTimeLocation()))
+		}
+	}
+}
+
+// dataFile return the writeable data file of the settings, the default data file is used if it is empty
+func dataFile(s conf.SLAReport) string {
+	file := strings.TrimSpace(s.DataFile)
+	if file == "-" {
+		return file
+	}
+	if file == "" {
+		file = global.DefaultDataFile
+	}
+	return global.MakeDirectory(file)
+}
+
+// loadData restores the probe results from the data file, and removes the oldest backups,
+// it must be called before the probers are configured, so that the probers could find their results
+func loadData(file string, backups int) {
+	if file == "-" {
+		log.Info("The data file is disabled, the probe results would not be saved")
+		return
+	}
+	if err := probe.LoadDataFromFile(file); err != nil {
+		if os.IsNotExist(err) {
+			log.Infof("The data file [%s] is not found, starting with the empty data", file)
+		} else {
+			log.Warnf("Cannot load the data file [%s]: %v", file, err)
+		}
+	} else {
+		log.Infof("Successfully loaded the data file [%s]", file)
+	}
+	probe.CleanDataFile(file, backups)
+}
+
+// openStore opens the time-series store of the probe executions, and sets it as the default store,
+// the store is disabled if it cannot be opened
+func openStore(s store.Settings) {
+	if !s.Enabled() {
+		log.Info("The time-series store is disabled, the probe executions would not be stored")
+		return
+	}
+	st, err := store.Open(s)
+	if err != nil {
+		log.Errorf("Cannot open the time-series store [%s], it's disabled: %v", s.Dir, err)
+		return
+	}
+	log.Infof("Successfully opened the time-series store [%s], retention: %s, raw: %s, resolution: %s",
+		st.Dir, st.Retention, st.Raw, st.Resolution)
+	store.SetDefault(st)
+}
+
+// closeStore closes the default time-series store
+func closeStore() {
+	if err := store.Default().Close(); err != nil {
+		log.Errorf("Cannot close the time-series store: %v", err)
+	}
+	store.SetDefault(nil)
+}
+
+// saveData saves the probe results into the data file periodically,
+// the results are saved again when the done signal is received
+func saveData(file string, probers []probe.Prober, done chan bool, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	save := func() {
+		// the whole results with the history are copied for saving, they are not sent with every probe result
+		for _, p := range probers {
+			r := probe.Snapshot(p)
+			probe.SetResultData(r.Name, &r)
+		}
+		if err := probe.SaveDataToFile(file); err != nil {
+			log.Errorf("Cannot save the data file [%s]: %v", file, err)
+			return
+		}
+		log.Debugf("Successfully saved the data file [%s]", file)
+	}
+
+	// the data file has been moved to the backup by the loading, save it at once
+	save()
+	ticker := time.NewTicker(global.DefaultDataSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			// the probers have been stopped, the latest results are saved
+			save()
+			log.Info("Received the exit signal, Data Saving process exiting...")
+			return
+		case <-ticker.C:
+			save()
+		}
+	}
+}
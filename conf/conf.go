@@ -14,12 +14,16 @@ import (
 	"time"
 
 	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/maintenance"
 	"github.com/megaease/easeprobe/metric"
+	"github.com/megaease/easeprobe/notify"
 	"github.com/megaease/easeprobe/probe"
 	"github.com/megaease/easeprobe/probe/client"
 	"github.com/megaease/easeprobe/probe/http"
 	"github.com/megaease/easeprobe/probe/tcp"
 	"github.com/megaease/easeprobe/probe/tls"
+	"github.com/megaease/easeprobe/statuspage"
+	"github.com/megaease/easeprobe/store"
 	"github.com/prometheus/client_golang/prometheus"
 
 	log "github.com/sirupsen/logrus"
@@ -67,42 +71,97 @@ func (s *Schedule) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return global.EnumUnmarshalYaml(unmarshal, stringToSchedule, s, None, "Schedule")
 }
 
+// String convert the Schedule to string
+func (s Schedule) String() string {
+	if val, ok := scheduleToString[s]; ok {
+		return val
+	}
+	return scheduleToString[None]
+}
+
+// MarshalJSON marshal the schedule to json
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	return global.EnumMarshalJSON(scheduleToString, s, "Schedule")
+}
+
+// UnmarshalJSON unmarshal the schedule from json
+func (s *Schedule) UnmarshalJSON(b []byte) error {
+	return global.EnumUnmarshalJSON(b, stringToSchedule, s, None, "Schedule")
+}
+
 // Probe is the settings of prober
 type Probe struct {
 	Interval                             time.Duration `yaml:"interval" json:"interval,omitempty" jsonschema:"type=string,format=duration,title=Probe Interval,description=the interval of probe,default=1m"`
 	Timeout                              time.Duration `yaml:"timeout"  json:"timeout,omitempty"  jsonschema:"type=string,format=duration,title=Probe Timeout,description=the timeout of probe,default=30s"`
+	History                              int           `yaml:"history"  json:"history,omitempty"  jsonschema:"title=Probe History,description=the number of the probe results kept in the history,default=1440"`
 	global.StatusChangeThresholdSettings `yaml:",inline" json:",inline"`
 	global.NotificationStrategySettings  `yaml:"alert" json:"alert" jsonschema:"title=Alert,description=the alert settings"`
 }
 
+// Notify is the settings of notification
+type Notify struct {
+	Retry   global.Retry  `yaml:"retry"   json:"retry,omitempty"   jsonschema:"title=Retry,description=the retry settings of the notification"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout,omitempty" jsonschema:"type=string,format=duration,title=Timeout,description=the timeout of the notification,default=30s"`
+	Dry     bool          `yaml:"dry"     json:"dry,omitempty"     jsonschema:"title=Dry Notification,description=render the notifications into the log instead of sending them,default=false"`
+	Digest  global.Digest `yaml:"digest"  json:"digest,omitempty"  jsonschema:"title=Alert Digest,description=group the notifications of a channel which are fired in the same window into one message"`
+}
+
 // HTTPServer is the settings of http server
 type HTTPServer struct {
 	IP              string        `yaml:"ip"      json:"ip"                jsonschema:"title=Web Server IP,description=the local ip address of the http server need to listen on,example=0.0.0.0"`
 	Port            string        `yaml:"port"    json:"port"              jsonschema:"type=integer,title=Web Server Port,description=port of the http server,default=8181"`
 	AutoRefreshTime time.Duration `yaml:"refresh" json:"refresh,omitempty" jsonschema:"type=string,title=Auto Refresh Time,description=auto refresh time of the http server,example=5s"`
 	AccessLog       Log           `yaml:"log"     json:"log,omitempty"     jsonschema:"title=Access Log,description=access log of the http server"`
+	TLS             global.TLS    `yaml:"tls"     json:"tls,omitempty"     jsonschema:"title=TLS,description=the cert/key of the HTTPS server, and the CA to verify the client certificates"`
+	Auth            HTTPAuth      `yaml:"auth"    json:"auth,omitempty"    jsonschema:"title=Authentication,description=the authentication of the http server"`
+	Badge           Badge         `yaml:"badge"   json:"badge,omitempty"   jsonschema:"title=Badge,description=the label text and the colour thresholds of the SVG badges"`
+}
+
+// Badge is the settings of the SVG badges of the probes, the zero value means the default
+type Badge struct {
+	StatusLabel string        `yaml:"status_label,omitempty" json:"status_label,omitempty" jsonschema:"title=Status Label,description=the label of the status badge,default=status"`
+	SLALabel    string        `yaml:"sla_label,omitempty"    json:"sla_label,omitempty"    jsonschema:"title=SLA Label,description=the label of the SLA badge,default=sla"`
+	RTTLabel    string        `yaml:"rtt_label,omitempty"    json:"rtt_label,omitempty"    jsonschema:"title=RTT Label,description=the label of the round trip time badge,default=rtt"`
+	SLAGood     float64       `yaml:"sla_good,omitempty"     json:"sla_good,omitempty"     jsonschema:"title=SLA Good,description=the SLA percentage greater than or equal to it is green,default=99.9"`
+	SLAWarn     float64       `yaml:"sla_warn,omitempty"     json:"sla_warn,omitempty"     jsonschema:"title=SLA Warning,description=the SLA percentage greater than or equal to it is yellow\\, otherwise red,default=99"`
+	RTTGood     time.Duration `yaml:"rtt_good,omitempty"     json:"rtt_good,omitempty"     jsonschema:"type=string,format=duration,title=RTT Good,description=the round trip time less than or equal to it is green,default=500ms"`
+	RTTWarn     time.Duration `yaml:"rtt_warn,omitempty"     json:"rtt_warn,omitempty"     jsonschema:"type=string,format=duration,title=RTT Warning,description=the round trip time less than or equal to it is yellow\\, otherwise red,default=2s"`
+}
+
+// HTTPAuth is the authentication settings of the http server, it's disabled if neither username nor token is set
+type HTTPAuth struct {
+	Username string   `yaml:"username,omitempty" json:"username,omitempty" jsonschema:"title=Username,description=the username of the basic authentication"`
+	Password string   `yaml:"password,omitempty" json:"password,omitempty" jsonschema:"title=Password,description=the password of the basic authentication"`
+	Token    string   `yaml:"token,omitempty"    json:"token,omitempty"    jsonschema:"title=Bearer Token,description=the bearer token of the authentication"`
+	Open     []string `yaml:"open,omitempty"     json:"open,omitempty"     jsonschema:"title=Open Paths,description=the paths which don't need the authentication (the control and maintenance APIs always need it),example=[\"/metrics\"]"`
 }
 
 // Settings is the EaseProbe configuration
 type Settings struct {
-	Name       string     `yaml:"name"       json:"name,omitempty"       jsonschema:"title=EaseProbe Name,description=The name of the EaseProbe instance,default=EaseProbe"`
-	IconURL    string     `yaml:"icon"       json:"icon,omitempty"       jsonschema:"title=Icon URL,description=The URL of the icon of the EaseProbe instance"`
-	PIDFile    string     `yaml:"pid"        json:"pid,omitempty"        jsonschema:"title=PID File,description=The PID file of the EaseProbe instance ('' or '-' means no PID file)"`
-	Log        Log        `yaml:"log"        json:"log,omitempty"        jsonschema:"title=EaseProbe Log,description=The log settings of the EaseProbe instance"`
-	TimeFormat string     `yaml:"timeformat" json:"timeformat,omitempty" jsonschema:"title=Time Format,description=The time format of the EaseProbe instance,default=2006-01-02 15:04:05Z07:00"`
-	TimeZone   string     `yaml:"timezone"   json:"timezone,omitempty"   jsonschema:"title=Time Zone,description=The time zone of the EaseProbe instance,example=Asia/Shanghai,example=Europe/Berlin,default=UTC"`
-	Probe      Probe      `yaml:"probe"      json:"probe,omitempty"      jsonschema:"title=Probe Settings,description=The global probe settings of the EaseProbe instance"`
-	HTTPServer HTTPServer `yaml:"http"       json:"http,omitempty"       jsonschema:"title=HTTP Server Settings,description=The HTTP server settings of the EaseProbe instance"`
+	Name       string         `yaml:"name"       json:"name,omitempty"       jsonschema:"title=EaseProbe Name,description=The name of the EaseProbe instance,default=EaseProbe"`
+	IconURL    string         `yaml:"icon"       json:"icon,omitempty"       jsonschema:"title=Icon URL,description=The URL of the icon of the EaseProbe instance"`
+	PIDFile    string         `yaml:"pid"        json:"pid,omitempty"        jsonschema:"title=PID File,description=The PID file of the EaseProbe instance ('' or '-' means no PID file)"`
+	Log        Log            `yaml:"log"        json:"log,omitempty"        jsonschema:"title=EaseProbe Log,description=The log settings of the EaseProbe instance"`
+	TimeFormat string         `yaml:"timeformat" json:"timeformat,omitempty" jsonschema:"title=Time Format,description=The time format of the EaseProbe instance,default=2006-01-02 15:04:05Z07:00"`
+	TimeZone   string         `yaml:"timezone"   json:"timezone,omitempty"   jsonschema:"title=Time Zone,description=The time zone of the EaseProbe instance,example=Asia/Shanghai,example=Europe/Berlin,default=UTC"`
+	Probe      Probe          `yaml:"probe"      json:"probe,omitempty"      jsonschema:"title=Probe Settings,description=The global probe settings of the EaseProbe instance"`
+	Notify     Notify         `yaml:"notify"     json:"notify,omitempty"     jsonschema:"title=Notification Settings,description=The global notification settings of the EaseProbe instance"`
+	HTTPServer HTTPServer     `yaml:"http"       json:"http,omitempty"       jsonschema:"title=HTTP Server Settings,description=The HTTP server settings of the EaseProbe instance"`
+	SLAReport  SLAReport      `yaml:"sla"        json:"sla,omitempty"        jsonschema:"title=SLA Report,description=The scheduled SLA report of all of the probes"`
+	Store      store.Settings `yaml:"store"      json:"store,omitempty"      jsonschema:"title=Time-Series Store,description=The embedded store of every probe execution"`
 }
 
 // Conf is Probe configuration
 type Conf struct {
-	Version  string          `yaml:"version"  json:"version,omitempty"  jsonschema:"title=Version,description=Version of the EaseProbe configuration"`
-	HTTP     []http.HTTP     `yaml:"http"     json:"http,omitempty"     jsonschema:"title=HTTP Probe,description=HTTP Probe Configuration"`
-	TCP      []tcp.TCP       `yaml:"tcp"      json:"tcp,omitempty"      jsonschema:"title=TCP Probe,description=TCP Probe Configuration"`
-	Client   []client.Client `yaml:"client"   json:"client,omitempty"   jsonschema:"title=Native Client Probe,description=Native Client Probe Configuration"`
-	TLS      []tls.TLS       `yaml:"tls"      json:"tls,omitempty"      jsonschema:"title=TLS Probe,description=TLS Probe Configuration"`
-	Settings Settings        `yaml:"settings" json:"settings,omitempty" jsonschema:"title=Global Settings,description=EaseProbe Global configuration"`
+	Version     string               `yaml:"version"     json:"version,omitempty"     jsonschema:"title=Version,description=Version of the EaseProbe configuration"`
+	HTTP        []http.HTTP          `yaml:"http"        json:"http,omitempty"        jsonschema:"title=HTTP Probe,description=HTTP Probe Configuration"`
+	TCP         []tcp.TCP            `yaml:"tcp"         json:"tcp,omitempty"         jsonschema:"title=TCP Probe,description=TCP Probe Configuration"`
+	Client      []client.Client      `yaml:"client"      json:"client,omitempty"      jsonschema:"title=Native Client Probe,description=Native Client Probe Configuration"`
+	TLS         []tls.TLS            `yaml:"tls"         json:"tls,omitempty"         jsonschema:"title=TLS Probe,description=TLS Probe Configuration"`
+	Notify      notify.Config        `yaml:"notify"      json:"notify,omitempty"      jsonschema:"title=Notification,description=Notification Configuration"`
+	Maintenance []maintenance.Window `yaml:"maintenance" json:"maintenance,omitempty" jsonschema:"title=Maintenance Windows,description=the maintenance windows which suppress the alerts of the probes"`
+	StatusPage  statuspage.Page      `yaml:"status"      json:"status,omitempty"      jsonschema:"title=Status Page,description=the public status page which groups the probes into services"`
+	Settings    Settings             `yaml:"settings"    json:"settings,omitempty"    jsonschema:"title=Global Settings,description=EaseProbe Global configuration"`
 }
 
 // Check if string is a url
@@ -242,11 +301,25 @@ func New(conf *string) (*Conf, error) {
 				Interval: global.DefaultProbeInterval,
 				Timeout:  global.DefaultTimeOut,
 			},
+			Notify: Notify{
+				Retry: global.Retry{
+					Times:    global.DefaultRetryTimes,
+					Interval: global.DefaultRetryInterval,
+				},
+				Timeout: global.DefaultTimeOut,
+			},
 			HTTPServer: HTTPServer{
 				IP:        global.DefaultHTTPServerIP,
 				Port:      global.DefaultHTTPServerPort,
 				AccessLog: NewLog(),
 			},
+			SLAReport: SLAReport{
+				DataFile: filepath.Join(global.GetWorkDir(), global.DefaultDataFile),
+				Backups:  global.DefaultMaxBackups,
+			},
+			Store: store.Settings{
+				Dir: filepath.Join(global.GetWorkDir(), global.DefaultStoreDir),
+			},
 		},
 	}
 	y, err := getYamlFile(*conf)
@@ -340,3 +413,37 @@ func allProbersHelper(i interface{}) []probe.Prober {
 
 	return probers
 }
+
+// isNotify checks whether a interface is a notify type
+func isNotify(t reflect.Type) bool {
+	modelType := reflect.TypeOf((*notify.Notify)(nil)).Elem()
+	return t.Implements(modelType)
+}
+
+// AllNotifiers return all notifiers
+func (conf *Conf) AllNotifiers() []notify.Notify {
+	var notifies []notify.Notify
+
+	log.Debugf("--------- Process the notification settings ---------")
+	t := reflect.TypeOf(conf.Notify)
+	v := reflect.ValueOf(conf.Notify)
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() != reflect.Slice {
+			continue
+		}
+
+		vField := v.Field(i)
+		for j := 0; j < vField.Len(); j++ {
+			if !isNotify(vField.Index(j).Addr().Type()) {
+				continue
+			}
+
+			// the notification settings contain the secrets, only the kind and the name are logged
+			n := vField.Index(j).Addr().Interface().(notify.Notify)
+			log.Debugf("--> %s / %s", t.Field(i).Name, n.Name())
+			notifies = append(notifies, n)
+		}
+	}
+
+	return notifies
+}
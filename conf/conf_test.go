@@ -9,11 +9,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/megaease/easeprobe/global"
 	"github.com/megaease/easeprobe/monkey"
 	"github.com/megaease/easeprobe/probe/client"
 	clientConf "github.com/megaease/easeprobe/probe/client/conf"
 	httpProbe "github.com/megaease/easeprobe/probe/http"
 	"github.com/megaease/easeprobe/probe/tcp"
+	"github.com/megaease/easeprobe/store"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -216,8 +218,29 @@ settings:
     ip: 127.0.0.1
     port: 8181
     refresh: 5s
+    tls:
+      cert: /etc/easeprobe/server.crt
+      key: /etc/easeprobe/server.key
+    auth:
+      username: admin
+      password: secret
+      token: abc123
+      open: ["/metrics"]
+    badge:
+      status_label: health
+      sla_good: 99.5
+      rtt_warn: 1s
   probe:
     interval: 15s
+  sla:
+    schedule: daily
+    time: "23:59"
+    channels: ["ops"]
+    data: /tmp/easeprobe/data.yaml
+    backups: 3
+  store:
+    dir: /tmp/easeprobe/store
+    raw: 72h
   log:
     level: debug
     size: 1
@@ -230,7 +253,14 @@ func checkSettings(t *testing.T, s Settings) {
 	assert.Equal(t, s.HTTPServer.IP, "127.0.0.1")
 	assert.Equal(t, s.HTTPServer.Port, "8181")
 	assert.Equal(t, s.HTTPServer.AutoRefreshTime, 5*time.Second)
+	assert.Equal(t, s.HTTPServer.TLS.Cert, "/etc/easeprobe/server.crt")
+	assert.Equal(t, s.HTTPServer.TLS.Key, "/etc/easeprobe/server.key")
+	assert.Equal(t, s.HTTPServer.Badge, Badge{StatusLabel: "health", SLAGood: 99.5, RTTWarn: time.Second})
+	assert.Equal(t, s.HTTPServer.Auth, HTTPAuth{Username: "admin", Password: "secret", Token: "abc123", Open: []string{"/metrics"}})
 	assert.Equal(t, s.Probe.Interval, 15*time.Second)
+	assert.Equal(t, s.SLAReport, SLAReport{Schedule: Daily, Time: "23:59", Channels: []string{"ops"},
+		DataFile: "/tmp/easeprobe/data.yaml", Backups: 3})
+	assert.Equal(t, s.Store, store.Settings{Dir: "/tmp/easeprobe/store", Raw: 72 * time.Hour})
 	assert.Equal(t, s.Log.Level, LogLevel(log.DebugLevel))
 	assert.Equal(t, s.Log.MaxSize, 1)
 	assert.Equal(t, s.TimeFormat, "2006-01-02 15:04:05 UTC")
@@ -347,6 +377,12 @@ func TestEmptyProbes(t *testing.T) {
 	assert.Nil(t, err)
 	probers := conf.AllProbers()
 	assert.Equal(t, 0, len(probers))
+	notifiers := conf.AllNotifiers()
+	assert.Equal(t, 0, len(notifiers))
+	assert.Equal(t, global.DefaultRetryTimes, conf.Settings.Notify.Retry.Times)
+	assert.Equal(t, global.DefaultRetryInterval, conf.Settings.Notify.Retry.Interval)
+	assert.Equal(t, global.DefaultTimeOut, conf.Settings.Notify.Timeout)
+	assert.False(t, conf.Settings.Notify.Dry)
 
 	os.RemoveAll(file)
 	os.RemoveAll("data")
@@ -374,3 +410,118 @@ func TestFileConfigModificaiton(t *testing.T) {
 	assert.False(t, IsConfigModified(file, metrics))
 
 }
+
+const confNotify = `
+notify:
+  webhook:
+    - name: incident webhook
+      url: http://localhost:12345/hook
+      headers:
+        X-Token: secret
+      channels:
+        - "telegram#Dev"
+      retry:
+        times: 5
+        interval: 1s
+settings:
+  notify:
+    timeout: 10s
+    dry: true
+    digest:
+      window: 1m
+      channels:
+        - "telegram#Dev"
+    retry:
+      times: 2
+`
+
+func TestNotifyConfig(t *testing.T) {
+	file := "./config.yaml"
+	err := writeConfig(file, confVer+confNotify)
+	assert.Nil(t, err)
+	defer os.RemoveAll(file)
+	defer os.RemoveAll("data")
+
+	conf, err := New(&file)
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Second, conf.Settings.Notify.Timeout)
+	assert.Equal(t, 2, conf.Settings.Notify.Retry.Times)
+	assert.True(t, conf.Settings.Notify.Dry)
+	assert.Equal(t, time.Minute, conf.Settings.Notify.Digest.Window)
+	assert.True(t, conf.Settings.Notify.Digest.Enabled("telegram#Dev"))
+
+	notifiers := conf.AllNotifiers()
+	assert.Equal(t, 1, len(notifiers))
+	assert.Equal(t, "incident webhook", notifiers[0].Name())
+	assert.Equal(t, []string{"telegram#Dev"}, notifiers[0].Channels())
+
+	assert.Equal(t, "http://localhost:12345/hook", conf.Notify.Webhook[0].URL)
+	assert.Equal(t, "secret", conf.Notify.Webhook[0].Headers["X-Token"])
+	assert.Equal(t, 5, conf.Notify.Webhook[0].Retry.Times)
+}
+
+const confMaintenance = `
+maintenance:
+  - name: weekly upgrade
+    weekdays: [sat, sun]
+    time: "22:00-02:00"
+    kinds: [http]
+  - name: db migration
+    start: "2024-05-01 22:00"
+    end: "2024-05-02 02:00"
+    labels:
+      team: db
+    exclude_sla: true
+  - name: nightly backup
+    cron: "0 3 * * *"
+    duration: 30m
+    probes: [mysql]
+`
+
+func TestMaintenanceConfig(t *testing.T) {
+	file := "./config.yaml"
+	err := writeConfig(file, confVer+confMaintenance)
+	assert.Nil(t, err)
+	defer os.RemoveAll(file)
+	defer os.RemoveAll("data")
+
+	conf, err := New(&file)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(conf.Maintenance))
+	assert.Equal(t, []string{"sat", "sun"}, conf.Maintenance[0].Weekdays)
+	assert.Equal(t, "22:00-02:00", conf.Maintenance[0].Time)
+	assert.Equal(t, map[string]string{"team": "db"}, conf.Maintenance[1].Labels)
+	assert.True(t, conf.Maintenance[1].ExcludeSLA)
+	assert.Equal(t, 30*time.Minute, conf.Maintenance[2].Duration)
+	for i := range conf.Maintenance {
+		assert.Nil(t, conf.Maintenance[i].Config())
+	}
+}
+
+const confStatusPage = `
+status:
+  title: Acme Status
+  days: 30
+  services:
+    - name: Website
+      description: The public web site
+      probes: [web, api]
+    - name: Database
+      probes: [mysql]
+`
+
+func TestStatusPageConfig(t *testing.T) {
+	file := "./config.yaml"
+	err := writeConfig(file, confVer+confStatusPage)
+	assert.Nil(t, err)
+	defer os.RemoveAll(file)
+	defer os.RemoveAll("data")
+
+	conf, err := New(&file)
+	assert.Nil(t, err)
+	assert.Equal(t, "Acme Status", conf.StatusPage.Title)
+	assert.Equal(t, 30, conf.StatusPage.Days)
+	assert.Equal(t, 2, len(conf.StatusPage.Services))
+	assert.Equal(t, []string{"web", "api"}, conf.StatusPage.Services[0].Probes)
+	assert.Nil(t, conf.StatusPage.Config())
+}
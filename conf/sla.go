@@ -0,0 +1,86 @@
+package conf
+
+import (
+	"fmt"
+	"time"
+)
+
+// SLAReport is the settings of the scheduled SLA report
+type SLAReport struct {
+	Schedule Schedule `yaml:"schedule"           json:"schedule,omitempty" jsonschema:"type=string,enum=none,enum=minutely,enum=hourly,enum=daily,enum=weekly,enum=monthly,title=Schedule,description=the schedule of the SLA report,default=none"`
+	Time     string   `yaml:"time,omitempty"     json:"time,omitempty"     jsonschema:"format=time,title=Time,description=the time of the day (HH:MM) to send the report (only the minute is used by the hourly report),default=00:00"`
+	Channels []string `yaml:"channels,omitempty" json:"channels,omitempty" jsonschema:"title=Channels,description=the channels which the report is sent to (empty means all of the channels)"`
+	Dir      string   `yaml:"dir,omitempty"      json:"dir,omitempty"      jsonschema:"title=Directory,description=the directory which the HTML/Markdown/CSV report files are written into (empty means no files)"`
+	DataFile string   `yaml:"data,omitempty"     json:"data,omitempty"     jsonschema:"title=Data File,description=the file which the probe results are saved into and restored from after restart ('-' means no data file),default=data/data.yaml"`
+	Backups  int      `yaml:"backups"            json:"backups"            jsonschema:"title=Backups,description=the number of the data file backups which are kept (negative means keeping all of the backups),default=5"`
+}
+
+// Enabled return true if the SLA report is scheduled
+func (s *SLAReport) Enabled() bool {
+	return s.Schedule != None
+}
+
+// clock return the hour and minute of the report time
+func (s *SLAReport) clock() (int, int, error) {
+	if s.Time == "" {
+		return 0, 0, nil
+	}
+	t, err := time.Parse("15:04", s.Time)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid SLA report time [%s], it must be HH:MM", s.Time)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// Check checks the SLA report settings
+func (s *SLAReport) Check() error {
+	if _, ok := scheduleToString[s.Schedule]; !ok {
+		return fmt.Errorf("invalid SLA report schedule [%d]", s.Schedule)
+	}
+	_, _, err := s.clock()
+	return err
+}
+
+// Next return the next report time after `now`, in the time zone of `now`
+// - minutely: the beginning of the next minute
+// - hourly: the minute of the report time in the next hour
+// - daily: the report time of the next day
+// - weekly: the report time of the next Monday
+// - monthly: the report time of the first day of the next month
+// The zero time is returned if the report is not scheduled
+func (s *SLAReport) Next(now time.Time) time.Time {
+	hour, min, err := s.clock()
+	if err != nil {
+		return time.Time{}
+	}
+	y, m, d := now.Date()
+	loc := now.Location()
+
+	var next time.Time
+	switch s.Schedule {
+	case Minutely:
+		next = time.Date(y, m, d, now.Hour(), now.Minute()+1, 0, 0, loc)
+	case Hourly:
+		next = time.Date(y, m, d, now.Hour(), min, 0, 0, loc)
+		if !next.After(now) {
+			next = time.Date(y, m, d, now.Hour()+1, min, 0, 0, loc)
+		}
+	case Daily:
+		next = time.Date(y, m, d, hour, min, 0, 0, loc)
+		if !next.After(now) {
+			next = time.Date(y, m, d+1, hour, min, 0, 0, loc)
+		}
+	case Weekly:
+		days := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+		next = time.Date(y, m, d+days, hour, min, 0, 0, loc)
+		if !next.After(now) {
+			next = time.Date(y, m, d+days+7, hour, min, 0, 0, loc)
+		}
+	case Monthly:
+		next = time.Date(y, m, 1, hour, min, 0, 0, loc)
+		if !next.After(now) {
+			next = time.Date(y, m+1, 1, hour, min, 0, 0, loc)
+		}
+	}
+	return next
+}
@@ -0,0 +1,79 @@
+package conf
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSLAReportNext(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	assert.Nil(t, err)
+	// Wednesday
+	now := time.Date(2022, 6, 15, 10, 30, 20, 0, loc)
+
+	tests := []struct {
+		schedule Schedule
+		time     string
+		next     time.Time
+	}{
+		{Minutely, "", time.Date(2022, 6, 15, 10, 31, 0, 0, loc)},
+		{Hourly, "", time.Date(2022, 6, 15, 11, 0, 0, 0, loc)},
+		{Hourly, "08:45", time.Date(2022, 6, 15, 10, 45, 0, 0, loc)},
+		{Daily, "", time.Date(2022, 6, 16, 0, 0, 0, 0, loc)},
+		{Daily, "23:59", time.Date(2022, 6, 15, 23, 59, 0, 0, loc)},
+		{Weekly, "09:00", time.Date(2022, 6, 20, 9, 0, 0, 0, loc)},
+		{Monthly, "", time.Date(2022, 7, 1, 0, 0, 0, 0, loc)},
+		{None, "", time.Time{}},
+	}
+	for _, tt := range tests {
+		s := SLAReport{Schedule: tt.schedule, Time: tt.time}
+		assert.Nil(t, s.Check())
+		assert.Equal(t, tt.schedule != None, s.Enabled())
+		assert.Equal(t, tt.next, s.Next(now), tt.schedule.String())
+	}
+
+	// Monday before and after the report time
+	monday := time.Date(2022, 6, 13, 8, 0, 0, 0, loc)
+	s := SLAReport{Schedule: Weekly, Time: "09:00"}
+	assert.Equal(t, time.Date(2022, 6, 13, 9, 0, 0, 0, loc), s.Next(monday))
+	assert.Equal(t, time.Date(2022, 6, 20, 9, 0, 0, 0, loc), s.Next(monday.Add(time.Hour)))
+
+	// the end of the year
+	s = SLAReport{Schedule: Monthly, Time: "00:00"}
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, loc), s.Next(time.Date(2022, 12, 1, 0, 0, 0, 0, loc)))
+
+	// bad settings
+	s = SLAReport{Schedule: Daily, Time: "25:00"}
+	assert.NotNil(t, s.Check())
+	assert.True(t, s.Next(now).IsZero())
+	s = SLAReport{Schedule: 100}
+	assert.NotNil(t, s.Check())
+}
+
+func TestSLAReportConfig(t *testing.T) {
+	var s SLAReport
+	yml := `
+schedule: weekly
+time: "09:30"
+channels: ["ops"]
+dir: /tmp/sla
+`
+	assert.Nil(t, yaml.Unmarshal([]byte(yml), &s))
+	assert.Equal(t, SLAReport{Schedule: Weekly, Time: "09:30", Channels: []string{"ops"}, Dir: "/tmp/sla"}, s)
+
+	buf, err := json.Marshal(s)
+	assert.Nil(t, err)
+	var j SLAReport
+	assert.Nil(t, json.Unmarshal(buf, &j))
+	assert.Equal(t, s, j)
+	assert.Contains(t, string(buf), `"schedule":"weekly"`)
+
+	// the data file settings
+	s = SLAReport{}
+	assert.Nil(t, yaml.Unmarshal([]byte("data: \"-\"\nbackups: -1\n"), &s))
+	assert.Equal(t, SLAReport{DataFile: "-", Backups: -1}, s)
+}
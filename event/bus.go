@@ -0,0 +1,150 @@
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	log "github.com/sirupsen/logrus"
+)
+
+const kind = "EventBus"
+
+// subscriptionBufferSize is the number of the events buffered for a slow subscriber
+const subscriptionBufferSize = 64
+
+// Subscription is the subscriber of the event bus
+type Subscription struct {
+	C      <-chan Event // it's closed when the subscription is cancelled or the bus is closed
+	ch     chan Event
+	filter Filter
+}
+
+// Bus is the event bus, it keeps the recent events for the replay
+type Bus struct {
+	mutex  sync.Mutex
+	nextID uint64
+	size   int
+	events []Event
+	subs   map[*Subscription]bool
+	closed bool
+}
+
+// NewBus creates the event bus which keeps `size` recent events
+func NewBus(size int) *Bus {
+	if size <= 0 {
+		size = global.DefaultEventBufferSize
+	}
+	return &Bus{size: size, subs: map[*Subscription]bool{}}
+}
+
+// Publish assigns the ID to the event and sends it to the matched subscribers,
+// the event is dropped for the subscriber whose buffer is full.
+func (b *Bus) Publish(e Event) Event {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nextID++
+	e.ID = b.nextID
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	b.events = append(b.events, e)
+	if len(b.events) > b.size {
+		b.events = b.events[len(b.events)-b.size:]
+	}
+
+	for s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			log.Warnf("[%s] The subscriber is too slow, the event [%d] of [%s] is dropped", kind, e.ID, e.Name)
+		}
+	}
+	return e
+}
+
+// Subscribe subscribes the events which match the filter, and returns the matched events for the replay:
+//   - the events after `lastID` if it's set, e.g. the reconnecting client
+//   - otherwise the last `replay` events
+func (b *Bus) Subscribe(f Filter, replay int, lastID uint64) (*Subscription, []Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	ch := make(chan Event, subscriptionBufferSize)
+	s := &Subscription{C: ch, ch: ch, filter: f}
+	if b.closed {
+		close(ch)
+		return s, []Event{}
+	}
+	b.subs[s] = true
+
+	events := []Event{}
+	for _, e := range b.events {
+		if f.Match(e) && (lastID <= 0 || e.ID > lastID) {
+			events = append(events, e)
+		}
+	}
+	if lastID <= 0 {
+		if replay <= 0 {
+			return s, []Event{}
+		}
+		if len(events) > replay {
+			events = events[len(events)-replay:]
+		}
+	}
+	return s, events
+}
+
+// Unsubscribe cancels the subscription
+func (b *Bus) Unsubscribe(s *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.subs[s] {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
+
+// Subscribers returns the number of the subscribers
+func (b *Bus) Subscribers() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.subs)
+}
+
+// Close cancels all of the subscriptions, no more subscription is accepted
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for s := range b.subs {
+		close(s.ch)
+	}
+	b.subs = map[*Subscription]bool{}
+	b.closed = true
+}
+
+var bus = NewBus(global.DefaultEventBufferSize)
+
+// Publish publishes the event on the default bus
+func Publish(e Event) Event {
+	return bus.Publish(e)
+}
+
+// Subscribe subscribes the events on the default bus
+func Subscribe(f Filter, replay int, lastID uint64) (*Subscription, []Event) {
+	return bus.Subscribe(f, replay, lastID)
+}
+
+// Unsubscribe cancels the subscription on the default bus
+func Unsubscribe(s *Subscription) {
+	bus.Unsubscribe(s)
+}
+
+// Close closes the default bus
+func Close() {
+	bus.Close()
+}
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func ids(events []Event) []uint64 {
+	list := []uint64{}
+	for _, e := range events {
+		list = append(list, e.ID)
+	}
+	return list
+}
+
+func TestBus(t *testing.T) {
+	b := NewBus(3)
+	for i := 0; i < 5; i++ {
+		e := b.Publish(Event{Name: fmt.Sprintf("p%d", i%2), Type: TypeResult})
+		assert.Equal(t, uint64(i+1), e.ID)
+		assert.False(t, e.Time.IsZero())
+	}
+
+	// only the recent events are kept
+	_, events := b.Subscribe(Filter{}, 10, 0)
+	assert.Equal(t, []uint64{3, 4, 5}, ids(events))
+	_, events = b.Subscribe(Filter{}, 2, 0)
+	assert.Equal(t, []uint64{4, 5}, ids(events))
+	_, events = b.Subscribe(Filter{}, 0, 0)
+	assert.Equal(t, []uint64{}, ids(events))
+	_, events = b.Subscribe(Filter{Names: []string{"p0"}}, 10, 0)
+	assert.Equal(t, []uint64{3, 5}, ids(events))
+	// the events after the last event id
+	s, events := b.Subscribe(Filter{}, 0, 3)
+	assert.Equal(t, []uint64{4, 5}, ids(events))
+	assert.Equal(t, 5, b.Subscribers())
+
+	// the matched events are sent to the subscriber
+	f, _ := b.Subscribe(Filter{Transitions: true}, 0, 0)
+	b.Publish(Event{Name: "p0", Type: TypeResult})
+	b.Publish(Event{Name: "p1", Type: TypeTransition})
+	assert.Equal(t, uint64(6), (<-s.C).ID)
+	assert.Equal(t, uint64(7), (<-s.C).ID)
+	assert.Equal(t, uint64(7), (<-f.C).ID)
+
+	b.Unsubscribe(s)
+	b.Unsubscribe(s)
+	_, ok := <-s.C
+	assert.False(t, ok)
+	assert.Equal(t, 5, b.Subscribers())
+
+	// the events are dropped for the slow subscriber
+	for i := 0; i < subscriptionBufferSize+10; i++ {
+		b.Publish(Event{Name: "p1", Type: TypeTransition})
+	}
+	assert.Equal(t, subscriptionBufferSize, len(f.C))
+
+	// all of the subscriptions are closed
+	b.Close()
+	for range f.C {
+	}
+	assert.Equal(t, 0, b.Subscribers())
+	s, events = b.Subscribe(Filter{}, 10, 0)
+	assert.Equal(t, 0, len(events))
+	_, ok = <-s.C
+	assert.False(t, ok)
+}
+
+func TestDefaultBus(t *testing.T) {
+	assert.Equal(t, global.DefaultEventBufferSize, NewBus(0).size)
+
+	s, _ := Subscribe(Filter{Names: []string{"default bus"}}, 0, 0)
+	e := Publish(Event{Name: "default bus"})
+	assert.Equal(t, e, <-s.C)
+	Unsubscribe(s)
+}
@@ -0,0 +1,81 @@
+// Package event is the event bus which publishes the probe results to the subscribers
+package event
+
+import (
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// Type is the type of the event
+type Type string
+
+// The types of the event
+const (
+	TypeResult     Type = "result"     // the probe result without the status change
+	TypeTransition Type = "transition" // the probe result with the status change
+)
+
+// Event is the probe result published on the event bus
+type Event struct {
+	ID        uint64        `json:"id"`
+	Type      Type          `json:"type"`
+	Time      time.Time     `json:"time"`
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	Endpoint  string        `json:"endpoint"`
+	Status    probe.Status  `json:"status"`
+	PreStatus probe.Status  `json:"prestatus"`
+	RTT       time.Duration `json:"rtt"`
+	Message   string        `json:"message"`
+}
+
+// NewEvent creates the event from the probe result
+func NewEvent(kind string, r probe.Result) Event {
+	t := TypeResult
+	if r.PreStatus != r.Status {
+		t = TypeTransition
+	}
+	return Event{
+		Type:      t,
+		Time:      r.StartTime,
+		Kind:      kind,
+		Name:      r.Name,
+		Endpoint:  r.Endpoint,
+		Status:    r.Status,
+		PreStatus: r.PreStatus,
+		RTT:       r.RoundTripTime,
+		Message:   r.Message,
+	}
+}
+
+// Filter is the filter of the subscription, the empty filter matches all of the events
+type Filter struct {
+	Names       []string // the probe names
+	Kinds       []string // the probe kinds (case-insensitive)
+	Transitions bool     // only the status transitions
+}
+
+// Match returns true if the event matches the filter
+func (f Filter) Match(e Event) bool {
+	if f.Transitions && e.Type != TypeTransition {
+		return false
+	}
+	if len(f.Names) > 0 && !contains(f.Names, e.Name, false) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !contains(f.Kinds, e.Kind, true) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, s string, fold bool) bool {
+	for _, v := range list {
+		if v == s || (fold && strings.EqualFold(v, s)) {
+			return true
+		}
+	}
+	return false
+}
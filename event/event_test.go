@@ -0,0 +1,56 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult(name string, pre, status probe.Status) probe.Result {
+	r := probe.NewResult()
+	r.Name = name
+	r.Endpoint = "http://" + name
+	r.PreStatus = pre
+	r.Status = status
+	r.StartTime = time.Now().UTC()
+	return *r
+}
+
+func TestNewEvent(t *testing.T) {
+	e := NewEvent("http", newResult("web", probe.StatusUp, probe.StatusUp))
+	assert.Equal(t, TypeResult, e.Type)
+	assert.Equal(t, "http", e.Kind)
+	assert.Equal(t, "web", e.Name)
+	assert.Equal(t, "http://web", e.Endpoint)
+
+	e = NewEvent("http", newResult("web", probe.StatusUp, probe.StatusDown))
+	assert.Equal(t, TypeTransition, e.Type)
+	assert.Equal(t, probe.StatusUp, e.PreStatus)
+	assert.Equal(t, probe.StatusDown, e.Status)
+}
+
+func TestFilter(t *testing.T) {
+	result := Event{Type: TypeResult, Kind: "http", Name: "web"}
+	transition := Event{Type: TypeTransition, Kind: "tcp", Name: "db"}
+
+	f := Filter{}
+	assert.True(t, f.Match(result))
+	assert.True(t, f.Match(transition))
+
+	f = Filter{Transitions: true}
+	assert.False(t, f.Match(result))
+	assert.True(t, f.Match(transition))
+
+	f = Filter{Names: []string{"web", "cache"}}
+	assert.True(t, f.Match(result))
+	assert.False(t, f.Match(transition))
+
+	f = Filter{Kinds: []string{"TCP"}}
+	assert.False(t, f.Match(result))
+	assert.True(t, f.Match(transition))
+
+	f = Filter{Names: []string{"Web"}}
+	assert.False(t, f.Match(result))
+}
@@ -59,8 +59,22 @@ const (
 	DefaultMaxNotificationTimes = 1
 	// DefaultNotificationFactor is the default notification factor
 	DefaultNotificationFactor = 1
+	// DefaultUptimeDays is the number of the recent days whose uptime and incidents are kept
+	DefaultUptimeDays = 90
+	// DefaultHistorySize is the default number of the probe results in the history, it's one day for the default interval
+	DefaultHistorySize = 1440
+	// DefaultStallFactor is the multiple of the probe interval after which the probe loop is considered stalled
+	DefaultStallFactor = 3
 	// DefaultConfigFileCheckInterval is the default config file checking interval
 	DefaultConfigFileCheckInterval = time.Second * 5
+	// DefaultDataSaveInterval is the interval of saving the probe results into the data file
+	DefaultDataSaveInterval = time.Second * 60
+	// DefaultStoreRetention is the retention of the probe executions in the time-series store
+	DefaultStoreRetention = DefaultUptimeDays * 24 * time.Hour
+	// DefaultStoreRawRetention is the retention of the raw probe executions, the older ones are downsampled
+	DefaultStoreRawRetention = 7 * 24 * time.Hour
+	// DefaultStoreResolution is the resolution of the downsampled probe executions
+	DefaultStoreResolution = time.Hour
 )
 
 const (
@@ -70,10 +84,14 @@ const (
 	DefaultHTTPServerPort = "8181"
 	// DefaultPageSize is the default page size
 	DefaultPageSize = 100
+	// DefaultEventBufferSize is the default number of the recent events kept for the replay
+	DefaultEventBufferSize = 100
 	// DefaultAccessLogFile is the default access log file name
 	DefaultAccessLogFile = "access.log"
 	// DefaultDataFile is the default data file name
 	DefaultDataFile = "data/data.yaml"
+	// DefaultStoreDir is the default directory of the time-series store
+	DefaultStoreDir = "data/store"
 	// DefaultPIDFile is the default pid file name
 	DefaultPIDFile = "easeprobe.pid"
 )
@@ -213,6 +231,46 @@ func (t *TLS) Config() (*tls.Config, error) {
 	}, nil
 }
 
+// ServerConfig returns the TLS configuration of the server side, nil is returned if the cert/key are not set.
+// If the CA is set, the client certificates are verified by the CA (mTLS),
+// and the client certificate is optional if the insecure is true.
+func (t *TLS) ServerConfig() (*tls.Config, error) {
+	if len(t.Cert) <= 0 || len(t.Key) <= 0 {
+		if len(t.CA) > 0 {
+			return nil, fmt.Errorf("the cert and key are required to verify the client certificates")
+		}
+		return nil, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+	if err != nil {
+		return nil, err
+	}
+	conf := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(t.CA) <= 0 {
+		log.Debug("[TLS] No CA file, the client certificates are not verified")
+		return conf, nil
+	}
+
+	cert, err := os.ReadFile(t.CA)
+	if err != nil {
+		return nil, err
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(cert) {
+		return nil, fmt.Errorf("no certificate found in the CA file [%s]", t.CA)
+	}
+	conf.ClientCAs = caCertPool
+	conf.ClientAuth = tls.RequireAndVerifyClientCert
+	if t.Insecure {
+		conf.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return conf, nil
+}
+
 // ErrNoRetry is the error need not retry
 type ErrNoRetry struct {
 	Message string
@@ -431,3 +431,69 @@ func TestEscape(t *testing.T) {
 	assert.Equal(t, `\\test\\`, EscapeQuote(`\test\`))
 
 }
+
+func TestServerTLS(t *testing.T) {
+	// no TLS
+	_tls := TLS{}
+	conf, e := _tls.ServerConfig()
+	assert.Nil(t, conf)
+	assert.Nil(t, e)
+
+	// CA without cert/key
+	_tls = TLS{CA: "ca.crt"}
+	conf, e = _tls.ServerConfig()
+	assert.Nil(t, conf)
+	assert.NotNil(t, e)
+
+	path := GetWorkDir() + "/server-certs/"
+	os.MkdirAll(path, 0755)
+	defer os.RemoveAll(path)
+
+	// the cert/key files don't exist
+	_tls = TLS{
+		Cert: filepath.Join(path, "./server.crt"),
+		Key:  filepath.Join(path, "./server.key"),
+	}
+	conf, e = _tls.ServerConfig()
+	assert.Nil(t, conf)
+	assert.NotNil(t, e)
+
+	subject := pkix.Name{Organization: []string{"MegaEase"}, CommonName: "CA"}
+	caCert, caKey, err := makeCA(path, &subject)
+	if err != nil {
+		t.Fatalf("make CA Certificate error! - %v", err)
+	}
+	subject.CommonName = "Server"
+	if err := makeCert(path, caCert, caKey, &subject, "server"); err != nil {
+		t.Fatal("make Server Certificate error!")
+	}
+
+	// TLS
+	conf, e = _tls.ServerConfig()
+	assert.Nil(t, e)
+	assert.Equal(t, 1, len(conf.Certificates))
+	assert.Equal(t, tls.NoClientCert, conf.ClientAuth)
+
+	// mTLS
+	_tls.CA = filepath.Join(path, "./ca.crt")
+	conf, e = _tls.ServerConfig()
+	assert.Nil(t, e)
+	assert.NotNil(t, conf.ClientCAs)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, conf.ClientAuth)
+
+	_tls.Insecure = true
+	conf, e = _tls.ServerConfig()
+	assert.Nil(t, e)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, conf.ClientAuth)
+
+	// invalid CA file
+	_tls.CA = filepath.Join(path, "./server.key")
+	conf, e = _tls.ServerConfig()
+	assert.Nil(t, conf)
+	assert.NotNil(t, e)
+
+	_tls.CA = filepath.Join(path, "./none.crt")
+	conf, e = _tls.ServerConfig()
+	assert.Nil(t, conf)
+	assert.NotNil(t, e)
+}
@@ -1,5 +1,5 @@
 
-package conf
+package global
 
 import (
 	"fmt"
@@ -1,4 +1,3 @@
-
 package global
 
 import "time"
@@ -8,6 +7,30 @@ type NotifySettings struct {
 	TimeFormat string
 	Timeout    time.Duration
 	Retry      Retry
+	Dry        bool
+}
+
+// Digest is the settings of the alert digest, the notifications of a channel
+// which are fired in the same window are grouped into one message
+type Digest struct {
+	Window   time.Duration `yaml:"window" json:"window,omitempty" jsonschema:"type=string,format=duration,title=Digest Window,description=the window to group the notifications of a channel (0 means no digest),example=1m"`
+	Channels []string      `yaml:"channels,omitempty" json:"channels,omitempty" jsonschema:"title=Digest Channels,description=the channels which use the digest (empty means all channels)"`
+}
+
+// Enabled return true if the digest is enabled for the channel
+func (d *Digest) Enabled(channel string) bool {
+	if d.Window <= 0 {
+		return false
+	}
+	if len(d.Channels) == 0 {
+		return true
+	}
+	for _, c := range d.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
 }
 
 // NormalizeTimeOut return a normalized timeout value
@@ -44,3 +44,16 @@ func TestNotify(t *testing.T) {
 	retry = n.NormalizeRetry(Retry{Times: 0, Interval: 0})
 	assert.Equal(t, Retry{Times: 20, Interval: 20}, retry)
 }
+
+func TestDigest(t *testing.T) {
+	d := Digest{}
+	assert.False(t, d.Enabled("dev"))
+
+	d.Window = time.Minute
+	assert.True(t, d.Enabled("dev"))
+	assert.True(t, d.Enabled("ops"))
+
+	d.Channels = []string{"dev"}
+	assert.True(t, d.Enabled("dev"))
+	assert.False(t, d.Enabled("ops"))
+}
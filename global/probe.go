@@ -18,6 +18,7 @@ type StatusChangeThresholdSettings struct {
 type ProbeSettings struct {
 	Interval time.Duration
 	Timeout  time.Duration
+	History  int
 	StatusChangeThresholdSettings
 	NotificationStrategySettings
 }
@@ -32,6 +33,11 @@ func (p *ProbeSettings) NormalizeInterval(t time.Duration) time.Duration {
 	return normalize(p.Interval, t, 0, DefaultProbeInterval)
 }
 
+// NormalizeHistory return a normalized history size
+func (p *ProbeSettings) NormalizeHistory(n int) int {
+	return normalize(p.History, n, 0, DefaultHistorySize)
+}
+
 // NormalizeThreshold return a normalized threshold value
 func (p *ProbeSettings) NormalizeThreshold(t StatusChangeThresholdSettings) StatusChangeThresholdSettings {
 	return StatusChangeThresholdSettings{
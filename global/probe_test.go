@@ -201,3 +201,12 @@ func TestNotificationIntervalStrategy(t *testing.T) {
 	testNotifyYamlJSON(t, "increment", IncrementStrategy, true)
 	testNotifyYamlJSON(t, "exponent", ExponentialStrategy, true)
 }
+
+func TestNormalizeHistory(t *testing.T) {
+	p := ProbeSettings{}
+	assert.Equal(t, DefaultHistorySize, p.NormalizeHistory(0))
+	assert.Equal(t, 10, p.NormalizeHistory(10))
+	p.History = 20
+	assert.Equal(t, 20, p.NormalizeHistory(0))
+	assert.Equal(t, 10, p.NormalizeHistory(10))
+}
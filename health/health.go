@@ -0,0 +1,145 @@
+// Package health is the liveness and readiness of EaseProbe itself
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+)
+
+// loop is the state of the probe loop
+type loop struct {
+	kind     string
+	interval time.Duration
+	timeout  time.Duration
+	start    time.Time // the time of the first probe, the loop is delayed to start
+	last     time.Time // the time of the latest loop iteration
+}
+
+var (
+	mutex     sync.RWMutex
+	startTime = time.Now()
+	config    string
+	expected  int
+	loops     = map[string]*loop{}
+)
+
+// SetConfig marks the configuration file is loaded
+func SetConfig(file string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	config = file
+}
+
+// SetExpected sets the number of the probers which need to be scheduled
+func SetExpected(n int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected = n
+}
+
+// Schedule registers the probe loop which starts after the delay
+func Schedule(kind, name string, interval, timeout, delay time.Duration) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	loops[name] = &loop{
+		kind:     kind,
+		interval: interval,
+		timeout:  timeout,
+		start:    time.Now().Add(delay),
+	}
+}
+
+// Beat records the iteration of the probe loop
+func Beat(name string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if l, ok := loops[name]; ok {
+		l.last = time.Now()
+	}
+}
+
+// Reset clears all of the states
+// Note: it is only used by the test
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	config = ""
+	expected = 0
+	loops = map[string]*loop{}
+}
+
+// Liveness is the liveness of EaseProbe
+type Liveness struct {
+	Status  string        `json:"status"`
+	Version string        `json:"version"`
+	Start   time.Time     `json:"start"`
+	Uptime  time.Duration `json:"uptime"`
+}
+
+// Live returns the liveness of EaseProbe, it's always alive if the web server could respond
+func Live(now time.Time) Liveness {
+	return Liveness{
+		Status:  "ok",
+		Version: global.Ver,
+		Start:   startTime,
+		Uptime:  now.Sub(startTime).Round(time.Second),
+	}
+}
+
+// StalledLoop is the probe loop which has no iteration for a long time
+type StalledLoop struct {
+	Name     string        `json:"name"`
+	Kind     string        `json:"kind"`
+	Interval time.Duration `json:"interval"`
+	Last     time.Time     `json:"last,omitzero"`
+	Stalled  time.Duration `json:"stalled"` // how long the loop has no iteration
+}
+
+// Readiness is the readiness of EaseProbe
+type Readiness struct {
+	Ready     bool          `json:"ready"`
+	Config    string        `json:"config"`
+	Loaded    bool          `json:"config_loaded"`
+	Expected  int           `json:"probers_expected"`
+	Scheduled int           `json:"probers_scheduled"`
+	Stalled   []StalledLoop `json:"stalled"`
+}
+
+// Ready returns the readiness of EaseProbe, it's ready if
+//   - the configuration is loaded
+//   - all of the probers are scheduled
+//   - none of the probe loops has stalled past a multiple of its interval
+func Ready(now time.Time) Readiness {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	r := Readiness{
+		Config:    config,
+		Loaded:    config != "",
+		Expected:  expected,
+		Scheduled: len(loops),
+		Stalled:   []StalledLoop{},
+	}
+	for name, l := range loops {
+		base := l.start
+		if l.last.After(base) {
+			base = l.last
+		}
+		if now.Sub(base) <= l.interval*global.DefaultStallFactor+l.timeout {
+			continue
+		}
+		r.Stalled = append(r.Stalled, StalledLoop{
+			Name:     name,
+			Kind:     l.kind,
+			Interval: l.interval,
+			Last:     l.last,
+			Stalled:  now.Sub(base).Round(time.Second),
+		})
+	}
+	sort.Slice(r.Stalled, func(i, j int) bool { return r.Stalled[i].Name < r.Stalled[j].Name })
+	r.Ready = r.Loaded && r.Expected > 0 && r.Scheduled >= r.Expected && len(r.Stalled) == 0
+	return r
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLive(t *testing.T) {
+	l := Live(startTime.Add(time.Minute))
+	assert.Equal(t, "ok", l.Status)
+	assert.Equal(t, global.Ver, l.Version)
+	assert.Equal(t, time.Minute, l.Uptime)
+}
+
+func TestReady(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	r := Ready(time.Now())
+	assert.False(t, r.Ready)
+	assert.False(t, r.Loaded)
+	assert.Equal(t, []StalledLoop{}, r.Stalled)
+
+	SetConfig("config.yaml")
+	SetExpected(2)
+	Schedule("http", "web", time.Minute, time.Second, 0)
+	r = Ready(time.Now())
+	assert.False(t, r.Ready)
+	assert.True(t, r.Loaded)
+	assert.Equal(t, "config.yaml", r.Config)
+	assert.Equal(t, 1, r.Scheduled)
+
+	Schedule("tcp", "db", time.Minute, time.Second, time.Hour)
+	r = Ready(time.Now())
+	assert.True(t, r.Ready)
+	assert.Equal(t, 2, r.Scheduled)
+
+	// the loop without the iteration since it started
+	now := time.Now().Add(global.DefaultStallFactor*time.Minute + 2*time.Second)
+	r = Ready(now)
+	assert.False(t, r.Ready)
+	assert.Equal(t, 1, len(r.Stalled))
+	assert.Equal(t, "web", r.Stalled[0].Name)
+	assert.Equal(t, "http", r.Stalled[0].Kind)
+	assert.True(t, r.Stalled[0].Last.IsZero())
+
+	// the loop is running
+	Beat("web")
+	Beat("none")
+	assert.True(t, Ready(time.Now()).Ready)
+	r = Ready(time.Now().Add(time.Hour + global.DefaultStallFactor*time.Minute + 2*time.Second))
+	assert.Equal(t, 2, len(r.Stalled))
+	assert.Equal(t, "db", r.Stalled[0].Name)
+	assert.False(t, r.Stalled[1].Last.IsZero())
+}
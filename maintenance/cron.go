@@ -0,0 +1,124 @@
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField is the allowed values of one field of the cron expression
+type cronField struct {
+	values map[int]bool
+	any    bool // the field is "*"
+}
+
+func (f cronField) match(v int) bool {
+	return f.values[v]
+}
+
+// cronSchedule is the standard 5 fields cron expression:
+// minute, hour, day of month, month, day of week
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// parseCron parses the cron expression, e.g. "30 2 * * sat,sun"
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression [%s], 5 fields are required", expr)
+	}
+	var (
+		s   cronSchedule
+		err error
+	)
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, err
+	}
+	// both 0 and 7 are Sunday
+	if s.dow.values[7] {
+		s.dow.values[0] = true
+	}
+	return &s, nil
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// parseCronField parses the field which supports "*", "a", "a-b", "*/n", "a-b/n" and the list of them
+func parseCronField(field string, min, max int, names map[string]int) (cronField, error) {
+	f := cronField{values: map[int]bool{}, any: field == "*"}
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return f, fmt.Errorf("invalid step in cron field [%s]", field)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], names); err != nil {
+				return f, fmt.Errorf("invalid value in cron field [%s]", field)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = parseCronValue(bounds[1], names); err != nil {
+					return f, fmt.Errorf("invalid value in cron field [%s]", field)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return f, fmt.Errorf("out of range value in cron field [%s]", field)
+		}
+		for v := lo; v <= hi; v += step {
+			f.values[v] = true
+		}
+	}
+	return f, nil
+}
+
+// match returns true if the minute of the time matches the schedule
+func (s *cronSchedule) match(t time.Time) bool {
+	if !s.minute.match(t.Minute()) || !s.hour.match(t.Hour()) || !s.month.match(int(t.Month())) {
+		return false
+	}
+	// the day matches if either day of month or day of week matches when both of them are restricted
+	dom, dow := s.dom.match(t.Day()), s.dow.match(int(t.Weekday()))
+	if s.dom.any || s.dow.any {
+		return dom && dow
+	}
+	return dom || dow
+}
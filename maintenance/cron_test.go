@@ -0,0 +1,53 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	s, err := parseCron("*/15 2-4 * jan,dec sat,sun")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(s.minute.values))
+	assert.True(t, s.minute.match(45))
+	assert.False(t, s.minute.match(10))
+	assert.Equal(t, 3, len(s.hour.values))
+	assert.True(t, s.dom.any)
+	assert.True(t, s.month.match(1) && s.month.match(12) && !s.month.match(6))
+	assert.True(t, s.dow.match(0) && s.dow.match(6) && !s.dow.match(1))
+
+	s, err = parseCron("0 0 1 * 7")
+	assert.Nil(t, err)
+	assert.True(t, s.dow.match(0))
+
+	for _, expr := range []string{
+		"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *", "1-b * * * *",
+	} {
+		_, err := parseCron(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
+
+func TestCronMatch(t *testing.T) {
+	// 2024-06-01 is Saturday
+	sat := time.Date(2024, 6, 1, 3, 30, 0, 0, time.UTC)
+
+	s, _ := parseCron("30 3 * * *")
+	assert.True(t, s.match(sat))
+	assert.False(t, s.match(sat.Add(time.Minute)))
+
+	s, _ = parseCron("30 3 * * mon")
+	assert.False(t, s.match(sat))
+
+	// both day of month and day of week are restricted, either of them matches
+	s, _ = parseCron("30 3 15 * sat")
+	assert.True(t, s.match(sat))
+	assert.True(t, s.match(time.Date(2024, 6, 15, 3, 30, 0, 0, time.UTC)))
+	assert.False(t, s.match(time.Date(2024, 6, 14, 3, 30, 0, 0, time.UTC)))
+
+	s, _ = parseCron("30 3 15 * *")
+	assert.False(t, s.match(sat))
+}
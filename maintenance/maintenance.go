@@ -0,0 +1,215 @@
+// Package maintenance is the maintenance windows which suppress the alerts of the probes
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+)
+
+// The time layouts of the one-off maintenance window, the time zone is the `settings.timezone`
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Window is the maintenance window, it could be
+//   - one-off: `start` and `end`
+//   - recurring by weekdays: `time` range (e.g. "22:00-02:00") and optional `weekdays`
+//   - recurring by cron: `cron` and `duration`
+//
+// The window applies to all probes if no probe names, kinds and labels are specified.
+type Window struct {
+	Name        string            `yaml:"name" json:"name" jsonschema:"required,title=Name,description=the unique name of the maintenance window"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"title=Description,description=the description of the maintenance window"`
+	Start       string            `yaml:"start,omitempty" json:"start,omitempty" jsonschema:"title=Start Time,description=the start time of the one-off window,example=2024-05-01 22:00"`
+	End         string            `yaml:"end,omitempty" json:"end,omitempty" jsonschema:"title=End Time,description=the end time of the one-off window,example=2024-05-02 02:00"`
+	Weekdays    []string          `yaml:"weekdays,omitempty" json:"weekdays,omitempty" jsonschema:"title=Weekdays,description=the weekdays of the recurring window (default is every day),example=[\"sat\"\\,\"sun\"]"`
+	Time        string            `yaml:"time,omitempty" json:"time,omitempty" jsonschema:"title=Time Range,description=the daily time range of the recurring window,example=22:00-02:00"`
+	Cron        string            `yaml:"cron,omitempty" json:"cron,omitempty" jsonschema:"title=Cron,description=the cron expression of the window start,example=0 3 * * *"`
+	Duration    time.Duration     `yaml:"duration,omitempty" json:"duration,omitempty" jsonschema:"type=string,format=duration,title=Duration,description=the duration of the cron window"`
+	Probes      []string          `yaml:"probes,omitempty" json:"probes,omitempty" jsonschema:"title=Probes,description=the names of the probes in maintenance"`
+	Kinds       []string          `yaml:"kinds,omitempty" json:"kinds,omitempty" jsonschema:"title=Kinds,description=the kinds of the probes in maintenance"`
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty" jsonschema:"title=Labels,description=the labels of the probes in maintenance"`
+	ExcludeSLA  bool              `yaml:"exclude_sla,omitempty" json:"exclude_sla,omitempty" jsonschema:"title=Exclude SLA,description=exclude the window from the SLA calculation"`
+
+	start, end time.Time     `yaml:"-" json:"-"`
+	weekdays   map[int]bool  `yaml:"-" json:"-"`
+	from, to   int           `yaml:"-" json:"-"` // minutes of the day
+	cron       *cronSchedule `yaml:"-" json:"-"`
+}
+
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(s), loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time [%s]", s)
+}
+
+// parseClock parses "HH:MM" into the minutes of the day
+func parseClock(s string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time of day [%s]", s)
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	m, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time of day [%s]", s)
+	}
+	return h*60 + m, nil
+}
+
+// parseWeekday parses the weekday name, e.g. "sat" or "Saturday"
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 3 {
+		return 0, false
+	}
+	day, ok := dayNames[s[:3]]
+	if !ok || (len(s) > 3 && s != strings.ToLower(day.String())) {
+		return 0, false
+	}
+	return day, true
+}
+
+// Config validates the window and parses the times with the global time zone
+func (w *Window) Config() error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return fmt.Errorf("the name of the maintenance window is required")
+	}
+	loc := global.GetTimeLocation()
+
+	kinds := 0
+	if w.Start != "" || w.End != "" {
+		kinds++
+		var err error
+		if w.start, err = parseTime(w.Start, loc); err != nil {
+			return fmt.Errorf("[%s] %v", w.Name, err)
+		}
+		if w.end, err = parseTime(w.End, loc); err != nil {
+			return fmt.Errorf("[%s] %v", w.Name, err)
+		}
+		if !w.end.After(w.start) {
+			return fmt.Errorf("[%s] the end time must be after the start time", w.Name)
+		}
+	}
+
+	if w.Time != "" {
+		kinds++
+		rng := strings.SplitN(w.Time, "-", 2)
+		if len(rng) != 2 {
+			return fmt.Errorf("[%s] invalid time range [%s], e.g. 22:00-02:00", w.Name, w.Time)
+		}
+		var err error
+		if w.from, err = parseClock(rng[0]); err != nil {
+			return fmt.Errorf("[%s] %v", w.Name, err)
+		}
+		if w.to, err = parseClock(rng[1]); err != nil {
+			return fmt.Errorf("[%s] %v", w.Name, err)
+		}
+		if w.from == w.to {
+			return fmt.Errorf("[%s] empty time range [%s]", w.Name, w.Time)
+		}
+	}
+	w.weekdays = map[int]bool{}
+	for _, d := range w.Weekdays {
+		day, ok := parseWeekday(d)
+		if !ok {
+			return fmt.Errorf("[%s] invalid weekday [%s]", w.Name, d)
+		}
+		w.weekdays[int(day)] = true
+	}
+	if len(w.Weekdays) > 0 && w.Time == "" {
+		return fmt.Errorf("[%s] the time range is required for the weekdays", w.Name)
+	}
+
+	if w.Cron != "" {
+		kinds++
+		var err error
+		if w.cron, err = parseCron(w.Cron); err != nil {
+			return fmt.Errorf("[%s] %v", w.Name, err)
+		}
+		if w.Duration <= 0 {
+			return fmt.Errorf("[%s] the duration is required for the cron window", w.Name)
+		}
+	}
+
+	if kinds != 1 {
+		return fmt.Errorf("[%s] one of the start/end, time range or cron is required", w.Name)
+	}
+	return nil
+}
+
+// Match returns true if the probe is in the scope of the window
+func (w *Window) Match(name, kind string, labels map[string]string) bool {
+	if len(w.Probes) == 0 && len(w.Kinds) == 0 && len(w.Labels) == 0 {
+		return true
+	}
+	for _, p := range w.Probes {
+		if p == name {
+			return true
+		}
+	}
+	for _, k := range w.Kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+	if len(w.Labels) == 0 {
+		return false
+	}
+	for k, v := range w.Labels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *Window) isWeekday(d time.Weekday) bool {
+	return len(w.weekdays) == 0 || w.weekdays[int(d)]
+}
+
+// IsActive returns true if the time is in the window
+func (w *Window) IsActive(t time.Time) bool {
+	t = t.In(global.GetTimeLocation())
+	switch {
+	case !w.start.IsZero():
+		return !t.Before(w.start) && t.Before(w.end)
+
+	case w.cron != nil:
+		// check whether there is a start of the window in the past duration
+		for s := t.Truncate(time.Minute); t.Sub(s) < w.Duration; s = s.Add(-time.Minute) {
+			if w.cron.match(s) {
+				return true
+			}
+		}
+		return false
+
+	case w.from != w.to:
+		m := t.Hour()*60 + t.Minute()
+		if w.from < w.to {
+			return w.isWeekday(t.Weekday()) && m >= w.from && m < w.to
+		}
+		// the range crosses the midnight, the weekday is the day when the window starts
+		if m >= w.from {
+			return w.isWeekday(t.Weekday())
+		}
+		return m < w.to && w.isWeekday(t.AddDate(0, 0, -1).Weekday())
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneOffWindow(t *testing.T) {
+	global.SetTimeZone("Asia/Shanghai")
+	defer global.SetTimeZone("UTC")
+
+	w := Window{Name: " upgrade ", Start: "2024-06-01 22:00", End: "2024-06-02 02:00"}
+	assert.Nil(t, w.Config())
+	assert.Equal(t, "upgrade", w.Name)
+
+	// the time is in the time zone of the settings (UTC+8)
+	assert.False(t, w.IsActive(time.Date(2024, 6, 1, 13, 59, 0, 0, time.UTC)))
+	assert.True(t, w.IsActive(time.Date(2024, 6, 1, 14, 0, 0, 0, time.UTC)))
+	assert.True(t, w.IsActive(time.Date(2024, 6, 1, 17, 59, 0, 0, time.UTC)))
+	assert.False(t, w.IsActive(time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)))
+
+	w = Window{Name: "rfc3339", Start: "2024-06-01T12:00:00Z", End: "2024-06-02"}
+	assert.Nil(t, w.Config())
+	assert.True(t, w.IsActive(time.Date(2024, 6, 1, 15, 0, 0, 0, time.UTC)))
+}
+
+func TestRecurringWindow(t *testing.T) {
+	// 2024-06-01 is Saturday
+	w := Window{Name: "nightly", Time: "22:00-02:00", Weekdays: []string{"Saturday"}}
+	assert.Nil(t, w.Config())
+	assert.True(t, w.IsActive(time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, w.IsActive(time.Date(2024, 6, 2, 1, 59, 0, 0, time.UTC)))
+	assert.False(t, w.IsActive(time.Date(2024, 6, 2, 2, 0, 0, 0, time.UTC)))
+	assert.False(t, w.IsActive(time.Date(2024, 6, 1, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, w.IsActive(time.Date(2024, 6, 2, 23, 0, 0, 0, time.UTC)))
+
+	w = Window{Name: "lunch", Time: "12:00-13:00"}
+	assert.Nil(t, w.Config())
+	assert.True(t, w.IsActive(time.Date(2024, 6, 3, 12, 30, 0, 0, time.UTC)))
+	assert.False(t, w.IsActive(time.Date(2024, 6, 3, 13, 0, 0, 0, time.UTC)))
+
+	w = Window{Name: "cron", Cron: "50 23 * * fri", Duration: 30 * time.Minute}
+	assert.Nil(t, w.Config())
+	assert.False(t, w.IsActive(time.Date(2024, 5, 31, 23, 49, 59, 0, time.UTC)))
+	assert.True(t, w.IsActive(time.Date(2024, 5, 31, 23, 50, 0, 0, time.UTC)))
+	assert.True(t, w.IsActive(time.Date(2024, 6, 1, 0, 19, 59, 0, time.UTC)))
+	assert.False(t, w.IsActive(time.Date(2024, 6, 1, 0, 20, 0, 0, time.UTC)))
+}
+
+func TestWindowConfigError(t *testing.T) {
+	for _, w := range []Window{
+		{},
+		{Name: "nothing"},
+		{Name: "both", Time: "01:00-02:00", Cron: "* * * * *", Duration: time.Minute},
+		{Name: "bad start", Start: "yesterday", End: "2024-06-01"},
+		{Name: "bad end", Start: "2024-06-01", End: "tomorrow"},
+		{Name: "reverse", Start: "2024-06-02", End: "2024-06-01"},
+		{Name: "bad range", Time: "01:00"},
+		{Name: "bad from", Time: "25:00-02:00"},
+		{Name: "bad to", Time: "01:00-02:60"},
+		{Name: "empty range", Time: "01:00-01:00"},
+		{Name: "bad weekday", Time: "01:00-02:00", Weekdays: []string{"someday"}},
+		{Name: "bad weekday", Time: "01:00-02:00", Weekdays: []string{"satx"}},
+		{Name: "weekday only", Weekdays: []string{"sat"}},
+		{Name: "bad cron", Cron: "* * *", Duration: time.Minute},
+		{Name: "no duration", Cron: "* * * * *"},
+	} {
+		assert.NotNil(t, w.Config(), w.Name)
+	}
+}
+
+func TestWindowMatch(t *testing.T) {
+	w := Window{}
+	assert.True(t, w.Match("any", "http", nil))
+
+	w = Window{Probes: []string{"db"}, Kinds: []string{"TCP"}, Labels: map[string]string{"env": "stage", "team": "sre"}}
+	assert.True(t, w.Match("db", "http", nil))
+	assert.True(t, w.Match("web", "tcp", nil))
+	assert.True(t, w.Match("web", "http", map[string]string{"env": "stage", "team": "sre", "x": "y"}))
+	assert.False(t, w.Match("web", "http", map[string]string{"env": "stage"}))
+	assert.False(t, w.Match("web", "http", nil))
+
+	w = Window{Probes: []string{"db"}}
+	assert.False(t, w.Match("web", "http", map[string]string{"env": "stage"}))
+}
@@ -0,0 +1,96 @@
+package maintenance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const kind = "maintenance"
+
+var (
+	windows []*Window
+	mutex   = &sync.RWMutex{}
+)
+
+// SetWindows replaces all of the maintenance windows, the invalid windows are ignored
+func SetWindows(list []Window) {
+	valid := []*Window{}
+	names := map[string]bool{}
+	for i := range list {
+		w := list[i]
+		if err := w.Config(); err != nil {
+			log.Errorf("[%s] Bad maintenance window configuration: %v", kind, err)
+			continue
+		}
+		if names[w.Name] {
+			log.Errorf("[%s] Duplicated maintenance window name [%s], ignored", kind, w.Name)
+			continue
+		}
+		names[w.Name] = true
+		valid = append(valid, &w)
+		log.Infof("[%s] Maintenance window [%s] is configured", kind, w.Name)
+	}
+	mutex.Lock()
+	windows = valid
+	mutex.Unlock()
+}
+
+// AddWindow adds or replaces the maintenance window with the same name
+func AddWindow(w Window) error {
+	if err := w.Config(); err != nil {
+		return err
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, old := range windows {
+		if old.Name == w.Name {
+			windows[i] = &w
+			log.Infof("[%s] Maintenance window [%s] is updated", kind, w.Name)
+			return nil
+		}
+	}
+	windows = append(windows, &w)
+	log.Infof("[%s] Maintenance window [%s] is added", kind, w.Name)
+	return nil
+}
+
+// RemoveWindow removes the maintenance window by name
+func RemoveWindow(name string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, w := range windows {
+		if w.Name == name {
+			windows = append(windows[:i], windows[i+1:]...)
+			log.Infof("[%s] Maintenance window [%s] is removed", kind, name)
+			return nil
+		}
+	}
+	return fmt.Errorf("maintenance window [%s] not found", name)
+}
+
+// GetWindows returns all of the maintenance windows
+func GetWindows() []Window {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	list := make([]Window, 0, len(windows))
+	for _, w := range windows {
+		list = append(list, *w)
+	}
+	return list
+}
+
+// Find returns the active maintenance window of the probe at the time, nil if the probe is not in maintenance
+func Find(name, probeKind string, labels map[string]string, t time.Time) *Window {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, w := range windows {
+		if w.Match(name, probeKind, labels) && w.IsActive(t) {
+			found := *w
+			return &found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager(t *testing.T) {
+	SetWindows([]Window{
+		{Name: "db", Time: "00:00-24:00", Kinds: []string{"mysql"}, ExcludeSLA: true},
+		{Name: "invalid"},
+		{Name: "db", Time: "01:00-02:00"},
+		{Name: "past", Start: "2000-01-01", End: "2000-01-02"},
+	})
+	defer SetWindows(nil)
+
+	list := GetWindows()
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "db", list[0].Name)
+	assert.Equal(t, "past", list[1].Name)
+
+	now := time.Now()
+	w := Find("mydb", "mysql", nil, now)
+	assert.NotNil(t, w)
+	assert.Equal(t, "db", w.Name)
+	assert.True(t, w.ExcludeSLA)
+	assert.Nil(t, Find("web", "http", nil, now))
+
+	// add a new window and replace the existing one
+	assert.Nil(t, AddWindow(Window{Name: "web", Time: "00:00-24:00", Probes: []string{"web"}}))
+	assert.NotNil(t, Find("web", "http", nil, now))
+	assert.Nil(t, AddWindow(Window{Name: "db", Time: "00:00-24:00", Kinds: []string{"redis"}}))
+	assert.Equal(t, 3, len(GetWindows()))
+	assert.Nil(t, Find("mydb", "mysql", nil, now))
+	assert.NotNil(t, AddWindow(Window{Name: "invalid"}))
+
+	assert.Nil(t, RemoveWindow("web"))
+	assert.NotNil(t, RemoveWindow("web"))
+	assert.Nil(t, Find("web", "http", nil, now))
+	assert.Equal(t, 2, len(GetWindows()))
+}
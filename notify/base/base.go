@@ -0,0 +1,278 @@
+// Package base is the base implementation of the notification.
+package base
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// SendFuncType is the function type to send the message
+type SendFuncType func(title, message string) error
+
+// FormatFuncType is the function type to render the result into the message
+type FormatFuncType func(result probe.Result) string
+
+// DigestFuncType is the function type to render the results into one digest message
+type DigestFuncType func(results []probe.Result) string
+
+// StatFuncType is the function type to render the SLA report of the probers
+type StatFuncType func(probers []probe.Prober) string
+
+// DefaultNotify is the base struct of the Notify
+type DefaultNotify struct {
+	NotifyKind       string          `yaml:"-" json:"-"`
+	NotifyFormat     report.Format   `yaml:"-" json:"-"`
+	NotifyFormatFunc FormatFuncType  `yaml:"-" json:"-"`
+	NotifyDigestFunc DigestFuncType  `yaml:"-" json:"-"`
+	NotifyBurnFunc   FormatFuncType  `yaml:"-" json:"-"`
+	NotifyStatFunc   StatFuncType    `yaml:"-" json:"-"`
+	NotifySendFunc   SendFuncType    `yaml:"-" json:"-"`
+	NotifyName       string          `yaml:"name" json:"name" jsonschema:"required,title=Notification Name,description=The name of the notification"`
+	NotifyChannel    []string        `yaml:"channels,omitempty" json:"channels,omitempty" jsonschema:"title=Notification Channels,description=The channels of the notification"`
+	Timeout          time.Duration   `yaml:"timeout,omitempty" json:"timeout,omitempty" jsonschema:"type=string,format=duration,title=Timeout,description=The timeout of the notification"`
+	Retry            global.Retry    `yaml:"retry,omitempty" json:"retry,omitempty" jsonschema:"title=Retry,description=The retry of the notification"`
+	Dry              bool            `yaml:"dry,omitempty" json:"dry,omitempty" jsonschema:"title=Dry Notification,description=render the notification into the log instead of sending it"`
+	MessageTemplate  report.Template `yaml:"message,omitempty" json:"message,omitempty" jsonschema:"title=Message Template,description=The title and body templates of the message which could be overwritten by the probe (the body is not used by the structured payload e.g. webhook / shell / log)"`
+}
+
+// Kind returns the kind of the notification
+func (c *DefaultNotify) Kind() string {
+	return c.NotifyKind
+}
+
+// Name returns the name of the notification
+func (c *DefaultNotify) Name() string {
+	return c.NotifyName
+}
+
+// Channels returns the channels of the notification
+func (c *DefaultNotify) Channels() []string {
+	return c.NotifyChannel
+}
+
+// LogTitle return the log title
+func (c *DefaultNotify) LogTitle() string {
+	return fmt.Sprintf("[%s / %s]", c.NotifyKind, c.NotifyName)
+}
+
+// Config is the default configuration for notification
+func (c *DefaultNotify) Config(gConf global.NotifySettings) error {
+	if c.NotifySendFunc == nil {
+		return fmt.Errorf("%s - the send function is not configured", c.LogTitle())
+	}
+
+	if err := c.MessageTemplate.Validate(); err != nil {
+		return fmt.Errorf("%s - %v", c.LogTitle(), err)
+	}
+
+	c.Timeout = gConf.NormalizeTimeOut(c.Timeout)
+	c.Retry = gConf.NormalizeRetry(c.Retry)
+	c.Dry = c.Dry || gConf.Dry
+
+	// if there no channels, use the default channel
+	if len(c.NotifyChannel) == 0 {
+		c.NotifyChannel = append(c.NotifyChannel, global.DefaultChannelName)
+	}
+
+	log.Infof("Notification %s is configured! timeout[%s], retry[%d / %s], channels%v, dry[%v]",
+		c.LogTitle(), c.Timeout, c.Retry.Times, c.Retry.Interval, c.NotifyChannel, c.Dry)
+	return nil
+}
+
+// Notify send the result message to the channel
+func (c *DefaultNotify) Notify(result probe.Result) {
+	title := c.RenderTitle(result)
+	message := c.FormatResult(result)
+	if err := c.SendWithRetry(title, message, "Notification"); err != nil {
+		log.Errorf("%s - %v", c.LogTitle(), err)
+		return
+	}
+	if !c.Dry {
+		log.Infof("%s - Successfully sent the notification for [%s]", c.LogTitle(), result.Name)
+	}
+}
+
+// template return the message template of the result, the probe's template has the higher priority
+func (c *DefaultNotify) template(result probe.Result) report.Template {
+	return report.GetProbeContext(result.Name).Template.Merge(c.MessageTemplate)
+}
+
+// RenderTitle render the title with the message template, the result title is used if no template
+func (c *DefaultNotify) RenderTitle(result probe.Result) string {
+	if title, ok := c.renderTitle(result); ok {
+		return title
+	}
+	return result.Title()
+}
+
+// renderTitle render the title with the message template, false is returned if no template
+func (c *DefaultNotify) renderTitle(result probe.Result) (string, bool) {
+	title, err := c.template(result).RenderTitle(report.NewTemplateData(result, c.NotifyName))
+	if err != nil {
+		log.Errorf("%s - failed to render the title template: %v", c.LogTitle(), err)
+		return "", false
+	}
+	return title, title != ""
+}
+
+// RenderBody render the body with the message template, false is returned if no template
+func (c *DefaultNotify) RenderBody(result probe.Result) (string, bool) {
+	tpl := c.template(result)
+	if strings.TrimSpace(tpl.Body) == "" {
+		return "", false
+	}
+	body, err := tpl.RenderBody(report.NewTemplateData(result, c.NotifyName), c.NotifyFormat == report.HTML)
+	if err != nil {
+		log.Errorf("%s - failed to render the body template: %v", c.LogTitle(), err)
+		return "", false
+	}
+	return body, true
+}
+
+// FormatResult render the result with the notification format
+// the customized format function has the higher priority than the message template and the format
+func (c *DefaultNotify) FormatResult(result probe.Result) string {
+	if c.NotifyFormatFunc != nil {
+		return c.NotifyFormatFunc(result)
+	}
+	fn, found := report.FormatFuncs[c.NotifyFormat]
+	if found && fn.CardFn != nil {
+		// the chat platforms only accept their own payload, the templates override its title and message
+		title, message := report.ResultTitle(result), result.Message
+		if t, ok := c.renderTitle(result); ok {
+			title = t
+		}
+		if body, ok := c.RenderBody(result); ok {
+			message = body
+		}
+		return fn.CardFn(result, title, message)
+	}
+	if body, ok := c.RenderBody(result); ok {
+		return body
+	}
+	if found {
+		return fn.ResultFn(result)
+	}
+	return report.ToText(result)
+}
+
+// NotifyDigest send the results in one digest message
+// the notification with the structured payload (customized format function but no digest function)
+// sends the results one by one, because its receiver expects one result per message
+func (c *DefaultNotify) NotifyDigest(results []probe.Result) {
+	if c.NotifyDigestFunc == nil && c.NotifyFormatFunc != nil {
+		for _, r := range results {
+			c.Notify(r)
+		}
+		return
+	}
+	title := report.DigestTitle(results)
+	message := c.FormatDigest(results)
+	if err := c.SendWithRetry(title, message, "Digest"); err != nil {
+		log.Errorf("%s - %v", c.LogTitle(), err)
+		return
+	}
+	if !c.Dry {
+		log.Infof("%s - Successfully sent the digest of %d probes", c.LogTitle(), len(results))
+	}
+}
+
+// FormatDigest render the results with the notification format
+func (c *DefaultNotify) FormatDigest(results []probe.Result) string {
+	if c.NotifyDigestFunc != nil {
+		return c.NotifyDigestFunc(results)
+	}
+	if fn, ok := report.FormatFuncs[c.NotifyFormat]; ok && fn.DigestFn != nil {
+		return fn.DigestFn(results)
+	}
+	return report.ToDigestText(results)
+}
+
+// NotifyStat send the SLA report of the probers
+func (c *DefaultNotify) NotifyStat(probers []probe.Prober) {
+	title := report.SLATitle(probers)
+	message := c.FormatStat(probers)
+	if err := c.SendWithRetry(title, message, "SLA"); err != nil {
+		log.Errorf("%s - %v", c.LogTitle(), err)
+		return
+	}
+	if !c.Dry {
+		log.Infof("%s - Successfully sent the SLA report of %d probes", c.LogTitle(), len(probers))
+	}
+}
+
+// FormatStat render the SLA report of the probers with the notification format
+func (c *DefaultNotify) FormatStat(probers []probe.Prober) string {
+	if c.NotifyStatFunc != nil {
+		return c.NotifyStatFunc(probers)
+	}
+	if fn, ok := report.FormatFuncs[c.NotifyFormat]; ok && fn.StatFn != nil {
+		return fn.StatFn(probers)
+	}
+	return report.ToSLAText(probers)
+}
+
+// NotifyBurn send the error budget burn rate alert of the result
+func (c *DefaultNotify) NotifyBurn(result probe.Result) {
+	title := report.SLOTitle(result)
+	message := c.FormatBurn(result)
+	if err := c.SendWithRetry(title, message, "SLO"); err != nil {
+		log.Errorf("%s - %v", c.LogTitle(), err)
+		return
+	}
+	if !c.Dry {
+		log.Infof("%s - Successfully sent the SLO alert for [%s]", c.LogTitle(), result.Name)
+	}
+}
+
+// FormatBurn render the burn rate alert with the notification format
+func (c *DefaultNotify) FormatBurn(result probe.Result) string {
+	if c.NotifyBurnFunc != nil {
+		return c.NotifyBurnFunc(result)
+	}
+	if fn, ok := report.FormatFuncs[c.NotifyFormat]; ok && fn.SLOFn != nil {
+		return fn.SLOFn(result)
+	}
+	return report.ToSLOText(result)
+}
+
+// SendWithRetry send the notification with retry, every attempt is limited by the timeout
+// In the dry mode, the message is only written into the log
+func (c *DefaultNotify) SendWithRetry(title, message, tag string) error {
+	return c.SendFuncWithRetry(c.NotifySendFunc, title, message, tag)
+}
+
+// SendFuncWithRetry is the same as SendWithRetry, but the message is sent by the function
+func (c *DefaultNotify) SendFuncWithRetry(send SendFuncType, title, message, tag string) error {
+	if c.Dry {
+		log.Infof("%s - Dry %s - [%s]\n%s", c.LogTitle(), tag, title, message)
+		return nil
+	}
+	fn := func() error {
+		log.Debugf("%s - %s - %s", c.LogTitle(), tag, title)
+		return c.sendWithTimeout(send, title, message)
+	}
+	return global.DoRetry(c.NotifyKind, c.NotifyName, tag, c.Retry, fn)
+}
+
+func (c *DefaultNotify) sendWithTimeout(send SendFuncType, title, message string) error {
+	if c.Timeout <= 0 {
+		return send(title, message)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- send(title, message)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(c.Timeout):
+		return fmt.Errorf("timeout after %s", c.Timeout)
+	}
+}
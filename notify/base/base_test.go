@@ -0,0 +1,358 @@
+package base
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "dummy://endpoint"
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = "dummy failure"
+	return *r
+}
+
+func TestDefaultNotify(t *testing.T) {
+	n := &DefaultNotify{
+		NotifyKind: "dummy",
+		NotifyName: "dummy notify",
+	}
+	err := n.Config(global.NotifySettings{})
+	assert.NotNil(t, err)
+
+	var title, message string
+	n.NotifySendFunc = func(t, m string) error {
+		title, message = t, m
+		return nil
+	}
+	err = n.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "dummy", n.Kind())
+	assert.Equal(t, "dummy notify", n.Name())
+	assert.Equal(t, []string{global.DefaultChannelName}, n.Channels())
+	assert.Equal(t, global.DefaultTimeOut, n.Timeout)
+	assert.Equal(t, global.DefaultRetryTimes, n.Retry.Times)
+	assert.Equal(t, global.DefaultRetryInterval, n.Retry.Interval)
+	assert.Equal(t, "[dummy / dummy notify]", n.LogTitle())
+
+	r := newResult()
+	n.Notify(r)
+	assert.Equal(t, r.Title(), title)
+	assert.Equal(t, report.ToText(r), message)
+
+	n.NotifyFormat = report.JSON
+	n.Notify(r)
+	assert.Equal(t, report.ToJSON(r), message)
+
+	n.NotifyFormat = report.Format(100)
+	assert.Equal(t, report.ToText(r), n.FormatResult(r))
+
+	n.NotifyFormatFunc = func(r probe.Result) string { return "custom " + r.Name }
+	assert.Equal(t, "custom dummy probe", n.FormatResult(r))
+	n.NotifyFormatFunc = nil
+
+	n.NotifyChannel = []string{"a", "b"}
+	n.Timeout = 0
+	n.Config(global.NotifySettings{Timeout: time.Second})
+	assert.Equal(t, []string{"a", "b"}, n.Channels())
+	assert.Equal(t, time.Second, n.Timeout)
+}
+
+func TestSendWithRetry(t *testing.T) {
+	var cnt int32
+	n := &DefaultNotify{
+		NotifyKind: "dummy",
+		NotifyName: "retry",
+		Retry:      global.Retry{Times: 3, Interval: time.Millisecond},
+		Timeout:    100 * time.Millisecond,
+		NotifySendFunc: func(t, m string) error {
+			atomic.AddInt32(&cnt, 1)
+			return fmt.Errorf("send error")
+		},
+	}
+	n.Config(global.NotifySettings{})
+
+	err := n.SendWithRetry("title", "message", "test")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "send error")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&cnt))
+
+	// no need to retry
+	atomic.StoreInt32(&cnt, 0)
+	n.NotifySendFunc = func(t, m string) error {
+		atomic.AddInt32(&cnt, 1)
+		return &global.ErrNoRetry{Message: "no retry"}
+	}
+	err = n.SendWithRetry("title", "message", "test")
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cnt))
+
+	// success at the second time
+	atomic.StoreInt32(&cnt, 0)
+	n.NotifySendFunc = func(t, m string) error {
+		if atomic.AddInt32(&cnt, 1) < 2 {
+			return fmt.Errorf("send error")
+		}
+		return nil
+	}
+	err = n.SendWithRetry("title", "message", "test")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&cnt))
+
+	// timeout
+	n.NotifySendFunc = func(t, m string) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	err = n.SendWithRetry("title", "message", "test")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+
+	// failed notification is only logged
+	n.Notify(newResult())
+}
+
+func TestDryNotify(t *testing.T) {
+	var cnt int32
+	n := &DefaultNotify{
+		NotifyKind: "dummy",
+		NotifyName: "dry",
+		NotifySendFunc: func(t, m string) error {
+			atomic.AddInt32(&cnt, 1)
+			return nil
+		},
+	}
+	err := n.Config(global.NotifySettings{Dry: true})
+	assert.Nil(t, err)
+	assert.True(t, n.Dry)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	r := newResult()
+	n.Notify(r)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&cnt))
+	assert.Contains(t, buf.String(), "Dry Notification")
+	assert.Contains(t, buf.String(), "dummy failure")
+
+	// the dry mode of the notification is not overwritten by the global setting
+	n.Config(global.NotifySettings{})
+	assert.True(t, n.Dry)
+	n.Dry = false
+	n.Config(global.NotifySettings{})
+	n.Notify(r)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cnt))
+}
+
+func TestMessageTemplate(t *testing.T) {
+	var title, message string
+	n := &DefaultNotify{
+		NotifyKind: "dummy",
+		NotifyName: "template",
+		NotifySendFunc: func(t, m string) error {
+			title, message = t, m
+			return nil
+		},
+		MessageTemplate: report.Template{
+			Title: "{{.Notify}}: {{.Name}} is {{.Status}}",
+			Body:  "<b>{{.Message}}</b>",
+		},
+	}
+	err := n.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+
+	r := newResult()
+	n.Notify(r)
+	assert.Equal(t, "template: dummy probe is down", title)
+	assert.Equal(t, "<b>dummy failure</b>", message)
+
+	// the body is escaped by html/template for the HTML notification
+	r.Message = "<script>"
+	n.NotifyFormat = report.HTML
+	n.Notify(r)
+	assert.Equal(t, "<b>&lt;script&gt;</b>", message)
+
+	// the probe template has the higher priority
+	report.SetProbeContext(r.Name, report.ProbeContext{
+		Labels:   map[string]string{"env": "prod"},
+		Template: report.Template{Title: `[{{label .Labels "env"}}] {{.Title}}`},
+	})
+	defer report.SetProbeContext(r.Name, report.ProbeContext{})
+	n.Notify(r)
+	assert.Equal(t, "[prod] "+r.Title(), title)
+	assert.Equal(t, "<b>&lt;script&gt;</b>", message)
+
+	// the chat platforms keep their payload, the templates override the title and message of the card
+	n.NotifyFormat = report.Slack
+	n.Notify(r)
+	assert.Equal(t, "[prod] "+r.Title(), title)
+	assert.Equal(t, report.ToSlackCard(r, "[prod] "+r.Title(), "<b><script></b>"), message)
+	assert.True(t, json.Valid([]byte(message)))
+	report.SetProbeContext(r.Name, report.ProbeContext{})
+	n.MessageTemplate = report.Template{Title: "{{.Name}} is {{.Status}}"}
+	n.Notify(r)
+	assert.Equal(t, report.ToSlackCard(r, "dummy probe is down", r.Message), message)
+	n.MessageTemplate = report.Template{}
+	n.Notify(r)
+	assert.Equal(t, report.ToSlack(r), message)
+	n.NotifyFormat = report.HTML
+
+	// the customized format function has the highest priority
+	n.NotifyFormatFunc = func(r probe.Result) string { return "custom" }
+	n.Notify(r)
+	assert.Equal(t, "custom", message)
+
+	// failed to render, fallback to the default
+	n.NotifyFormatFunc = nil
+	n.MessageTemplate = report.Template{Title: "{{.Stat.Since.NoSuchMethod}}", Body: "{{.Stat.Since.NoSuchMethod}}"}
+	report.SetProbeContext(r.Name, report.ProbeContext{})
+	n.Notify(r)
+	assert.Equal(t, r.Title(), title)
+	assert.Equal(t, report.ToHTML(r), message)
+
+	// invalid template
+	n.MessageTemplate = report.Template{Body: "{{.Name"}
+	assert.NotNil(t, n.Config(global.NotifySettings{}))
+}
+
+func TestNotifyDigest(t *testing.T) {
+	var titles, messages []string
+	n := &DefaultNotify{
+		NotifyKind: "dummy",
+		NotifyName: "digest",
+		NotifySendFunc: func(t, m string) error {
+			titles = append(titles, t)
+			messages = append(messages, m)
+			return nil
+		},
+	}
+	assert.Nil(t, n.Config(global.NotifySettings{}))
+
+	r1, r2 := newResult(), newResult()
+	r2.Name = "another probe"
+	results := []probe.Result{r1, r2}
+
+	n.NotifyDigest(results)
+	assert.Equal(t, []string{"2 Probes Failure"}, titles)
+	assert.Contains(t, messages[0], "[2 Probes Failure]")
+	assert.Contains(t, messages[0], "another probe")
+
+	n.NotifyFormat = report.Markdown
+	assert.Equal(t, report.ToDigestMarkdown(results), n.FormatDigest(results))
+	n.NotifyFormat = report.Format(100)
+	assert.Contains(t, n.FormatDigest(results), "[2 Probes Failure]")
+
+	// the structured payload is sent one by one
+	titles = nil
+	n.NotifyFormatFunc = func(r probe.Result) string { return r.Name }
+	n.NotifyDigest(results)
+	assert.Equal(t, []string{r1.Title(), r2.Title()}, titles)
+	assert.Equal(t, []string{"dummy probe", "another probe"}, messages[len(messages)-2:])
+
+	// the customized digest function
+	titles = nil
+	n.NotifyDigestFunc = func(results []probe.Result) string { return fmt.Sprintf("%d results", len(results)) }
+	n.NotifyDigest(results)
+	assert.Equal(t, 1, len(titles))
+	assert.Equal(t, "2 results", messages[len(messages)-1])
+}
+
+type statProber struct {
+	result *probe.Result
+}
+
+func (d *statProber) LabelMap() prometheus.Labels          { return nil }
+func (d *statProber) SetLabelMap(labels prometheus.Labels) {}
+func (d *statProber) Kind() string                         { return "dummy" }
+func (d *statProber) Name() string                         { return d.result.Name }
+func (d *statProber) Channels() []string                   { return nil }
+func (d *statProber) Timeout() time.Duration               { return time.Second }
+func (d *statProber) Interval() time.Duration              { return time.Minute }
+func (d *statProber) Result() *probe.Result                { return d.result }
+func (d *statProber) Config(global.ProbeSettings) error    { return nil }
+func (d *statProber) Probe() probe.Result                  { return *d.result }
+
+func TestNotifyStat(t *testing.T) {
+	var titles, messages []string
+	var fail error
+	n := &DefaultNotify{
+		NotifyKind: "dummy",
+		NotifyName: "sla",
+		Retry:      global.Retry{Times: 2, Interval: time.Millisecond},
+		NotifySendFunc: func(t, m string) error {
+			titles = append(titles, t)
+			messages = append(messages, m)
+			return fail
+		},
+	}
+	assert.Nil(t, n.Config(global.NotifySettings{}))
+
+	r := newResult()
+	r.Stat.UpTime = 3 * time.Minute
+	r.Stat.DownTime = time.Minute
+	probers := []probe.Prober{&statProber{&r}}
+
+	n.NotifyStat(probers)
+	assert.Equal(t, []string{"SLA Report of 1 Probes"}, titles)
+	assert.Contains(t, messages[0], "dummy probe (dummy) - SLA 75.00%")
+
+	n.NotifyFormat = report.CSV
+	assert.Equal(t, report.ToSLACSV(probers), n.FormatStat(probers))
+	n.NotifyFormat = report.Format(100)
+	assert.Equal(t, report.ToSLAText(probers), n.FormatStat(probers))
+
+	// the error is only logged
+	fail = fmt.Errorf("send error")
+	n.NotifyStat(probers)
+	assert.Equal(t, 3, len(titles))
+}
+
+func TestNotifyBurn(t *testing.T) {
+	var titles, messages []string
+	n := &DefaultNotify{
+		NotifyKind: "dummy",
+		NotifyName: "slo",
+		NotifySendFunc: func(t, m string) error {
+			titles = append(titles, t)
+			messages = append(messages, m)
+			return nil
+		},
+	}
+	assert.Nil(t, n.Config(global.NotifySettings{}))
+
+	r := newResult()
+	r.SLO = &probe.SLOStatus{Target: 99, Window: time.Hour, Budget: 50,
+		BurnRates: map[string]float64{"1h": 20, "5m": 30}, Alert: probe.BurnFast}
+
+	n.NotifyBurn(r)
+	assert.Equal(t, []string{"dummy probe Error Budget Fast Burn"}, titles)
+	assert.Equal(t, report.ToSLOText(r), messages[0])
+
+	n.NotifyFormat = report.Slack
+	assert.Equal(t, report.ToSLOSlack(r), n.FormatBurn(r))
+	n.NotifyFormat = report.Format(100)
+	assert.Equal(t, report.ToSLOText(r), n.FormatBurn(r))
+
+	// the structured payload does not render the burn alert without the burn function
+	n.NotifyFormatFunc = func(r probe.Result) string { return r.Name }
+	assert.Equal(t, report.ToSLOText(r), n.FormatBurn(r))
+	n.NotifyBurnFunc = func(r probe.Result) string { return r.SLO.Alert.String() }
+	n.NotifyBurn(r)
+	assert.Equal(t, "fast", messages[len(messages)-1])
+}
@@ -0,0 +1,58 @@
+package base
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/megaease/easeprobe/global"
+)
+
+// RedactURL returns the scheme and the host of the URL, the path and the query could contain the secrets
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "***"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// RedactError replaces the URL of the request error with the redacted one, so that the error could be logged
+func RedactError(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		ue.URL = RedactURL(ue.URL)
+	}
+	return err
+}
+
+// PostJSON posts the JSON message to the URL, the non-2xx response is treated as an error,
+// the URL of the request error is redacted because the webhook URL is the secret
+func (c *DefaultNotify) PostJSON(rawURL, message string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, rawURL, bytes.NewBufferString(message))
+	if err != nil {
+		return nil, RedactError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", global.OrgProgVer)
+	req.Close = true
+
+	client := &http.Client{Timeout: c.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, RedactError(err)
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return buf, fmt.Errorf("error response from %s - code [%d] - msg [%s]", c.NotifyKind, resp.StatusCode, string(buf))
+	}
+	return buf, nil
+}
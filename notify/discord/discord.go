@@ -0,0 +1,40 @@
+// Package discord is the Discord notification package
+package discord
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// NotifyConfig is the Discord notification configuration
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	WebhookURL         string `yaml:"webhook" json:"webhook" jsonschema:"required,format=uri,title=Webhook URL,description=The Discord channel webhook URL"`
+}
+
+// Config configures the Discord notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "discord"
+	c.NotifyFormat = report.Discord
+	c.NotifySendFunc = c.SendDiscord
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+	if _, err := url.ParseRequestURI(c.WebhookURL); err != nil {
+		return fmt.Errorf("%s - invalid webhook URL: %v", c.LogTitle(), err)
+	}
+	// the webhook URL contains the secret, it's not logged
+	log.Debugf("%s - configured", c.LogTitle())
+	return nil
+}
+
+// SendDiscord sends the message to the Discord webhook
+func (c *NotifyConfig) SendDiscord(title, msg string) error {
+	_, err := c.PostJSON(c.WebhookURL, msg)
+	return err
+}
@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.RoundTripTime = 150 * time.Millisecond
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = "<timeout> & retry"
+	return *r
+}
+
+// payload is the Discord message which is checked by the tests
+type payload struct {
+	Username  string `json:"username"`
+	AvatarURL string `json:"avatar_url"`
+	Embeds    []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Color       int    `json:"color"`
+		Fields      []struct {
+			Name   string `json:"name"`
+			Value  string `json:"value"`
+			Inline bool   `json:"inline"`
+		} `json:"fields"`
+		Footer struct {
+			Text    string `json:"text"`
+			IconURL string `json:"icon_url"`
+		} `json:"footer"`
+		Timestamp string `json:"timestamp"`
+	} `json:"embeds"`
+}
+
+func checkPayload(t *testing.T, body []byte, color int) {
+	m := payload{}
+	assert.Nil(t, json.Unmarshal(body, &m))
+	assert.Equal(t, "EaseProbe", m.Username)
+	assert.Equal(t, "icon", m.AvatarURL)
+	assert.Equal(t, 1, len(m.Embeds))
+	e := m.Embeds[0]
+	assert.Contains(t, e.Title, "dummy probe")
+	assert.Equal(t, "```<timeout> & retry```", e.Description)
+	assert.Equal(t, color, e.Color)
+	assert.Equal(t, 5, len(e.Fields))
+	assert.Equal(t, "Endpoint", e.Fields[0].Name)
+	assert.Equal(t, "http://example.com", e.Fields[0].Value)
+	assert.True(t, e.Fields[0].Inline)
+	assert.Equal(t, global.FooterString(), e.Footer.Text)
+	assert.Equal(t, "icon", e.Footer.IconURL)
+	_, err := time.Parse(time.RFC3339, e.Timestamp)
+	assert.Nil(t, err)
+}
+
+func TestDiscord(t *testing.T) {
+	ch := make(chan []byte, 10)
+	var code atomic.Int32
+	code.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		ch <- body
+		w.WriteHeader(int(code.Load()))
+	}))
+	defer server.Close()
+
+	global.InitEaseProbe("EaseProbe", "icon")
+	conf := &NotifyConfig{WebhookURL: server.URL}
+	conf.NotifyName = "dummy"
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "discord", conf.Kind())
+
+	r := newResult()
+	conf.Notify(r)
+	body := <-ch
+	assert.Equal(t, report.ToDiscord(r), string(body))
+	checkPayload(t, body, 0xE01E5A)
+
+	// the recovery is green
+	r.PreStatus, r.Status = probe.StatusDown, probe.StatusUp
+	conf.Notify(r)
+	checkPayload(t, <-ch, 0x36A64F)
+
+	code.Store(http.StatusBadRequest)
+	err = conf.SendDiscord("title", report.ToDiscord(r))
+	<-ch
+	assert.NotNil(t, err)
+
+	conf = &NotifyConfig{WebhookURL: "not a url"}
+	conf.NotifyName = "dummy"
+	err = conf.Config(global.NotifySettings{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,321 @@
+// Package email is the email notification package
+package email
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// The security modes of the SMTP connection
+const (
+	SecurityAuto     = ""         // implicit TLS for port 465, otherwise STARTTLS if the server supports it
+	SecurityNone     = "none"     // plain text connection
+	SecuritySTARTTLS = "starttls" // STARTTLS is required
+	SecurityTLS      = "tls"      // implicit TLS
+)
+
+// The SMTP authentication mechanisms
+const (
+	AuthAuto  = ""
+	AuthPlain = "plain"
+	AuthLogin = "login"
+)
+
+// NotifyConfig is the email notification configuration
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	Server             string `yaml:"server" json:"server" jsonschema:"required,format=hostname,title=SMTP Server,description=SMTP server with port,example=\"smtp.example.com:465\""`
+	User               string `yaml:"username,omitempty" json:"username,omitempty" jsonschema:"title=SMTP Username,description=SMTP username"`
+	Pass               string `yaml:"password,omitempty" json:"password,omitempty" jsonschema:"title=SMTP Password,description=SMTP password"`
+	To                 string `yaml:"to" json:"to" jsonschema:"required,title=To,description=Email address list to send the message to (separated by ';' or ',')"`
+	From               string `yaml:"from,omitempty" json:"from,omitempty" jsonschema:"title=From,description=Email address to send the message from (default is the username)"`
+	Security           string `yaml:"security,omitempty" json:"security,omitempty" jsonschema:"enum=none,enum=starttls,enum=tls,title=Security,description=the security mode of the SMTP connection (default is starttls if the server supports it or tls for port 465)"`
+	Auth               string `yaml:"auth,omitempty" json:"auth,omitempty" jsonschema:"enum=plain,enum=login,title=Auth,description=the SMTP authentication mechanism (default is auto negotiated)"`
+	global.TLS         `yaml:",inline"`
+
+	host string   `yaml:"-" json:"-"`
+	to   []string `yaml:"-" json:"-"`
+}
+
+// Config configures the email notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "email"
+	c.NotifyFormat = report.HTML
+	c.NotifyFormatFunc = c.Render
+	c.NotifyDigestFunc = c.RenderDigest
+	c.NotifyBurnFunc = c.RenderBurn
+	c.NotifyStatFunc = c.RenderStat
+	c.NotifySendFunc = c.SendMail
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(c.Server)
+	if err != nil {
+		return fmt.Errorf("%s - invalid server [%s]: %v", c.LogTitle(), c.Server, err)
+	}
+	c.host = host
+
+	c.to = splitAddress(c.To)
+	if len(c.to) == 0 {
+		return fmt.Errorf("%s - no recipient is configured", c.LogTitle())
+	}
+	if strings.TrimSpace(c.From) == "" {
+		c.From = c.User
+	}
+	if strings.TrimSpace(c.From) == "" {
+		return fmt.Errorf("%s - no sender is configured", c.LogTitle())
+	}
+
+	c.Security = strings.ToLower(strings.TrimSpace(c.Security))
+	switch c.Security {
+	case SecurityAuto, SecurityNone, SecuritySTARTTLS, SecurityTLS:
+	default:
+		return fmt.Errorf("%s - invalid security mode [%s]", c.LogTitle(), c.Security)
+	}
+	if c.Security == SecurityAuto && strings.HasSuffix(c.Server, ":465") {
+		c.Security = SecurityTLS
+	}
+
+	c.Auth = strings.ToLower(strings.TrimSpace(c.Auth))
+	switch c.Auth {
+	case AuthAuto, AuthPlain, AuthLogin:
+	default:
+		return fmt.Errorf("%s - invalid auth mechanism [%s]", c.LogTitle(), c.Auth)
+	}
+
+	if _, err := c.TLS.Config(); err != nil {
+		return fmt.Errorf("%s - TLS configuration error: %v", c.LogTitle(), err)
+	}
+
+	log.Debugf("%s - configuration: server[%s], to%v, security[%s], auth[%s]",
+		c.LogTitle(), c.Server, c.to, c.Security, c.Auth)
+	return nil
+}
+
+func splitAddress(s string) []string {
+	var list []string
+	for _, addr := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			list = append(list, addr)
+		}
+	}
+	return list
+}
+
+// Render renders the result into the multipart body with both plain text and HTML
+// the HTML part could be replaced by the body of the message template
+func (c *NotifyConfig) Render(r probe.Result) string {
+	text := report.ToText(r) + "\n\n" + global.FooterString()
+	html, ok := c.RenderBody(r)
+	if !ok {
+		html = report.ToHTML(r)
+	}
+	return multipartBody(text, html)
+}
+
+// RenderDigest renders the results into the multipart body with both plain text and HTML
+func (c *NotifyConfig) RenderDigest(results []probe.Result) string {
+	text := report.ToDigestText(results) + "\n\n" + global.FooterString()
+	return multipartBody(text, report.ToDigestHTML(results))
+}
+
+// RenderBurn renders the error budget burn rate alert into the multipart body with both plain text and HTML
+func (c *NotifyConfig) RenderBurn(r probe.Result) string {
+	text := report.ToSLOText(r) + "\n\n" + global.FooterString()
+	return multipartBody(text, report.ToSLOHTML(r))
+}
+
+// RenderStat renders the SLA report into the multipart body with both plain text and HTML
+func (c *NotifyConfig) RenderStat(probers []probe.Prober) string {
+	text := report.ToSLAText(probers) + "\n\n" + global.FooterString()
+	return multipartBody(text, report.ToSLAHTML(probers))
+}
+
+// multipartBody returns the MIME entity which includes its own Content-Type header
+func multipartBody(text, html string) string {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	}
+	for _, p := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", p.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			log.Errorf("[email] failed to create the mail part: %v", err)
+			continue
+		}
+		qp := quotedprintable.NewWriter(pw)
+		qp.Write([]byte(p.content))
+		qp.Close()
+	}
+	w.Close()
+	return buf.String()
+}
+
+// message returns the whole mail message with the headers
+func (c *NotifyConfig) message(title, body string) []byte {
+	header := "From: " + c.From + "\r\n" +
+		"To: " + strings.Join(c.to, ", ") + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("UTF-8", title) + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n" +
+		"MIME-Version: 1.0\r\n"
+	if !strings.HasPrefix(body, "Content-Type:") {
+		body = "Content-Type: text/plain; charset=UTF-8\r\n\r\n" + body
+	}
+	return []byte(header + body)
+}
+
+func (c *NotifyConfig) tlsConfig() *tls.Config {
+	t, _ := c.TLS.Config()
+	if t == nil {
+		t = &tls.Config{}
+	}
+	if t.ServerName == "" {
+		t.ServerName = c.host
+	}
+	return t
+}
+
+func (c *NotifyConfig) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: c.Timeout}
+	if c.Security == SecurityTLS {
+		return tls.DialWithDialer(dialer, "tcp", c.Server, c.tlsConfig())
+	}
+	return dialer.Dial("tcp", c.Server)
+}
+
+// auth returns the SMTP authentication which is negotiated with the server
+func (c *NotifyConfig) auth(client *smtp.Client) (smtp.Auth, error) {
+	mechanism := c.Auth
+	if mechanism == AuthAuto {
+		ok, params := client.Extension("AUTH")
+		if !ok {
+			return nil, fmt.Errorf("the SMTP server does not support authentication")
+		}
+		mechanism = AuthLogin
+		for _, m := range strings.Fields(strings.ToLower(params)) {
+			if m == AuthPlain {
+				mechanism = AuthPlain
+				break
+			}
+		}
+	}
+	if mechanism == AuthLogin {
+		return &loginAuth{username: c.User, password: c.Pass, host: c.host}, nil
+	}
+	return smtp.PlainAuth("", c.User, c.Pass, c.host), nil
+}
+
+// SendMail sends the mail to all of the recipients
+func (c *NotifyConfig) SendMail(title, message string) error {
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	if c.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.Security == SecurityAuto || c.Security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(c.tlsConfig()); err != nil {
+				return err
+			}
+		} else if c.Security == SecuritySTARTTLS {
+			return &global.ErrNoRetry{Message: "the SMTP server does not support STARTTLS"}
+		}
+	}
+
+	if len(c.User) > 0 {
+		a, err := c.auth(client)
+		if err != nil {
+			return &global.ErrNoRetry{Message: err.Error()}
+		}
+		if err := client.Auth(a); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(c.From); err != nil {
+		return err
+	}
+	for _, to := range c.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(c.message(title, message)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// loginAuth implements the AUTH LOGIN mechanism which is not supported by net/smtp
+type loginAuth struct {
+	username, password, host string
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// do not send the password over an unencrypted connection, the same as smtp.PlainAuth
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, fmt.Errorf("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, fmt.Errorf("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+}
@@ -0,0 +1,439 @@
+package email
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// mail is the mail received by the SMTP stand-in
+type mail struct {
+	auth string
+	tls  bool
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is an in-process SMTP stand-in
+type smtpServer struct {
+	listener net.Listener
+	tlsConf  *tls.Config
+	implicit bool
+	startTLS bool
+	mutex    sync.Mutex
+	mails    []mail
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func newSMTPServer(t *testing.T, implicit, startTLS bool) *smtpServer {
+	s := &smtpServer{
+		tlsConf:  &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}},
+		implicit: implicit,
+		startTLS: startTLS,
+	}
+	var err error
+	if implicit {
+		s.listener, err = tls.Listen("tcp", "127.0.0.1:0", s.tlsConf)
+	} else {
+		s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	assert.Nil(t, err)
+	go s.serve()
+	return s
+}
+
+func (s *smtpServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *smtpServer) Close() {
+	s.listener.Close()
+}
+
+func (s *smtpServer) Mails() []mail {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.mails
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	readLine := func() string {
+		line, _ := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n")
+	}
+
+	m := mail{tls: s.implicit}
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line := readLine()
+		cmd := strings.ToUpper(line)
+		switch {
+		case line == "":
+			return
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-localhost")
+			if s.startTLS && !m.tls {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN LOGIN")
+		case cmd == "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConf)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+			m.tls = true
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			buf, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(line[len("AUTH PLAIN"):]))
+			m.auth = "plain:" + strings.ReplaceAll(string(buf), "\x00", "|")
+			reply("235 authenticated")
+		case strings.HasPrefix(cmd, "AUTH LOGIN"):
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+			user, _ := base64.StdEncoding.DecodeString(readLine())
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+			pass, _ := base64.StdEncoding.DecodeString(readLine())
+			m.auth = "login:" + string(user) + "|" + string(pass)
+			reply("235 authenticated")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			m.to = append(m.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for l := readLine(); l != "."; l = readLine() {
+				data.WriteString(l + "\n")
+			}
+			m.data = data.String()
+			s.mutex.Lock()
+			s.mails = append(s.mails, m)
+			s.mutex.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = "Error (http): timeout"
+	return *r
+}
+
+func newConf(server string) *NotifyConfig {
+	conf := &NotifyConfig{
+		Server: server,
+		User:   "user@example.com",
+		Pass:   "secret",
+		To:     "a@example.com; b@example.com, c@example.com",
+	}
+	conf.NotifyName = "dummy"
+	conf.Insecure = true
+	return conf
+}
+
+func checkMail(t *testing.T, m mail, r probe.Result) {
+	assert.Equal(t, "user@example.com", m.from)
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, m.to)
+	assert.Contains(t, m.data, "Subject: "+r.Title())
+	assert.Contains(t, m.data, "multipart/alternative")
+	assert.Contains(t, m.data, "text/plain")
+	assert.Contains(t, m.data, "text/html")
+	assert.Contains(t, m.data, "Error (http): timeout")
+	assert.Contains(t, m.data, global.FooterString())
+}
+
+func TestEmailSTARTTLS(t *testing.T) {
+	server := newSMTPServer(t, false, true)
+	defer server.Close()
+
+	conf := newConf(server.Addr())
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "email", conf.Kind())
+	assert.Equal(t, "user@example.com", conf.From)
+
+	r := newResult()
+	conf.Notify(r)
+	mails := server.Mails()
+	assert.Equal(t, 1, len(mails))
+	assert.True(t, mails[0].tls)
+	assert.Equal(t, "plain:|user@example.com|secret", mails[0].auth)
+	checkMail(t, mails[0], r)
+}
+
+func TestEmailImplicitTLSLogin(t *testing.T) {
+	server := newSMTPServer(t, true, false)
+	defer server.Close()
+
+	conf := newConf(server.Addr())
+	conf.Security = "TLS"
+	conf.Auth = "login"
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+
+	r := newResult()
+	err = conf.SendMail(r.Title(), conf.Render(r))
+	assert.Nil(t, err)
+	mails := server.Mails()
+	assert.Equal(t, 1, len(mails))
+	assert.True(t, mails[0].tls)
+	assert.Equal(t, "login:user@example.com|secret", mails[0].auth)
+	checkMail(t, mails[0], r)
+}
+
+func TestEmailPlainConnection(t *testing.T) {
+	server := newSMTPServer(t, false, false)
+	defer server.Close()
+
+	// STARTTLS is required, but not supported by the server
+	conf := newConf(server.Addr())
+	conf.Security = SecuritySTARTTLS
+	conf.Retry = global.Retry{Times: 1, Interval: time.Millisecond}
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+	err := conf.SendMail("title", "message")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "STARTTLS")
+
+	// no authentication with plain connection
+	conf = newConf(server.Addr())
+	conf.Security = SecurityNone
+	conf.User = ""
+	conf.From = "probe@example.com"
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+	err = conf.SendMail("title", "plain message")
+	assert.Nil(t, err)
+	mails := server.Mails()
+	assert.Equal(t, 1, len(mails))
+	assert.False(t, mails[0].tls)
+	assert.Equal(t, "", mails[0].auth)
+	assert.Equal(t, "probe@example.com", mails[0].from)
+	assert.Contains(t, mails[0].data, "plain message")
+}
+
+func TestEmailConfigError(t *testing.T) {
+	conf := newConf("smtp.example.com")
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = newConf("smtp.example.com:25")
+	conf.To = " ; "
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = newConf("smtp.example.com:25")
+	conf.User = ""
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = newConf("smtp.example.com:25")
+	conf.Security = "ssl"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = newConf("smtp.example.com:25")
+	conf.Auth = "cram-md5"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = newConf("smtp.example.com:25")
+	conf.CA = "/no/such/ca.crt"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = newConf("smtp.example.com:465")
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+	assert.Equal(t, SecurityTLS, conf.Security)
+
+	conf = newConf("127.0.0.1:1")
+	conf.Timeout = time.Second
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+	assert.NotNil(t, conf.SendMail("title", "message"))
+}
+
+func TestLoginAuth(t *testing.T) {
+	a := &loginAuth{username: "user", password: "pass", host: "smtp.example.com"}
+	_, _, err := a.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: false})
+	assert.NotNil(t, err)
+	_, _, err = a.Start(&smtp.ServerInfo{Name: "other.example.com", TLS: true})
+	assert.NotNil(t, err)
+	proto, _, err := a.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "LOGIN", proto)
+
+	buf, err := a.Next([]byte("Username:"), true)
+	assert.Nil(t, err)
+	assert.Equal(t, "user", string(buf))
+	buf, err = a.Next([]byte("Password:"), true)
+	assert.Nil(t, err)
+	assert.Equal(t, "pass", string(buf))
+	_, err = a.Next([]byte("Other:"), true)
+	assert.NotNil(t, err)
+	buf, err = a.Next(nil, false)
+	assert.Nil(t, err)
+	assert.Nil(t, buf)
+}
+
+func TestEmailTemplate(t *testing.T) {
+	server := newSMTPServer(t, false, true)
+	defer server.Close()
+
+	conf := newConf(server.Addr())
+	conf.MessageTemplate = report.Template{
+		Title: "Alert: {{.Name}}",
+		Body:  "<p>custom body for {{.Name}}</p>",
+	}
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+
+	conf.Notify(newResult())
+	mails := server.Mails()
+	assert.Equal(t, 1, len(mails))
+	assert.Contains(t, mails[0].data, "Subject: Alert: dummy probe")
+	assert.Contains(t, mails[0].data, "<p>custom body for dummy probe</p>")
+	assert.Contains(t, mails[0].data, "text/plain")
+}
+
+func TestEmailDigest(t *testing.T) {
+	server := newSMTPServer(t, false, true)
+	defer server.Close()
+
+	conf := newConf(server.Addr())
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+
+	r1, r2 := newResult(), newResult()
+	r2.Name = "another probe"
+	conf.NotifyDigest([]probe.Result{r1, r2})
+	mails := server.Mails()
+	assert.Equal(t, 1, len(mails))
+	assert.Contains(t, mails[0].data, "Subject: 2 Probes Failure")
+	assert.Contains(t, mails[0].data, "text/html")
+	assert.Contains(t, mails[0].data, "another probe")
+}
+
+func TestEmailBurn(t *testing.T) {
+	server := newSMTPServer(t, false, true)
+	defer server.Close()
+
+	conf := newConf(server.Addr())
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+
+	r := newResult()
+	r.SLO = &probe.SLOStatus{Target: 99.9, Budget: 50, Alert: probe.BurnFast}
+	conf.NotifyBurn(r)
+	mails := server.Mails()
+	assert.Equal(t, 1, len(mails))
+	assert.Contains(t, mails[0].data, "Subject: dummy probe Error Budget Fast Burn")
+	assert.Contains(t, mails[0].data, "text/html")
+	assert.Contains(t, mails[0].data, "Error Budget")
+}
+
+type dummyProber struct {
+	result probe.Result
+}
+
+func (d *dummyProber) LabelMap() prometheus.Labels          { return nil }
+func (d *dummyProber) SetLabelMap(labels prometheus.Labels) {}
+func (d *dummyProber) Kind() string                         { return "http" }
+func (d *dummyProber) Name() string                         { return d.result.Name }
+func (d *dummyProber) Channels() []string                   { return nil }
+func (d *dummyProber) Timeout() time.Duration               { return time.Second }
+func (d *dummyProber) Interval() time.Duration              { return time.Minute }
+func (d *dummyProber) Result() *probe.Result                { return &d.result }
+func (d *dummyProber) Config(global.ProbeSettings) error    { return nil }
+func (d *dummyProber) Probe() probe.Result                  { return d.result }
+
+func TestEmailStat(t *testing.T) {
+	server := newSMTPServer(t, false, true)
+	defer server.Close()
+
+	conf := newConf(server.Addr())
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+
+	conf.NotifyStat([]probe.Prober{&dummyProber{result: newResult()}})
+	mails := server.Mails()
+	assert.Equal(t, 1, len(mails))
+
+	// the HTML report is not sent as the plain text
+	msg, err := netmail.ReadMessage(strings.NewReader(mails[0].data))
+	assert.Nil(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	types := []string{}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err != nil {
+			break
+		}
+		mediaType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		assert.Nil(t, err)
+		types = append(types, mediaType)
+		body, _ := io.ReadAll(part)
+		if mediaType == "text/html" {
+			assert.Contains(t, string(body), "<table")
+		} else {
+			assert.NotContains(t, string(body), "<table")
+			assert.Contains(t, string(body), "dummy probe")
+		}
+	}
+	assert.Equal(t, []string{"text/plain", "text/html"}, types)
+}
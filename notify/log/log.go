@@ -0,0 +1,234 @@
+// Package log is the log notification package, it writes the notifications
+// as JSON lines into a rotating file or the local syslog.
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// The destination types of the log notification
+const (
+	TypeFile   = "file"
+	TypeSyslog = "syslog"
+)
+
+// NotifyConfig is the log notification configuration
+// The `level` is the threshold of the notifications: the failures are written
+// with the error level and the others with the info level.
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	global.Log         `yaml:",inline"`
+	Type               string `yaml:"type,omitempty" json:"type,omitempty" jsonschema:"enum=file,enum=syslog,title=Log Type,description=write the notification into the file or the local syslog,default=file"`
+	Tag                string `yaml:"tag,omitempty" json:"tag,omitempty" jsonschema:"title=Syslog Tag,description=the tag of the syslog message (default is the program name)"`
+
+	mutex     sync.Mutex `yaml:"-" json:"-"`
+	rotateSet bool       `yaml:"-" json:"-"` // the self_rotate is set explicitly
+}
+
+// UnmarshalYAML unmarshals the configuration, and records whether the self rotation is set explicitly
+func (c *NotifyConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain NotifyConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	var keys map[string]interface{}
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+	_, c.rotateSet = keys["self_rotate"]
+	return nil
+}
+
+// Record is the JSON line of the notification
+type Record struct {
+	Time      string           `json:"time"`
+	Notify    string           `json:"notify"`
+	Title     string           `json:"title"`
+	Name      string           `json:"name"`
+	Endpoint  string           `json:"endpoint"`
+	Status    probe.Status     `json:"status"`
+	PreStatus probe.Status     `json:"prestatus"`
+	RTT       int64            `json:"rtt"`
+	SLA       float64          `json:"sla"`
+	Windows   probe.SLAWindows `json:"windows,omitempty"`
+	SLO       *probe.SLOStatus `json:"slo,omitempty"`
+	Message   string           `json:"message"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+// NewRecord create the record of the result
+func NewRecord(notifyName string, r probe.Result) Record {
+	return Record{
+		Time:      r.StartTime.UTC().Format(time.RFC3339Nano),
+		Notify:    notifyName,
+		Title:     r.Title(),
+		Name:      r.Name,
+		Endpoint:  r.Endpoint,
+		Status:    r.Status,
+		PreStatus: r.PreStatus,
+		RTT:       r.RoundTripTime.Milliseconds(),
+		SLA:       r.SLAPercent(),
+		Windows:   r.Windows,
+		SLO:       r.SLO,
+		Message:   r.Message,
+		Timestamp: r.StartTimestamp,
+	}
+}
+
+// lineFormatter writes the pre-rendered message as a line
+type lineFormatter struct{}
+
+func (f *lineFormatter) Format(entry *log.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
+
+// Config configures the log notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "log"
+	c.NotifyFormat = report.JSON
+	c.NotifyFormatFunc = c.Render
+	c.NotifyBurnFunc = c.RenderBurn
+	c.NotifySendFunc = c.WriteLog
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+
+	c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+	if c.Type == "" {
+		c.Type = TypeFile
+	}
+
+	// the file is rotated by lumberjack unless the self rotation is disabled explicitly
+	if !c.rotateSet {
+		c.SelfRotate = true
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Formatter = &lineFormatter{}
+	switch c.Type {
+	case TypeFile:
+		// use a private logger, so the rotation never touches the system-wide log
+		c.InitLog(log.New())
+		log.Infof("%s - writing the notifications into %s", c.LogTitle(), c.destination())
+	case TypeSyslog:
+		if c.Tag == "" {
+			c.Tag = global.GetEaseProbe().Name
+		}
+		if err := c.initSyslog(); err != nil {
+			return fmt.Errorf("%s - cannot connect to the syslog: %v", c.LogTitle(), err)
+		}
+		log.Infof("%s - writing the notifications into the syslog with tag [%s]", c.LogTitle(), c.Tag)
+	default:
+		return fmt.Errorf("%s - invalid type [%s]", c.LogTitle(), c.Type)
+	}
+	return nil
+}
+
+func (c *NotifyConfig) destination() string {
+	if c.File == "" {
+		return "the standard output"
+	}
+	return c.File
+}
+
+// Render renders the result into the JSON line
+func (c *NotifyConfig) Render(r probe.Result) string {
+	return c.render(r, NewRecord(c.NotifyName, r))
+}
+
+// RenderBurn renders the error budget burn rate alert into the JSON line,
+// the title and the message describe the alert instead of the status
+func (c *NotifyConfig) RenderBurn(r probe.Result) string {
+	rec := NewRecord(c.NotifyName, r)
+	rec.Title = report.SLOTitle(r)
+	rec.Message = report.SLOMessage(r)
+	return c.render(r, rec)
+}
+
+func (c *NotifyConfig) render(r probe.Result, rec Record) string {
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		log.Errorf("%s - failed to render the record: %v", c.LogTitle(), err)
+		return report.ToJSON(r)
+	}
+	return string(buf)
+}
+
+// level returns the log level of the result, the failures are logged as error
+func level(r probe.Result) log.Level {
+	if r.Status != probe.StatusUp {
+		return log.ErrorLevel
+	}
+	return log.InfoLevel
+}
+
+// Notify writes the result with the level of its status
+func (c *NotifyConfig) Notify(r probe.Result) {
+	c.write(level(r), c.RenderTitle(r), c.FormatResult(r), "Notification")
+}
+
+// NotifyBurn writes the error budget burn rate alert with the level of the result status
+func (c *NotifyConfig) NotifyBurn(r probe.Result) {
+	c.write(level(r), report.SLOTitle(r), c.FormatBurn(r), "SLO")
+}
+
+// NotifyDigest writes the results one by one, so that every line has the level of its status
+func (c *NotifyConfig) NotifyDigest(results []probe.Result) {
+	for _, r := range results {
+		c.Notify(r)
+	}
+}
+
+func (c *NotifyConfig) write(lvl log.Level, title, msg, tag string) {
+	send := func(title, msg string) error { return c.writeLog(lvl, msg) }
+	if err := c.SendFuncWithRetry(send, title, msg, tag); err != nil {
+		log.Errorf("%s - %v", c.LogTitle(), err)
+	}
+}
+
+// WriteLog writes the JSON line with the info level, e.g. the SLA report
+func (c *NotifyConfig) WriteLog(title, msg string) error {
+	return c.writeLog(log.InfoLevel, msg)
+}
+
+func (c *NotifyConfig) writeLog(lvl log.Level, msg string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.Logger == nil {
+		return &global.ErrNoRetry{Message: "the log is not configured"}
+	}
+	c.Logger.Log(lvl, msg)
+	return nil
+}
+
+// Rotate rotates the log file, it is called when receiving the SIGHUP
+func (c *NotifyConfig) Rotate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.Type != TypeFile {
+		return
+	}
+	c.Log.Rotate()
+}
+
+// Close closes the log file or the syslog
+func (c *NotifyConfig) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.Type == TypeSyslog {
+		c.closeSyslog()
+		return
+	}
+	c.Log.Close()
+}
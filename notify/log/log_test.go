@@ -0,0 +1,153 @@
+package log
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func newResult(status probe.Status) probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.RoundTripTime = 150 * time.Millisecond
+	r.PreStatus = probe.StatusUp
+	r.Status = status
+	r.Message = "dummy message"
+	return *r
+}
+
+func readLines(t *testing.T, file string) []string {
+	buf, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	return strings.Split(strings.TrimSpace(string(buf)), "\n")
+}
+
+func TestLogFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "alerts", "alert.log")
+	conf := &NotifyConfig{}
+	conf.NotifyName = "dummy"
+	conf.File = file
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "log", conf.Kind())
+	assert.Equal(t, TypeFile, conf.Type)
+	assert.True(t, conf.SelfRotate)
+
+	down := newResult(probe.StatusDown)
+	down.Windows = probe.SLAWindows{"24h": 99.9}
+	conf.Notify(down)
+	conf.Notify(newResult(probe.StatusUp))
+
+	lines := readLines(t, file)
+	assert.Equal(t, 2, len(lines))
+	rec := Record{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &rec))
+	assert.Equal(t, "dummy", rec.Notify)
+	assert.Equal(t, down.Title(), rec.Title)
+	assert.Equal(t, "dummy probe", rec.Name)
+	assert.Equal(t, probe.StatusDown, rec.Status)
+	assert.Equal(t, probe.StatusUp, rec.PreStatus)
+	assert.Equal(t, int64(150), rec.RTT)
+	assert.Equal(t, "dummy message", rec.Message)
+	assert.Equal(t, probe.SLAWindows{"24h": 99.9}, rec.Windows)
+	assert.Nil(t, rec.SLO)
+
+	// the burn rate alert
+	up := newResult(probe.StatusUp)
+	up.SLO = &probe.SLOStatus{Target: 99, Alert: probe.BurnSlow}
+	rec = Record{}
+	assert.Nil(t, json.Unmarshal([]byte(conf.RenderBurn(up)), &rec))
+	assert.Equal(t, "dummy probe Error Budget Slow Burn", rec.Title)
+	assert.Equal(t, report.SLOMessage(up), rec.Message)
+	assert.Equal(t, probe.BurnSlow, rec.SLO.Alert)
+
+	// the self rotation moves the current file to a backup
+	conf.Rotate()
+	conf.Notify(down)
+	assert.Equal(t, 1, len(readLines(t, file)))
+	backups, _ := filepath.Glob(filepath.Join(filepath.Dir(file), "alert-*.log*"))
+	assert.Equal(t, 1, len(backups))
+	conf.Close()
+}
+
+func TestLogFileExternalRotate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "alert.log")
+	conf := &NotifyConfig{}
+	assert.Nil(t, yaml.Unmarshal([]byte("name: dummy\nself_rotate: false\nfile: "+file), conf))
+	conf.Level = global.LogLevel(logrus.ErrorLevel)
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+	assert.False(t, conf.SelfRotate)
+
+	// only the failures are written with the error level
+	conf.Notify(newResult(probe.StatusUp))
+	conf.Notify(newResult(probe.StatusDown))
+	assert.Equal(t, 1, len(readLines(t, file)))
+	conf.NotifyDigest([]probe.Result{newResult(probe.StatusUp), newResult(probe.StatusDown)})
+	assert.Equal(t, 2, len(readLines(t, file)))
+	conf.NotifyBurn(newResult(probe.StatusUp))
+	assert.Equal(t, 2, len(readLines(t, file)))
+	lines := readLines(t, file)
+	for _, line := range lines {
+		rec := Record{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &rec))
+		assert.Equal(t, probe.StatusDown, rec.Status)
+	}
+
+	// the file is moved by the external program (e.g. logrotate)
+	assert.Nil(t, os.Rename(file, file+".1"))
+	conf.Rotate()
+	conf.Notify(newResult(probe.StatusDown))
+	assert.Equal(t, 1, len(readLines(t, file)))
+	assert.Equal(t, 2, len(readLines(t, file+".1")))
+	conf.Close()
+}
+
+func TestLogConfig(t *testing.T) {
+	conf := &NotifyConfig{Type: "invalid"}
+	conf.NotifyName = "dummy"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = &NotifyConfig{}
+	assert.NotNil(t, conf.WriteLog("title", "msg"))
+	assert.Nil(t, yaml.Unmarshal([]byte("name: dummy"), conf))
+	assert.False(t, conf.rotateSet)
+	assert.NotNil(t, yaml.Unmarshal([]byte("name: [dummy"), conf))
+
+	str := `
+name: dummy
+file: /tmp/alert.log
+self_rotate: true
+size: 10
+type: file
+`
+	conf = &NotifyConfig{}
+	assert.Nil(t, yaml.Unmarshal([]byte(str), conf))
+	assert.Equal(t, "dummy", conf.NotifyName)
+	assert.Equal(t, "/tmp/alert.log", conf.File)
+	assert.Equal(t, 10, conf.MaxSize)
+	assert.True(t, conf.SelfRotate)
+	assert.True(t, conf.rotateSet)
+}
+
+func TestSyslog(t *testing.T) {
+	conf := &NotifyConfig{Type: TypeSyslog}
+	conf.NotifyName = "dummy"
+	if err := conf.Config(global.NotifySettings{}); err != nil {
+		t.Skipf("syslog is not available: %v", err)
+	}
+	assert.Equal(t, global.GetEaseProbe().Name, conf.Tag)
+	conf.Notify(newResult(probe.StatusDown))
+	conf.Rotate()
+	conf.Close()
+}
@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package log
+
+import (
+	"io"
+	"log/syslog"
+
+	log "github.com/sirupsen/logrus"
+	logrus_syslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// initSyslog sends the notifications to the local syslog
+func (c *NotifyConfig) initSyslog() error {
+	hook, err := logrus_syslog.NewSyslogHook("", "", syslog.LOG_INFO|syslog.LOG_DAEMON, c.Tag)
+	if err != nil {
+		return err
+	}
+	c.CheckDefault()
+	c.Logger = log.New()
+	c.Logger.SetOutput(io.Discard)
+	c.Logger.SetLevel(c.Level.GetLevel())
+	c.Logger.SetFormatter(c.Formatter)
+	c.Logger.AddHook(hook)
+	c.Writer = hook.Writer
+	return nil
+}
+
+func (c *NotifyConfig) closeSyslog() {
+	if w, ok := c.Writer.(*syslog.Writer); ok {
+		w.Close()
+	}
+}
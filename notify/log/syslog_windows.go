@@ -0,0 +1,13 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package log
+
+import "fmt"
+
+// initSyslog returns an error because the syslog is not supported on this platform
+func (c *NotifyConfig) initSyslog() error {
+	return fmt.Errorf("syslog is not supported on this platform")
+}
+
+func (c *NotifyConfig) closeSyslog() {}
@@ -0,0 +1,53 @@
+// Package notify contains the notify implementation.
+package notify
+
+import (
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/discord"
+	"github.com/megaease/easeprobe/notify/email"
+	"github.com/megaease/easeprobe/notify/log"
+	"github.com/megaease/easeprobe/notify/shell"
+	"github.com/megaease/easeprobe/notify/slack"
+	"github.com/megaease/easeprobe/notify/teams"
+	"github.com/megaease/easeprobe/notify/telegram"
+	"github.com/megaease/easeprobe/notify/webhook"
+	"github.com/megaease/easeprobe/probe"
+)
+
+// Config is the notify configuration
+// Each kind of notifier is a slice field, the configuration manager
+// will collect all of the elements which implement the Notify interface
+type Config struct {
+	Email    []email.NotifyConfig    `yaml:"email,omitempty" json:"email,omitempty" jsonschema:"title=Email Notification,description=Email Notification Configuration"`
+	Webhook  []webhook.NotifyConfig  `yaml:"webhook,omitempty" json:"webhook,omitempty" jsonschema:"title=Webhook Notification,description=Webhook Notification Configuration"`
+	Slack    []slack.NotifyConfig    `yaml:"slack,omitempty" json:"slack,omitempty" jsonschema:"title=Slack Notification,description=Slack Notification Configuration"`
+	Discord  []discord.NotifyConfig  `yaml:"discord,omitempty" json:"discord,omitempty" jsonschema:"title=Discord Notification,description=Discord Notification Configuration"`
+	Teams    []teams.NotifyConfig    `yaml:"teams,omitempty" json:"teams,omitempty" jsonschema:"title=Microsoft Teams Notification,description=Microsoft Teams Notification Configuration"`
+	Telegram []telegram.NotifyConfig `yaml:"telegram,omitempty" json:"telegram,omitempty" jsonschema:"title=Telegram Notification,description=Telegram Notification Configuration"`
+	Shell    []shell.NotifyConfig    `yaml:"shell,omitempty" json:"shell,omitempty" jsonschema:"title=Shell Notification,description=Shell Command Notification Configuration"`
+	Log      []log.NotifyConfig      `yaml:"log,omitempty" json:"log,omitempty" jsonschema:"title=Log Notification,description=Log File or Syslog Notification Configuration"`
+}
+
+// Notify is the configuration of the Notify
+type Notify interface {
+	Kind() string
+	Name() string
+	Channels() []string
+	Config(global.NotifySettings) error
+	Notify(probe.Result)
+}
+
+// Digest is the notifier which could send the results in one digest message
+type Digest interface {
+	NotifyDigest([]probe.Result)
+}
+
+// Stat is the notifier which could send the SLA report of the probers
+type Stat interface {
+	NotifyStat([]probe.Prober)
+}
+
+// Burn is the notifier which could send the error budget burn rate alert of the probe
+type Burn interface {
+	NotifyBurn(probe.Result)
+}
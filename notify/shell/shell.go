@@ -0,0 +1,129 @@
+// Package shell is the shell command notification package
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// NotifyConfig is the shell command notification configuration
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	Command            string   `yaml:"cmd" json:"cmd" jsonschema:"required,title=Command,description=The executable to run when the notification fires"`
+	Args               []string `yaml:"args,omitempty" json:"args,omitempty" jsonschema:"title=Arguments,description=The arguments of the command"`
+	Env                []string `yaml:"env,omitempty" json:"env,omitempty" jsonschema:"title=Environment,description=The extra environment variables of the command,example=[\"KEY=value\"]"`
+	CleanEnv           bool     `yaml:"clean_env,omitempty" json:"clean_env,omitempty" jsonschema:"title=Clean Environment,description=Do not inherit the environment variables of EaseProbe"`
+}
+
+// Config configures the shell command notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "shell"
+	c.NotifyFormat = report.JSON
+	c.NotifyFormatFunc = func(r probe.Result) string { return r.DebugJSON() }
+	c.NotifyBurnFunc = c.NotifyFormatFunc
+	c.NotifySendFunc = c.RunShell
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+	if strings.TrimSpace(c.Command) == "" {
+		return fmt.Errorf("%s - the command is required", c.LogTitle())
+	}
+	for _, e := range c.Env {
+		if !strings.Contains(e, "=") {
+			return fmt.Errorf("%s - invalid environment variable [%s], it should be KEY=value", c.LogTitle(), e)
+		}
+	}
+	log.Debugf("%s - configuration: cmd[%s], args%v", c.LogTitle(), c.Command, c.Args)
+	return nil
+}
+
+// Environ returns the environment variables of the result
+func Environ(notifyName, title string, r probe.Result) []string {
+	return []string{
+		"EASEPROBE_NOTIFY=" + notifyName,
+		"EASEPROBE_TITLE=" + title,
+		"EASEPROBE_NAME=" + r.Name,
+		"EASEPROBE_ENDPOINT=" + r.Endpoint,
+		"EASEPROBE_STATUS=" + r.Status.String(),
+		"EASEPROBE_PRESTATUS=" + r.PreStatus.String(),
+		"EASEPROBE_RTT=" + strconv.FormatInt(r.RoundTripTime.Milliseconds(), 10),
+		"EASEPROBE_SLA=" + fmt.Sprintf("%.2f", r.SLAPercent()),
+		"EASEPROBE_TIMESTAMP=" + strconv.FormatInt(r.StartTimestamp, 10),
+		"EASEPROBE_TIME=" + r.StartTime.UTC().Format(time.RFC3339),
+		"EASEPROBE_MESSAGE=" + r.Message,
+	}
+}
+
+// RunShell runs the command with the result in the environment variables and the JSON on the stdin
+func (c *NotifyConfig) RunShell(title, msg string) error {
+	var r probe.Result
+	if err := json.Unmarshal([]byte(msg), &r); err != nil {
+		return &global.ErrNoRetry{Message: fmt.Sprintf("invalid result JSON: %v", err)}
+	}
+	return c.run(msg, Environ(c.NotifyName, title, r))
+}
+
+// NotifyStat runs the command with the SLA report JSON on the stdin,
+// the report is not a probe result, so only the notification and the title are in the environment variables
+func (c *NotifyConfig) NotifyStat(probers []probe.Prober) {
+	title := report.SLATitle(probers)
+	message := c.FormatStat(probers)
+	send := func(title, msg string) error {
+		return c.run(msg, []string{"EASEPROBE_NOTIFY=" + c.NotifyName, "EASEPROBE_TITLE=" + title})
+	}
+	if err := c.SendFuncWithRetry(send, title, message, "SLA"); err != nil {
+		log.Errorf("%s - %v", c.LogTitle(), err)
+		return
+	}
+	if !c.Dry {
+		log.Infof("%s - Successfully sent the SLA report of %d probes", c.LogTitle(), len(probers))
+	}
+}
+
+// run runs the command with the message on the stdin and the extra environment variables
+func (c *NotifyConfig) run(msg string, env []string) error {
+	ctx := context.Background()
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	if !c.CleanEnv {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, c.Env...)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdin = strings.NewReader(msg)
+	// do not wait forever for the children which still hold the output after the command is killed
+	cmd.WaitDelay = time.Second
+
+	output, err := cmd.CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command [%s] timeout after %s - %s", c.Command, c.Timeout, out)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("command [%s] exit code [%d] - %s", c.Command, exitErr.ExitCode(), out)
+		}
+		return fmt.Errorf("command [%s] failed: %v", c.Command, err)
+	}
+	log.Debugf("%s - command [%s] output: %s", c.LogTitle(), c.Command, out)
+	return nil
+}
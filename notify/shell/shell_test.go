@@ -0,0 +1,144 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.RoundTripTime = 150 * time.Millisecond
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.StartTimestamp = 1640995200000
+	r.Message = "timeout"
+	return *r
+}
+
+func debugJSON() string {
+	r := newResult()
+	return r.DebugJSON()
+}
+
+func newNotify(t *testing.T, cmd string, args ...string) *NotifyConfig {
+	conf := &NotifyConfig{Command: cmd, Args: args}
+	conf.NotifyName = "dummy"
+	conf.Timeout = 5 * time.Second
+	conf.Retry = global.Retry{Times: 1, Interval: time.Millisecond}
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+	return conf
+}
+
+func TestShell(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	conf := newNotify(t, "/bin/sh", "-c", `env | grep ^EASEPROBE_ | sort > "$OUT"; echo >> "$OUT"; cat >> "$OUT"`)
+	conf.Env = []string{"OUT=" + out}
+	assert.Equal(t, "shell", conf.Kind())
+
+	r := newResult()
+	conf.Notify(r)
+
+	buf, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	str := string(buf)
+	assert.Contains(t, str, "EASEPROBE_NOTIFY=dummy\n")
+	assert.Contains(t, str, "EASEPROBE_TITLE="+r.Title()+"\n")
+	assert.Contains(t, str, "EASEPROBE_NAME=dummy probe\n")
+	assert.Contains(t, str, "EASEPROBE_ENDPOINT=http://example.com\n")
+	assert.Contains(t, str, "EASEPROBE_STATUS=down\n")
+	assert.Contains(t, str, "EASEPROBE_PRESTATUS=up\n")
+	assert.Contains(t, str, "EASEPROBE_RTT=150\n")
+	assert.Contains(t, str, "EASEPROBE_TIMESTAMP=1640995200000\n")
+	assert.Contains(t, str, "EASEPROBE_MESSAGE=timeout\n")
+	assert.True(t, strings.HasSuffix(str, r.DebugJSON()))
+}
+
+func TestShellFailure(t *testing.T) {
+	conf := newNotify(t, "/bin/sh", "-c", "echo oops; exit 3")
+	err := conf.RunShell("title", debugJSON())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exit code [3] - oops")
+
+	conf = newNotify(t, "/not/exist/command")
+	err = conf.RunShell("title", debugJSON())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "failed")
+
+	conf = newNotify(t, "/bin/sh", "-c", "sleep 5")
+	conf.Timeout = 100 * time.Millisecond
+	err = conf.RunShell("title", debugJSON())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+
+	err = conf.RunShell("title", "not json")
+	assert.IsType(t, &global.ErrNoRetry{}, err)
+}
+
+func TestShellRetry(t *testing.T) {
+	dir := t.TempDir()
+	cnt := filepath.Join(dir, "cnt")
+	conf := newNotify(t, "/bin/sh", "-c", `echo x >> "$CNT"; exit 1`)
+	conf.Env = []string{"CNT=" + cnt}
+	conf.Retry = global.Retry{Times: 3, Interval: time.Millisecond}
+	err := conf.SendWithRetry("title", debugJSON(), "Notification")
+	assert.NotNil(t, err)
+	buf, err := os.ReadFile(cnt)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, strings.Count(string(buf), "x"))
+}
+
+type dummyProber struct {
+	result probe.Result
+}
+
+func (d *dummyProber) LabelMap() prometheus.Labels          { return nil }
+func (d *dummyProber) SetLabelMap(labels prometheus.Labels) {}
+func (d *dummyProber) Kind() string                         { return "http" }
+func (d *dummyProber) Name() string                         { return d.result.Name }
+func (d *dummyProber) Channels() []string                   { return nil }
+func (d *dummyProber) Timeout() time.Duration               { return time.Second }
+func (d *dummyProber) Interval() time.Duration              { return time.Minute }
+func (d *dummyProber) Result() *probe.Result                { return &d.result }
+func (d *dummyProber) Config(global.ProbeSettings) error    { return nil }
+func (d *dummyProber) Probe() probe.Result                  { return d.result }
+
+func TestShellStat(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	conf := newNotify(t, "/bin/sh", "-c", `env | grep ^EASEPROBE_ | sort > "$OUT"; echo >> "$OUT"; cat >> "$OUT"`)
+	conf.Env = []string{"OUT=" + out}
+
+	// the SLA report is not a probe result, it's sent without being parsed
+	probers := []probe.Prober{&dummyProber{result: newResult()}}
+	conf.NotifyStat(probers)
+
+	buf, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	str := string(buf)
+	assert.Contains(t, str, "EASEPROBE_NOTIFY=dummy\n")
+	assert.Contains(t, str, "EASEPROBE_TITLE="+report.SLATitle(probers)+"\n")
+	assert.NotContains(t, str, "EASEPROBE_NAME=")
+	assert.True(t, strings.HasSuffix(str, report.ToSLAJSON(probers)))
+}
+
+func TestShellConfig(t *testing.T) {
+	conf := &NotifyConfig{}
+	conf.NotifyName = "dummy"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = &NotifyConfig{Command: "/bin/true", Env: []string{"INVALID"}}
+	conf.NotifyName = "dummy"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+}
@@ -0,0 +1,40 @@
+// Package slack is the Slack notification package
+package slack
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// NotifyConfig is the Slack notification configuration
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	WebhookURL         string `yaml:"webhook" json:"webhook" jsonschema:"required,format=uri,title=Webhook URL,description=The Slack incoming webhook URL"`
+}
+
+// Config configures the Slack notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "slack"
+	c.NotifyFormat = report.Slack
+	c.NotifySendFunc = c.SendSlack
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+	if _, err := url.ParseRequestURI(c.WebhookURL); err != nil {
+		return fmt.Errorf("%s - invalid webhook URL: %v", c.LogTitle(), err)
+	}
+	// the webhook URL contains the secret, it's not logged
+	log.Debugf("%s - configured", c.LogTitle())
+	return nil
+}
+
+// SendSlack sends the message to the Slack webhook
+func (c *NotifyConfig) SendSlack(title, msg string) error {
+	_, err := c.PostJSON(c.WebhookURL, msg)
+	return err
+}
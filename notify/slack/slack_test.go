@@ -0,0 +1,107 @@
+package slack
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.RoundTripTime = 150 * time.Millisecond
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = "<timeout> & retry"
+	return *r
+}
+
+// payload is the Slack message which is checked by the tests
+type payload struct {
+	Text        string `json:"text"`
+	Attachments []struct {
+		Color  string `json:"color"`
+		Blocks []struct {
+			Type     string `json:"type"`
+			Text     *text  `json:"text"`
+			Fields   []text `json:"fields"`
+			Elements []text `json:"elements"`
+		} `json:"blocks"`
+	} `json:"attachments"`
+}
+
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func checkPayload(t *testing.T, body []byte, color string) {
+	m := payload{}
+	assert.Nil(t, json.Unmarshal(body, &m))
+	assert.Equal(t, 1, len(m.Attachments))
+	assert.Equal(t, color, m.Attachments[0].Color)
+	blocks := m.Attachments[0].Blocks
+	assert.Equal(t, 4, len(blocks))
+	assert.Equal(t, "header", blocks[0].Type)
+	assert.Equal(t, "plain_text", blocks[0].Text.Type)
+	assert.Equal(t, m.Text, blocks[0].Text.Text)
+	assert.Contains(t, m.Text, "dummy probe")
+	assert.Equal(t, "section", blocks[1].Type)
+	assert.Equal(t, 5, len(blocks[1].Fields))
+	assert.Equal(t, "mrkdwn", blocks[1].Fields[0].Type)
+	assert.Equal(t, "*Endpoint*\nhttp://example.com", blocks[1].Fields[0].Text)
+	assert.Equal(t, "section", blocks[2].Type)
+	assert.Equal(t, "```&lt;timeout&gt; &amp; retry```", blocks[2].Text.Text)
+	assert.Equal(t, "context", blocks[3].Type)
+	assert.Equal(t, 1, len(blocks[3].Elements))
+}
+
+func TestSlack(t *testing.T) {
+	ch := make(chan []byte, 10)
+	var code atomic.Int32
+	code.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		ch <- body
+		w.WriteHeader(int(code.Load()))
+	}))
+	defer server.Close()
+
+	conf := &NotifyConfig{WebhookURL: server.URL}
+	conf.NotifyName = "dummy"
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "slack", conf.Kind())
+
+	r := newResult()
+	conf.Notify(r)
+	body := <-ch
+	assert.Equal(t, report.ToSlack(r), string(body))
+	checkPayload(t, body, "#E01E5A")
+
+	// the recovery is green
+	r.PreStatus, r.Status = probe.StatusDown, probe.StatusUp
+	conf.Notify(r)
+	checkPayload(t, <-ch, "#36A64F")
+
+	code.Store(http.StatusBadRequest)
+	err = conf.SendSlack("title", report.ToSlack(r))
+	<-ch
+	assert.NotNil(t, err)
+
+	conf = &NotifyConfig{WebhookURL: "not a url"}
+	conf.NotifyName = "dummy"
+	err = conf.Config(global.NotifySettings{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,40 @@
+// Package teams is the Microsoft Teams notification package
+package teams
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// NotifyConfig is the Microsoft Teams notification configuration
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	WebhookURL         string `yaml:"webhook" json:"webhook" jsonschema:"required,format=uri,title=Webhook URL,description=The Microsoft Teams incoming webhook URL"`
+}
+
+// Config configures the Microsoft Teams notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "teams"
+	c.NotifyFormat = report.Teams
+	c.NotifySendFunc = c.SendTeams
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+	if _, err := url.ParseRequestURI(c.WebhookURL); err != nil {
+		return fmt.Errorf("%s - invalid webhook URL: %v", c.LogTitle(), err)
+	}
+	// the webhook URL contains the secret, it's not logged
+	log.Debugf("%s - configured", c.LogTitle())
+	return nil
+}
+
+// SendTeams sends the message to the Microsoft Teams webhook
+func (c *NotifyConfig) SendTeams(title, msg string) error {
+	_, err := c.PostJSON(c.WebhookURL, msg)
+	return err
+}
@@ -0,0 +1,118 @@
+package teams
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.RoundTripTime = 150 * time.Millisecond
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = "<timeout> & retry"
+	return *r
+}
+
+// payload is the Microsoft Teams message which is checked by the tests
+type payload struct {
+	Type        string `json:"type"`
+	Attachments []struct {
+		ContentType string `json:"contentType"`
+		Content     struct {
+			Schema  string    `json:"$schema"`
+			Type    string    `json:"type"`
+			Version string    `json:"version"`
+			Body    []element `json:"body"`
+		} `json:"content"`
+	} `json:"attachments"`
+}
+
+type element struct {
+	Type  string    `json:"type"`
+	Text  string    `json:"text"`
+	Color string    `json:"color"`
+	Style string    `json:"style"`
+	Facts []fact    `json:"facts"`
+	Items []element `json:"items"`
+}
+
+type fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func checkPayload(t *testing.T, body []byte, style, color string) {
+	m := payload{}
+	assert.Nil(t, json.Unmarshal(body, &m))
+	assert.Equal(t, "message", m.Type)
+	assert.Equal(t, 1, len(m.Attachments))
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", m.Attachments[0].ContentType)
+	card := m.Attachments[0].Content
+	assert.Equal(t, "http://adaptivecards.io/schemas/adaptive-card.json", card.Schema)
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	assert.Equal(t, "1.4", card.Version)
+	assert.Equal(t, 4, len(card.Body))
+	assert.Equal(t, "Container", card.Body[0].Type)
+	assert.Equal(t, style, card.Body[0].Style)
+	assert.Equal(t, 1, len(card.Body[0].Items))
+	assert.Equal(t, color, card.Body[0].Items[0].Color)
+	assert.Contains(t, card.Body[0].Items[0].Text, "dummy probe")
+	assert.Equal(t, "FactSet", card.Body[1].Type)
+	assert.Equal(t, 5, len(card.Body[1].Facts))
+	assert.Equal(t, fact{"Endpoint", "http://example.com"}, card.Body[1].Facts[0])
+	assert.Equal(t, "TextBlock", card.Body[2].Type)
+	assert.Equal(t, "<timeout> & retry", card.Body[2].Text)
+}
+
+func TestTeams(t *testing.T) {
+	ch := make(chan []byte, 10)
+	var code atomic.Int32
+	code.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		ch <- body
+		w.WriteHeader(int(code.Load()))
+	}))
+	defer server.Close()
+
+	conf := &NotifyConfig{WebhookURL: server.URL}
+	conf.NotifyName = "dummy"
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "teams", conf.Kind())
+
+	r := newResult()
+	conf.Notify(r)
+	body := <-ch
+	assert.Equal(t, report.ToTeams(r), string(body))
+	checkPayload(t, body, "attention", "Attention")
+
+	// the recovery is green
+	r.PreStatus, r.Status = probe.StatusDown, probe.StatusUp
+	conf.Notify(r)
+	checkPayload(t, <-ch, "good", "Good")
+
+	code.Store(http.StatusBadRequest)
+	err = conf.SendTeams("title", report.ToTeams(r))
+	<-ch
+	assert.NotNil(t, err)
+
+	conf = &NotifyConfig{WebhookURL: "not a url"}
+	conf.NotifyName = "dummy"
+	err = conf.Config(global.NotifySettings{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,84 @@
+// Package telegram is the Telegram notification package
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultAPIURL is the default Telegram Bot API URL
+const DefaultAPIURL = "https://api.telegram.org"
+
+// NotifyConfig is the Telegram notification configuration
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	Token              string `yaml:"token" json:"token" jsonschema:"required,title=Bot Token,description=The Telegram bot token"`
+	ChatID             string `yaml:"chat_id" json:"chat_id" jsonschema:"required,title=Chat ID,description=The Telegram chat ID"`
+	APIURL             string `yaml:"api_url,omitempty" json:"api_url,omitempty" jsonschema:"format=uri,title=API URL,description=The Telegram Bot API URL,default=https://api.telegram.org"`
+}
+
+// Config configures the Telegram notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "telegram"
+	c.NotifyFormat = report.Telegram
+	c.NotifySendFunc = c.SendTelegram
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+	if strings.TrimSpace(c.Token) == "" || strings.TrimSpace(c.ChatID) == "" {
+		return fmt.Errorf("%s - the token and chat_id are required", c.LogTitle())
+	}
+	if strings.TrimSpace(c.APIURL) == "" {
+		c.APIURL = DefaultAPIURL
+	}
+	c.APIURL = strings.TrimRight(c.APIURL, "/")
+	if _, err := url.ParseRequestURI(c.APIURL); err != nil {
+		return fmt.Errorf("%s - invalid API URL: %v", c.LogTitle(), err)
+	}
+	log.Debugf("%s - configuration: chat_id[%s], api_url[%s]", c.LogTitle(), c.ChatID, c.APIURL)
+	return nil
+}
+
+type message struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+type response struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// SendTelegram sends the MarkdownV2 message to the Telegram chat
+func (c *NotifyConfig) SendTelegram(title, msg string) error {
+	body, err := json.Marshal(message{ChatID: c.ChatID, Text: msg, ParseMode: "MarkdownV2"})
+	if err != nil {
+		return err
+	}
+	buf, err := c.PostJSON(c.APIURL+"/bot"+c.Token+"/sendMessage", string(body))
+	if err != nil {
+		// the URL of the error contains the bot token
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			ue.URL = c.APIURL + "/bot***/sendMessage"
+		}
+		return err
+	}
+	var resp response
+	if err := json.Unmarshal(buf, &resp); err != nil {
+		return fmt.Errorf("invalid response from telegram: %v", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("error response from telegram - %s", resp.Description)
+	}
+	return nil
+}
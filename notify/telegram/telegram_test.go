@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.RoundTripTime = 150 * time.Millisecond
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = "<timeout> & retry"
+	return *r
+}
+
+func TestTelegram(t *testing.T) {
+	ch := make(chan message, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/botsecret/sendMessage", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		m := message{}
+		assert.Nil(t, json.Unmarshal(body, &m))
+		ch <- m
+		if m.ChatID != "12345" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	conf := &NotifyConfig{Token: "secret", ChatID: "12345", APIURL: server.URL + "/"}
+	conf.NotifyName = "dummy"
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "telegram", conf.Kind())
+	assert.Equal(t, server.URL, conf.APIURL)
+
+	r := newResult()
+	conf.Notify(r)
+	m := <-ch
+	assert.Equal(t, "12345", m.ChatID)
+	assert.Equal(t, "MarkdownV2", m.ParseMode)
+	assert.Equal(t, report.ToTelegram(r), m.Text)
+	assert.Contains(t, m.Text, "dummy probe")
+	assert.Contains(t, m.Text, `*Endpoint*: http://example\.com`)
+	assert.Contains(t, m.Text, "```\n<timeout> & retry\n```")
+
+	conf.ChatID = "unknown"
+	err = conf.SendTelegram("title", "text")
+	<-ch
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "chat not found")
+
+	// the bot token is not in the error
+	conf.APIURL = "http://127.0.0.1:1"
+	err = conf.SendTelegram("title", "text")
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+	assert.Contains(t, err.Error(), "/bot***/sendMessage")
+}
+
+func TestTelegramConfig(t *testing.T) {
+	conf := &NotifyConfig{}
+	conf.NotifyName = "dummy"
+	err := conf.Config(global.NotifySettings{})
+	assert.NotNil(t, err)
+
+	conf = &NotifyConfig{Token: "secret", ChatID: "12345"}
+	conf.NotifyName = "dummy"
+	err = conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultAPIURL, conf.APIURL)
+}
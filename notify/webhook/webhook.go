@@ -0,0 +1,201 @@
+// Package webhook is the webhook notification package
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/notify/base"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultTemplate is the default template of the webhook payload
+const DefaultTemplate = `{"name":{{json .Name}},"endpoint":{{json .Endpoint}},"status":{{json .Status}},` +
+	`"prestatus":{{json .PreStatus}},"title":{{json .Title}},"message":{{json .Message}},` +
+	`"rtt":{{.RTT.Milliseconds}},"sla":{{printf "%.2f" .SLA}},"windows":{{json .Windows}},"slo":{{json .SLO}},"time":{{json .Time}},"timestamp":{{.Timestamp}}}`
+
+// Payload is the data which is used to render the webhook template
+type Payload struct {
+	Name      string
+	Endpoint  string
+	Status    string
+	PreStatus string
+	Title     string
+	Message   string
+	RTT       time.Duration
+	SLA       float64
+	Windows   probe.SLAWindows
+	SLO       *probe.SLOStatus
+	Time      string
+	Timestamp int64
+	Result    probe.Result
+}
+
+// NewPayload creates the template data from the probe result
+func NewPayload(r probe.Result) Payload {
+	return Payload{
+		Name:      r.Name,
+		Endpoint:  r.Endpoint,
+		Status:    r.Status.String(),
+		PreStatus: r.PreStatus.String(),
+		Title:     r.Title(),
+		Message:   r.Message,
+		RTT:       r.RoundTripTime,
+		SLA:       r.SLAPercent(),
+		Windows:   r.Windows,
+		SLO:       r.SLO,
+		Time:      report.FormatTime(r.StartTime),
+		Timestamp: r.StartTimestamp,
+		Result:    r,
+	}
+}
+
+// NewBurnPayload creates the template data of the error budget burn rate alert,
+// the title and the message describe the alert instead of the status
+func NewBurnPayload(r probe.Result) Payload {
+	p := NewPayload(r)
+	p.Title = report.SLOTitle(r)
+	p.Message = report.SLOMessage(r)
+	return p
+}
+
+// NotifyConfig is the webhook notification configuration
+type NotifyConfig struct {
+	base.DefaultNotify `yaml:",inline"`
+	URL                string            `yaml:"url" json:"url" jsonschema:"format=uri,title=Webhook URL,description=The URL which the webhook would be sent to"`
+	Method             string            `yaml:"method,omitempty" json:"method,omitempty" jsonschema:"enum=POST,enum=PUT,enum=PATCH,title=HTTP Method,description=The HTTP method of the webhook,default=POST"`
+	Headers            map[string]string `yaml:"headers,omitempty" json:"headers,omitempty" jsonschema:"title=HTTP Headers,description=The HTTP headers of the webhook"`
+	Template           string            `yaml:"template,omitempty" json:"template,omitempty" jsonschema:"title=Payload Template,description=The Go template to render the payload of the webhook"`
+	global.TLS         `yaml:",inline"`
+
+	client *http.Client       `yaml:"-" json:"-"`
+	tpl    *template.Template `yaml:"-" json:"-"`
+}
+
+// Config configures the webhook notification
+func (c *NotifyConfig) Config(gConf global.NotifySettings) error {
+	c.NotifyKind = "webhook"
+	c.NotifyFormat = report.JSON
+	c.NotifyFormatFunc = c.Render
+	c.NotifyBurnFunc = c.RenderBurn
+	c.NotifySendFunc = c.SendWebhook
+	if err := c.DefaultNotify.Config(gConf); err != nil {
+		return err
+	}
+
+	// the URL could contain the secrets, only the scheme and the host are in the errors
+	if _, err := url.ParseRequestURI(c.URL); err != nil {
+		return fmt.Errorf("%s - invalid URL [%s]: %v", c.LogTitle(), base.RedactURL(c.URL), base.RedactError(err))
+	}
+
+	c.Method = strings.ToUpper(strings.TrimSpace(c.Method))
+	if c.Method == "" {
+		c.Method = http.MethodPost
+	}
+
+	if strings.TrimSpace(c.Template) == "" {
+		c.Template = DefaultTemplate
+	}
+	tpl, err := template.New(c.NotifyName).Funcs(FuncMap).Parse(c.Template)
+	if err != nil {
+		return fmt.Errorf("%s - invalid template: %v", c.LogTitle(), err)
+	}
+	// render a sample result to make sure the template is valid
+	if err := tpl.Execute(io.Discard, NewPayload(*probe.NewResult())); err != nil {
+		return fmt.Errorf("%s - invalid template: %v", c.LogTitle(), err)
+	}
+	c.tpl = tpl
+
+	tls, err := c.TLS.Config()
+	if err != nil {
+		return fmt.Errorf("%s - TLS configuration error: %v", c.LogTitle(), err)
+	}
+	c.client = &http.Client{
+		Timeout: c.Timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: tls,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+	}
+
+	// the URL and the header values could contain the secrets, only the host and the header names are logged
+	headers := make([]string, 0, len(c.Headers))
+	for k := range c.Headers {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	u, _ := url.Parse(c.URL)
+	log.Debugf("%s - configuration: method[%s], host[%s], headers%v", c.LogTitle(), c.Method, u.Host, headers)
+	return nil
+}
+
+// Render renders the result with the webhook template
+// if the rendering failed, the JSON of the result is used
+func (c *NotifyConfig) Render(r probe.Result) string {
+	return c.render(r, NewPayload(r))
+}
+
+// RenderBurn renders the error budget burn rate alert with the webhook template
+func (c *NotifyConfig) RenderBurn(r probe.Result) string {
+	return c.render(r, NewBurnPayload(r))
+}
+
+func (c *NotifyConfig) render(r probe.Result, p Payload) string {
+	var buf bytes.Buffer
+	if err := c.tpl.Execute(&buf, p); err != nil {
+		log.Errorf("%s - failed to render the template: %v", c.LogTitle(), err)
+		return report.ToJSON(r)
+	}
+	return buf.String()
+}
+
+// SendWebhook sends the payload to the webhook URL
+func (c *NotifyConfig) SendWebhook(title, msg string) error {
+	req, err := http.NewRequest(c.Method, c.URL, bytes.NewBufferString(msg))
+	if err != nil {
+		return base.RedactError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", global.OrgProgVer)
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Close = true
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return base.RedactError(err)
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error response from webhook - code [%d] - msg [%s]", resp.StatusCode, string(buf))
+	}
+	return nil
+}
+
+// FuncMap is the template functions for the webhook template
+var FuncMap = template.FuncMap{
+	"json": func(v interface{}) string {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return `""`
+		}
+		return string(buf)
+	},
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	r := probe.NewResult()
+	r.Name = "dummy probe"
+	r.Endpoint = "http://example.com"
+	r.RoundTripTime = 150 * time.Millisecond
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = `Error (http): "timeout"`
+	r.Stat.UpTime = 3 * time.Second
+	r.Stat.DownTime = time.Second
+	r.Windows = probe.SLAWindows{"1h": 50, "30d": 99.5}
+	return *r
+}
+
+type received struct {
+	method  string
+	headers http.Header
+	body    []byte
+}
+
+func newReceiver(t *testing.T, code int, ch chan received) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+		ch <- received{method: r.Method, headers: r.Header, body: body}
+		w.WriteHeader(code)
+		w.Write([]byte("done"))
+	}))
+}
+
+func TestWebhook(t *testing.T) {
+	ch := make(chan received, 10)
+	server := newReceiver(t, http.StatusOK, ch)
+	defer server.Close()
+
+	conf := &NotifyConfig{URL: server.URL}
+	conf.NotifyName = "dummy"
+	conf.Headers = map[string]string{"X-Token": "secret"}
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, "webhook", conf.Kind())
+	assert.Equal(t, http.MethodPost, conf.Method)
+	assert.Equal(t, DefaultTemplate, conf.Template)
+
+	r := newResult()
+	conf.Notify(r)
+	rcv := <-ch
+	assert.Equal(t, http.MethodPost, rcv.method)
+	assert.Equal(t, "secret", rcv.headers.Get("X-Token"))
+	assert.Equal(t, "application/json", rcv.headers.Get("Content-Type"))
+
+	m := map[string]interface{}{}
+	err = json.Unmarshal(rcv.body, &m)
+	assert.Nil(t, err)
+	assert.Equal(t, "dummy probe", m["name"])
+	assert.Equal(t, "http://example.com", m["endpoint"])
+	assert.Equal(t, "down", m["status"])
+	assert.Equal(t, "up", m["prestatus"])
+	assert.Equal(t, r.Title(), m["title"])
+	assert.Equal(t, r.Message, m["message"])
+	assert.Equal(t, float64(150), m["rtt"])
+	assert.Equal(t, float64(75), m["sla"])
+	assert.Equal(t, map[string]interface{}{"1h": float64(50), "30d": 99.5}, m["windows"])
+	assert.Nil(t, m["slo"])
+
+	// the burn rate alert
+	r.SLO = &probe.SLOStatus{Target: 99.9, Budget: 50, Alert: probe.BurnFast}
+	conf.NotifyBurn(r)
+	rcv = <-ch
+	m = map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(rcv.body, &m))
+	assert.Equal(t, "dummy probe Error Budget Fast Burn", m["title"])
+	assert.Equal(t, report.SLOMessage(r), m["message"])
+	assert.Equal(t, "fast", m["slo"].(map[string]interface{})["alert"])
+	assert.Equal(t, "down", m["status"])
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	ch := make(chan received, 10)
+	server := newReceiver(t, http.StatusOK, ch)
+	defer server.Close()
+
+	conf := &NotifyConfig{
+		URL:      server.URL,
+		Method:   "put",
+		Template: `{{.Title}}|{{.Status}}|{{.Result.Endpoint}}`,
+	}
+	conf.NotifyName = "dummy"
+	err := conf.Config(global.NotifySettings{})
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPut, conf.Method)
+
+	r := newResult()
+	assert.Equal(t, r.Title()+"|down|http://example.com", conf.Render(r))
+	conf.Notify(r)
+	rcv := <-ch
+	assert.Equal(t, http.MethodPut, rcv.method)
+	assert.Equal(t, r.Title()+"|down|http://example.com", string(rcv.body))
+}
+
+func TestWebhookError(t *testing.T) {
+	conf := &NotifyConfig{URL: "not a url"}
+	conf.NotifyName = "dummy"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	// the URL could contain the secrets, it's not in the errors
+	conf = &NotifyConfig{URL: "hooks/secret?token=secret"}
+	conf.NotifyName = "dummy"
+	err := conf.Config(global.NotifySettings{})
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+
+	conf = &NotifyConfig{URL: "http://localhost", Template: "{{.Title"}
+	conf.NotifyName = "dummy"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = &NotifyConfig{URL: "http://localhost", Template: "{{.NoSuchField}}"}
+	conf.NotifyName = "dummy"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	conf = &NotifyConfig{URL: "http://localhost"}
+	conf.NotifyName = "dummy"
+	conf.TLS.CA = "/no/such/ca.crt"
+	assert.NotNil(t, conf.Config(global.NotifySettings{}))
+
+	ch := make(chan received, 10)
+	server := newReceiver(t, http.StatusInternalServerError, ch)
+	defer server.Close()
+	conf = &NotifyConfig{URL: server.URL}
+	conf.NotifyName = "dummy"
+	assert.Nil(t, conf.Config(global.NotifySettings{}))
+	err = conf.SendWebhook("title", "{}")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "500")
+
+	conf.Method = "BAD METHOD"
+	assert.NotNil(t, conf.SendWebhook("title", "{}"))
+
+	conf.Method = http.MethodPost
+	conf.URL = "http://localhost:1/hook?token=secret"
+	err = conf.SendWebhook("title", "{}")
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+	assert.Contains(t, err.Error(), "http://localhost:1")
+}
@@ -7,15 +7,19 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/proxy"
 
+	"github.com/megaease/easeprobe/event"
 	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/maintenance"
 	"github.com/megaease/easeprobe/metric"
 	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
 )
 
 // Probe Simple Status
@@ -36,11 +40,17 @@ type DefaultProbe struct {
 	ProbeTimeout                         time.Duration     `yaml:"timeout,omitempty"  json:"timeout,omitempty"  jsonschema:"type=string,format=duration,title=Probe Timeout,description=the timeout of probe"`
 	ProbeTimeInterval                    time.Duration     `yaml:"interval,omitempty" json:"interval,omitempty" jsonschema:"type=string,format=duration,title=Probe Interval,description=the interval of probe"`
 	Labels                               prometheus.Labels `yaml:"labels,omitempty"   json:"labels,omitempty"   jsonschema:"title=Probe LabelMap,description=the labels of probe"`
+	ProbeHistory                         int               `yaml:"history,omitempty"  json:"history,omitempty"  jsonschema:"title=Probe History,description=the number of the probe results kept in the history"`
+	Public                               bool              `yaml:"public,omitempty"   json:"public,omitempty"   jsonschema:"title=Public Probe,description=show the probe and its messages on the public status page,default=false"`
 	global.StatusChangeThresholdSettings `yaml:",inline" json:",inline"`
 	global.NotificationStrategySettings  `yaml:"alert" json:"alert" jsonschema:"title=Probe Alert,description=the alert strategy of probe"`
-	ProbeFunc                            ProbeFuncType `yaml:"-"                  json:"-"`
-	ProbeResult                          *probe.Result `yaml:"-"                  json:"-"`
-	metrics                              *metrics      `yaml:"-"                  json:"-"`
+	MessageTemplate                      report.Template `yaml:"message,omitempty" json:"message,omitempty" jsonschema:"title=Message Template,description=the title and body templates of the notification message for this probe"`
+	SLO                                  probe.SLO       `yaml:"slo,omitempty" json:"slo,omitempty" jsonschema:"title=Probe SLO,description=the service level objective of the probe which raises the error budget burn rate alerts"`
+	ProbeFunc                            ProbeFuncType   `yaml:"-"                  json:"-"`
+	ProbeResult                          *probe.Result   `yaml:"-"                  json:"-"`
+	metrics                              *metrics        `yaml:"-"                  json:"-"`
+	mutex                                *sync.Mutex     `yaml:"-"                  json:"-"` // the probe could be run out-of-band by the operator
+	resultMutex                          *sync.RWMutex   `yaml:"-"                  json:"-"` // the result is read by the web server and the reports while probing
 }
 
 // LabelMap return the const metric labels  for a probe in the configuration.
@@ -70,6 +80,11 @@ func (d *DefaultProbe) Channels() []string {
 	return d.ProbeChannels
 }
 
+// IsPublic return true if the probe could be shown on the public status page
+func (d *DefaultProbe) IsPublic() bool {
+	return d.Public
+}
+
 // Timeout get the probe timeout
 func (d *DefaultProbe) Timeout() time.Duration {
 	return d.ProbeTimeout
@@ -85,6 +100,40 @@ func (d *DefaultProbe) Result() *probe.Result {
 	return d.ProbeResult
 }
 
+// Snapshot return the copy of the probe result, it's safe to be called while probing
+func (d *DefaultProbe) Snapshot() probe.Result {
+	d.lockResult(false)
+	defer d.unlockResult(false)
+	return d.ProbeResult.DeepClone()
+}
+
+// SetPhases set the traced phases of the probe result, it's called by the probe function
+func (d *DefaultProbe) SetPhases(phases *probe.Phases) {
+	d.lockResult(true)
+	defer d.unlockResult(true)
+	d.ProbeResult.Phases = phases
+}
+
+func (d *DefaultProbe) lockResult(write bool) {
+	switch {
+	case d.resultMutex == nil:
+	case write:
+		d.resultMutex.Lock()
+	default:
+		d.resultMutex.RLock()
+	}
+}
+
+func (d *DefaultProbe) unlockResult(write bool) {
+	switch {
+	case d.resultMutex == nil:
+	case write:
+		d.resultMutex.Unlock()
+	default:
+		d.resultMutex.RUnlock()
+	}
+}
+
 // LogTitle return the log title
 func (d *DefaultProbe) LogTitle() string {
 	if len(d.ProbeTag) > 0 {
@@ -152,14 +201,19 @@ func (d *DefaultProbe) Config(gConf global.ProbeSettings,
 
 	d.ProbeTimeout = gConf.NormalizeTimeOut(d.ProbeTimeout)
 	d.ProbeTimeInterval = gConf.NormalizeInterval(d.ProbeTimeInterval)
+	d.ProbeHistory = gConf.NormalizeHistory(d.ProbeHistory)
 	d.StatusChangeThresholdSettings = gConf.NormalizeThreshold(d.StatusChangeThresholdSettings)
 	d.NotificationStrategySettings = gConf.NormalizeNotificationStrategy(
 		d.NotificationStrategySettings,
 	)
 
+	d.mutex = &sync.Mutex{}
+	d.resultMutex = &sync.RWMutex{}
 	d.ProbeResult = probe.NewResultWithName(name)
 	d.ProbeResult.Name = name
 	d.ProbeResult.Endpoint = endpoint
+	// restore the paused and muted state from the data file
+	probe.SetControl(name, d.ProbeResult.Control)
 
 	// update the notification strategy settings
 	d.ProbeResult.Stat.NotificationStrategyData.Strategy = d.NotificationStrategySettings.Strategy
@@ -173,6 +227,9 @@ func (d *DefaultProbe) Config(gConf global.ProbeSettings,
 	}
 	d.ProbeResult.Stat.StatusCounter.SetMaxLen(maxLen)
 
+	// Set the max length of the history, the history could be loaded from the data file
+	d.ProbeResult.History.SetMaxLen(d.ProbeHistory)
+
 	// if there no channels, use the default channel
 	if len(d.ProbeChannels) == 0 {
 		d.ProbeChannels = append(d.ProbeChannels, global.DefaultChannelName)
@@ -189,6 +246,24 @@ func (d *DefaultProbe) Config(gConf global.ProbeSettings,
 		)
 	}
 
+	if err := d.MessageTemplate.Validate(); err != nil {
+		return fmt.Errorf("%s - %v", d.LogTitle(), err)
+	}
+
+	if d.SLO.Target != 0 {
+		d.SLO = d.SLO.Normalize()
+		if err := d.SLO.Check(); err != nil {
+			return fmt.Errorf("%s - %v", d.LogTitle(), err)
+		}
+		log.Infof("Probe %s SLO is configured! target[%v%%], window[%s], burn rate fast[%v] slow[%v]",
+			d.LogTitle(), d.SLO.Target, d.SLO.Window, d.SLO.FastBurn, d.SLO.SlowBurn)
+	}
+	report.SetProbeContext(name, report.ProbeContext{
+		Kind:     kind,
+		Labels:   d.Labels,
+		Template: d.MessageTemplate,
+	})
+
 	d.metrics = newMetrics(kind, tag, d.Labels)
 
 	return nil
@@ -196,26 +271,52 @@ func (d *DefaultProbe) Config(gConf global.ProbeSettings,
 
 // Probe return the checking result
 func (d *DefaultProbe) Probe() probe.Result {
+	if d.mutex != nil {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+	}
+
 	if d.ProbeFunc == nil {
 		return *d.ProbeResult
 	}
-
 	now := time.Now().UTC()
-	d.ProbeResult.StartTime = now
-	d.ProbeResult.StartTimestamp = now.UnixMilli()
+	// the phases are only set by the probers which trace them, e.g. HTTP
+	d.SetPhases(nil)
 
+	// the result is not locked while probing, the probe function could take the timeout
 	stat, msg := d.ProbeFunc()
+	rtt := time.Since(now)
+
+	d.lockResult(true)
+	defer d.unlockResult(true)
 
-	d.ProbeResult.RoundTripTime = time.Since(now)
+	d.ProbeResult.Control = probe.GetControl(d.ProbeName)
+	d.ProbeResult.StartTime = now
+	d.ProbeResult.StartTimestamp = now.UnixMilli()
+	d.ProbeResult.RoundTripTime = rtt
 
 	// check the status threshold
 	d.ProbeResult.Stat.StatusCounter.AppendStatus(stat, msg)
 	status := d.CheckStatusThreshold()
 	title := status.Title()
 
+	// the failures in the maintenance window are not counted toward the alerts
+	window := maintenance.Find(d.ProbeName, d.ProbeKind, d.Labels, now)
+	d.ProbeResult.Maintenance = ""
+	d.ProbeResult.SLAExcluded = false
+	if window != nil {
+		d.ProbeResult.Maintenance = window.Name
+		d.ProbeResult.SLAExcluded = window.ExcludeSLA
+		log.Debugf("%s - in maintenance window [%s]", d.LogTitle(), window.Name)
+	}
+
 	// process the notification strategy
 	if status != probe.StatusInit {
-		d.ProbeResult.Stat.NotificationStrategyData.ProcessStatus(status == probe.StatusUp)
+		if window == nil || status == probe.StatusUp {
+			d.ProbeResult.Stat.NotificationStrategyData.ProcessStatus(status == probe.StatusUp)
+		} else {
+			d.ProbeResult.Stat.NotificationStrategyData.IsSent = false
+		}
 	}
 
 	if len(d.ProbeTag) > 0 {
@@ -231,11 +332,30 @@ func (d *DefaultProbe) Probe() probe.Result {
 
 	d.DownTimeCalculation(status)
 
-	d.ProbeResult.DoStat(d.Interval())
+	d.ProbeResult.Incidents.Update(now, status, d.ProbeResult.Message)
+	d.ProbeResult.History.Append(probe.HistoryRecord{
+		Time:    now,
+		Status:  status,
+		RTT:     d.ProbeResult.RoundTripTime,
+		Message: d.ProbeResult.Message,
+	})
+
+	interval := d.Interval()
+	if window != nil && window.ExcludeSLA {
+		// only count the status, the time is excluded from the SLA
+		interval = 0
+	}
+	d.ProbeResult.DoStat(interval)
+	d.ProbeResult.Daily.Add(now, status == probe.StatusUp, interval)
+	d.ProbeResult.Buckets.Add(now, status == probe.StatusUp, interval)
+	d.ProbeResult.Minutes.Add(now, status == probe.StatusUp, interval)
+	d.ProbeResult.UpdateWindows(now)
+	d.ProbeResult.UpdateSLO(d.SLO, now)
 
 	d.ExportMetrics()
 
 	result := d.ProbeResult.Clone()
+	event.Publish(event.NewEvent(d.ProbeKind, result))
 	return result
 }
 
@@ -283,7 +403,29 @@ func (d *DefaultProbe) ExportMetrics() {
 	d.metrics.SLA.With(metric.AddConstLabels(prometheus.Labels{
 		"name":     d.ProbeName,
 		"endpoint": d.ProbeResult.Endpoint,
+		"window":   "all",
 	}, d.Labels)).Set(float64(d.ProbeResult.SLAPercent()))
+	for _, w := range probe.RollingWindows {
+		d.metrics.SLA.With(metric.AddConstLabels(prometheus.Labels{
+			"name":     d.ProbeName,
+			"endpoint": d.ProbeResult.Endpoint,
+			"window":   w.Name,
+		}, d.Labels)).Set(d.ProbeResult.Windows[w.Name])
+	}
+
+	if slo := d.ProbeResult.SLO; slo != nil {
+		d.metrics.SLOBudget.With(metric.AddConstLabels(prometheus.Labels{
+			"name":     d.ProbeName,
+			"endpoint": d.ProbeResult.Endpoint,
+		}, d.Labels)).Set(slo.Budget)
+		for window, rate := range slo.BurnRates {
+			d.metrics.BurnRate.With(metric.AddConstLabels(prometheus.Labels{
+				"name":     d.ProbeName,
+				"endpoint": d.ProbeResult.Endpoint,
+				"window":   window,
+			}, d.Labels)).Set(rate)
+		}
+	}
 }
 
 // DownTimeCalculation calculate the down time
@@ -6,16 +6,21 @@ import (
 	"math/rand"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/megaease/easeprobe/monkey"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/proxy"
 
+	"github.com/megaease/easeprobe/event"
 	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/maintenance"
 	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
 )
 
 var (
@@ -224,3 +229,260 @@ func TestStatusThreshold2(t *testing.T) {
 	p.Probe()
 	assert.Equal(t, probe.StatusUp, p.Result().Status)
 }
+
+func TestMessageTemplate(t *testing.T) {
+	p := newDummyProber("template")
+	p.Labels = prometheus.Labels{"env": "prod"}
+	p.MessageTemplate = report.Template{Title: "{{.Name}} in {{label .Labels \"env\"}}"}
+	err := p.DefaultProbe.Config(global.ProbeSettings{}, p.ProbeKind, p.ProbeTag, p.ProbeName, "endpoint", p.DoProbe)
+	assert.Nil(t, err)
+
+	ctx := report.GetProbeContext("template")
+	assert.Equal(t, "dummy", ctx.Kind)
+	assert.Equal(t, "prod", ctx.Labels["env"])
+	assert.Equal(t, p.MessageTemplate, ctx.Template)
+
+	p = newDummyProber("invalid template")
+	p.MessageTemplate = report.Template{Body: "{{.Name"}
+	err = p.DefaultProbe.Config(global.ProbeSettings{}, p.ProbeKind, p.ProbeTag, p.ProbeName, "endpoint", p.DoProbe)
+	assert.NotNil(t, err)
+}
+
+func TestMaintenance(t *testing.T) {
+	p := newDummyProber("maintenance")
+	p.ProbeTimeInterval = time.Minute
+	p.Config(global.ProbeSettings{})
+	p.ProbeFunc = func() (bool, string) { return false, "failure" }
+
+	maintenance.SetWindows([]maintenance.Window{
+		{Name: "db upgrade", Time: "00:00-24:00", Kinds: []string{"dummy"}, ExcludeSLA: true},
+	})
+	defer maintenance.SetWindows(nil)
+
+	// the probe still runs, but the failures are not counted toward the alerts
+	for i := 0; i < 3; i++ {
+		r := p.Probe()
+		assert.Equal(t, probe.StatusDown, r.Status)
+		assert.Equal(t, "db upgrade", r.Maintenance)
+		assert.True(t, r.SLAExcluded)
+		assert.Equal(t, 0, r.Stat.NotificationStrategyData.Failed)
+		assert.False(t, r.Stat.NotificationStrategyData.NeedToSendNotification())
+	}
+	assert.Equal(t, int64(3), p.ProbeResult.Stat.Status[probe.StatusDown])
+	assert.Equal(t, time.Duration(0), p.ProbeResult.Stat.DownTime)
+
+	// the window is not excluded from the SLA
+	maintenance.SetWindows([]maintenance.Window{
+		{Name: "db upgrade", Time: "00:00-24:00", Probes: []string{"maintenance"}},
+	})
+	r := p.Probe()
+	assert.Equal(t, "db upgrade", r.Maintenance)
+	assert.False(t, r.SLAExcluded)
+	assert.Equal(t, time.Minute, r.Stat.DownTime)
+
+	// the window is over, the failure is counted again
+	maintenance.SetWindows(nil)
+	r = p.Probe()
+	assert.Equal(t, "", r.Maintenance)
+	assert.Equal(t, 1, r.Stat.NotificationStrategyData.Failed)
+	assert.True(t, r.Stat.NotificationStrategyData.NeedToSendNotification())
+}
+
+func TestHistory(t *testing.T) {
+	p := newDummyProber("history")
+	p.ProbeHistory = 3
+	p.Config(global.ProbeSettings{})
+	assert.Equal(t, 3, p.ProbeResult.History.MaxLen())
+
+	n := 0
+	p.ProbeFunc = func() (bool, string) {
+		n++
+		return n%2 == 0, fmt.Sprintf("probe %d", n)
+	}
+	for i := 0; i < 5; i++ {
+		p.Probe()
+	}
+	records := p.Result().History.Records()
+	assert.Equal(t, 3, len(records))
+	assert.Contains(t, records[0].Message, "probe 3")
+	assert.Equal(t, probe.StatusDown, records[0].Status)
+	assert.Equal(t, probe.StatusUp, records[1].Status)
+
+	// the default history size
+	p = newDummyProber("history default")
+	p.Config(global.ProbeSettings{})
+	assert.Equal(t, global.DefaultHistorySize, p.ProbeHistory)
+	p = newDummyProber("history global")
+	p.Config(global.ProbeSettings{History: 10})
+	assert.Equal(t, 10, p.ProbeResult.History.MaxLen())
+}
+
+func TestUptimeAndIncidents(t *testing.T) {
+	p := newDummyProber("incidents")
+	p.ProbeTimeInterval = time.Minute
+	p.Public = true
+	p.Config(global.ProbeSettings{})
+	assert.True(t, p.IsPublic())
+
+	ok := true
+	p.ProbeFunc = func() (bool, string) { return ok, msg[ok] }
+	p.Probe()
+	ok = false
+	p.Probe()
+	p.Probe()
+	ok = true
+	p.Probe()
+
+	r := p.Result()
+	assert.Equal(t, 1, len(r.Daily))
+	assert.Equal(t, 2*time.Minute, r.Daily[0].UpTime)
+	assert.Equal(t, 2*time.Minute, r.Daily[0].DownTime)
+	assert.Equal(t, float64(50), r.Daily[0].Percent())
+
+	up, down := r.Buckets.Window(time.Now(), 24*time.Hour)
+	assert.Equal(t, 2*time.Minute, up)
+	assert.Equal(t, 2*time.Minute, down)
+	assert.Equal(t, len(probe.RollingWindows), len(r.Windows))
+	assert.Equal(t, float64(50), r.Windows["30d"])
+
+	assert.Equal(t, 1, len(r.Incidents))
+	assert.Equal(t, probe.StatusDown, r.Incidents[0].Status)
+	assert.Contains(t, r.Incidents[0].Message, "failed")
+	assert.False(t, r.Incidents[0].Ongoing())
+}
+
+func TestSLO(t *testing.T) {
+	p := newDummyProber("slo")
+	p.ProbeTimeInterval = time.Minute
+	p.SLO = probe.SLO{Target: 99}
+	p.Config(global.ProbeSettings{})
+	assert.Equal(t, probe.DefaultSLOWindow, p.SLO.Window)
+	assert.Equal(t, probe.DefaultFastBurn, p.SLO.FastBurn)
+
+	ok := true
+	p.ProbeFunc = func() (bool, string) { return ok, msg[ok] }
+	r := p.Probe()
+	assert.Equal(t, float64(100), r.SLO.Budget)
+	assert.Equal(t, probe.BurnNone, r.SLO.Alert)
+	ok = false
+	r = p.Probe()
+	assert.Equal(t, probe.BurnFast, r.SLO.Alert)
+	assert.Equal(t, probe.BurnNone, r.SLO.PreAlert)
+	assert.InDelta(t, 50, r.SLO.BurnRates["1h"], 1e-9)
+	assert.InDelta(t, -4900, r.SLO.Budget, 1e-9)
+	r = p.Probe()
+	assert.False(t, r.SLO.IsChanged())
+
+	// the invalid SLO
+	p = newDummyProber("bad slo")
+	p.SLO = probe.SLO{Target: 100}
+	err := p.DefaultProbe.Config(global.ProbeSettings{}, p.ProbeKind, p.ProbeTag, p.ProbeName, "endpoint", p.DoProbe)
+	assert.NotNil(t, err)
+
+	// no SLO
+	p = newDummyProber("no slo")
+	p.Config(global.ProbeSettings{})
+	assert.Nil(t, p.Probe().SLO)
+}
+
+func TestControl(t *testing.T) {
+	p := newDummyProber("control")
+	p.Config(global.ProbeSettings{})
+	p.ProbeFunc = func() (bool, string) { return true, "success" }
+
+	probe.Pause("control")
+	probe.Mute("control", time.Hour)
+	r := p.Probe()
+	assert.True(t, r.Control.Paused)
+	assert.True(t, r.Control.IsMuted(time.Now()))
+
+	// the state is restored from the result data
+	p = newDummyProber("control")
+	probe.SetControl("control", probe.Control{})
+	probe.SetResultData("control", &r)
+	p.Config(global.ProbeSettings{})
+	assert.True(t, probe.GetControl("control").Paused)
+
+	probe.SetControl("control", probe.Control{})
+	r = p.Probe()
+	assert.False(t, r.Control.Paused)
+}
+
+func TestEvent(t *testing.T) {
+	p := newDummyProber("event")
+	p.Config(global.ProbeSettings{})
+	p.ProbeFunc = func() (bool, string) { return false, "failure" }
+
+	sub, _ := event.Subscribe(event.Filter{Names: []string{"event"}}, 0, 0)
+	defer event.Unsubscribe(sub)
+
+	p.Probe()
+	e := <-sub.C
+	assert.Equal(t, event.TypeTransition, e.Type)
+	assert.Equal(t, "dummy", e.Kind)
+	assert.Equal(t, probe.StatusDown, e.Status)
+
+	p.Probe()
+	e = <-sub.C
+	assert.Equal(t, event.TypeResult, e.Type)
+}
+
+func TestSnapshot(t *testing.T) {
+	p := newDummyProber("snapshot")
+	p.Config(global.ProbeSettings{})
+	p.ProbeFunc = func() (bool, string) { return true, "success" }
+	p.Probe()
+
+	// the snapshot is a copy
+	r := probe.Snapshot(p)
+	assert.Equal(t, probe.StatusUp, r.Status)
+	assert.Equal(t, 1, r.History.Len())
+	r.Stat.Status[probe.StatusUp] = 100
+	assert.Equal(t, int64(1), p.Result().Stat.Status[probe.StatusUp])
+
+	// the snapshot is not blocked while probing
+	probing, done := make(chan bool), make(chan bool)
+	p.ProbeFunc = func() (bool, string) {
+		probing <- true
+		<-done
+		p.SetPhases(&probe.Phases{Total: time.Second})
+		return false, "failure"
+	}
+	go p.Probe()
+	<-probing
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, probe.StatusUp, probe.Snapshot(p).Status)
+	}
+	close(done)
+	assert.Eventually(t, func() bool {
+		return probe.Snapshot(p).Status == probe.StatusDown
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, time.Second, probe.Snapshot(p).Phases.Total)
+}
+
+func TestControlRestart(t *testing.T) {
+	name := "control restart"
+	p := newDummyProber(name)
+	p.Config(global.ProbeSettings{})
+	p.Probe()
+
+	// the paused probe doesn't run, so its result keeps the stale control state
+	probe.Pause(name)
+	assert.False(t, p.Result().Control.Paused)
+
+	// save the data file as the data saving process does
+	file := filepath.Join(t.TempDir(), "data.yaml")
+	r := probe.Snapshot(p)
+	assert.True(t, r.Control.Paused)
+	probe.SetResultData(name, &r)
+	assert.Nil(t, probe.SaveDataToFile(file))
+
+	// restart: the control state is restored from the data file
+	probe.SetControl(name, probe.Control{})
+	assert.Nil(t, probe.LoadDataFromFile(file))
+	n := newDummyProber(name)
+	n.Config(global.ProbeSettings{})
+	assert.True(t, probe.GetControl(name).Paused)
+	assert.True(t, n.Result().Control.Paused)
+	probe.Resume(name)
+}
@@ -14,6 +14,8 @@ type metrics struct {
 	Duration  *prometheus.GaugeVec
 	Status    *prometheus.GaugeVec
 	SLA       *prometheus.GaugeVec
+	SLOBudget *prometheus.GaugeVec
+	BurnRate  *prometheus.GaugeVec
 }
 
 // newMetrics create the metrics
@@ -29,6 +31,10 @@ func newMetrics(subsystem, name string, constLabels prometheus.Labels) *metrics
 		Status: metric.NewGauge(namespace, subsystem, name, "status",
 			"Probe Status", []string{"name", "endpoint"}, constLabels),
 		SLA: metric.NewGauge(namespace, subsystem, name, "sla",
-			"Probe SLA", []string{"name", "endpoint"}, constLabels),
+			"Probe SLA", []string{"name", "endpoint", "window"}, constLabels),
+		SLOBudget: metric.NewGauge(namespace, subsystem, name, "slo_error_budget",
+			"Remaining Error Budget(%) of the SLO", []string{"name", "endpoint"}, constLabels),
+		BurnRate: metric.NewGauge(namespace, subsystem, name, "slo_burn_rate",
+			"Error Budget Burn Rate of the SLO", []string{"name", "endpoint", "window"}, constLabels),
 	}
 }
@@ -0,0 +1,74 @@
+package probe
+
+import (
+	"sync"
+	"time"
+)
+
+// Control is the operator control state of the probe
+type Control struct {
+	Paused     bool      `json:"paused" yaml:"paused,omitempty"`
+	MutedUntil time.Time `json:"muted_until,omitempty" yaml:"muted_until,omitempty"`
+}
+
+// IsMuted returns true if the notifications of the probe are muted at the time
+func (c Control) IsMuted(t time.Time) bool {
+	return t.Before(c.MutedUntil)
+}
+
+var (
+	controls     = map[string]Control{}
+	controlMutex = &sync.RWMutex{}
+)
+
+// GetControl returns the control state of the probe, the expired mute is cleared
+func GetControl(name string) Control {
+	controlMutex.RLock()
+	c := controls[name]
+	controlMutex.RUnlock()
+	if !c.MutedUntil.IsZero() && !c.IsMuted(time.Now()) {
+		c.MutedUntil = time.Time{}
+	}
+	return c
+}
+
+// SetControl sets the control state of the probe,
+// the state is also set into the result data, so that it could be saved into the data file.
+func SetControl(name string, c Control) {
+	controlMutex.Lock()
+	controls[name] = c
+	controlMutex.Unlock()
+
+	mutex.Lock()
+	if r, ok := resultData[name]; ok {
+		r.Control = c
+	}
+	mutex.Unlock()
+}
+
+// Pause pauses the probe
+func Pause(name string) Control {
+	c := GetControl(name)
+	c.Paused = true
+	SetControl(name, c)
+	return c
+}
+
+// Resume resumes the paused probe
+func Resume(name string) Control {
+	c := GetControl(name)
+	c.Paused = false
+	SetControl(name, c)
+	return c
+}
+
+// Mute mutes the notifications of the probe for the duration, zero duration unmutes the probe
+func Mute(name string, d time.Duration) Control {
+	c := GetControl(name)
+	c.MutedUntil = time.Time{}
+	if d > 0 {
+		c.MutedUntil = time.Now().Add(d).UTC()
+	}
+	SetControl(name, c)
+	return c
+}
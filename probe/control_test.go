@@ -0,0 +1,56 @@
+package probe
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControl(t *testing.T) {
+	name := "control probe"
+	assert.Equal(t, Control{}, GetControl(name))
+
+	c := Pause(name)
+	assert.True(t, c.Paused)
+	assert.True(t, GetControl(name).Paused)
+	assert.False(t, Resume(name).Paused)
+
+	c = Mute(name, time.Hour)
+	assert.True(t, c.IsMuted(time.Now()))
+	assert.False(t, c.IsMuted(time.Now().Add(2*time.Hour)))
+	assert.Equal(t, c, GetControl(name))
+	assert.True(t, Mute(name, 0).MutedUntil.IsZero())
+
+	// the expired mute is cleared
+	SetControl(name, Control{MutedUntil: time.Now().Add(-time.Minute)})
+	assert.Equal(t, Control{}, GetControl(name))
+}
+
+func TestControlDataFile(t *testing.T) {
+	name := "control data"
+	data := resultData
+	defer func() { resultData = data }()
+	resultData = map[string]*Result{}
+	NewResultWithName(name)
+	Pause(name)
+	until := Mute(name, time.Hour).MutedUntil
+	assert.True(t, GetResultData(name).Control.Paused)
+
+	file := "control.yaml"
+	defer os.RemoveAll(file)
+	assert.Nil(t, SaveDataToFile(file))
+	defer func() {
+		if b := GetMetaData().backup; b != "" {
+			os.RemoveAll(b)
+		}
+	}()
+
+	resultData = map[string]*Result{}
+	assert.Nil(t, LoadDataFromFile(file))
+	r := GetResultData(name)
+	assert.NotNil(t, r)
+	assert.True(t, r.Control.Paused)
+	assert.True(t, until.Equal(r.Control.MutedUntil))
+}
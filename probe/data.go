@@ -47,7 +47,7 @@ func GetMetaData() *MetaData {
 //
 //	int saveData() in cmd/easeprobe/report.go
 func SetResultData(name string, result *Result) {
-	r := result.Clone()
+	r := result.DeepClone()
 	mutex.Lock()
 	resultData[name] = &r
 	mutex.Unlock()
@@ -67,7 +67,7 @@ func GetResultData(name string) *Result {
 	mutex.RLock()
 	defer mutex.RUnlock()
 	if v, ok := resultData[name]; ok {
-		r := v.Clone()
+		r := v.DeepClone()
 		return &r
 	}
 	return nil
@@ -90,14 +90,19 @@ func CleanData(p []Prober) {
 }
 
 // SaveDataToFile save the results to file
-// Note: No need to consider the thread-safe, because this function and SetResultData in same goroutine
+// The data is written into a temporary file which is renamed to the data file,
+// so the data file is never left half-written if the process is killed during the saving.
+// Note: this function and SetResultData are called in the same goroutine,
+// the lock only protects the results which are set by the probers during the startup
 func SaveDataToFile(filename string) error {
 	metaData.file = filename
 	if strings.TrimSpace(filename) == "-" {
 		return nil
 	}
 
+	mutex.RLock()
 	dataBuf, err := yaml.Marshal(resultData)
+	mutex.RUnlock()
 	if err != nil {
 		return err
 	}
@@ -105,7 +110,12 @@ func SaveDataToFile(filename string) error {
 	genMetaBuf()
 	buf := append(metaBuf, dataBuf...)
 
-	if err := os.WriteFile(filename, []byte(buf), 0644); err != nil {
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, []byte(buf), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
 		return err
 	}
 	return nil
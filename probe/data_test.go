@@ -155,6 +155,30 @@ func TestNewDataFile(t *testing.T) {
 	monkey.UnpatchAll()
 }
 
+func TestSaveDataFileAtomic(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data.yaml")
+	assert.Nil(t, newDataFile(file))
+	assert.False(t, isDataFileExisted(file+".tmp"))
+	before, err := os.ReadFile(file)
+	assert.Nil(t, err)
+
+	// the data file is kept if the saving is failed
+	monkey.Patch(os.Rename, func(oldpath, newpath string) error {
+		return fmt.Errorf("error")
+	})
+	SetResultData("atomic", &Result{Name: "atomic"})
+	assert.Error(t, SaveDataToFile(file))
+	monkey.UnpatchAll()
+	assert.False(t, isDataFileExisted(file+".tmp"))
+	after, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	mutex.Lock()
+	delete(resultData, "atomic")
+	mutex.Unlock()
+}
+
 func TestLoadDataFile(t *testing.T) {
 	// no data file
 	file := "data.yaml"
@@ -171,11 +195,11 @@ func TestLoadDataFile(t *testing.T) {
 	removeAll(metaData.backup)
 	checkData(t)
 
-	// errors - backup file
+	// errors - backup file, the data file is saved before the rename is broken
+	newDataFile(file)
 	monkey.Patch(os.Rename, func(oldpath, newpath string) error {
 		return fmt.Errorf("error")
 	})
-	newDataFile(file)
 	err = LoadDataFromFile(file)
 	assert.Nil(t, err)
 	assert.False(t, isDataFileExisted(metaData.backup))
@@ -0,0 +1,129 @@
+package probe
+
+import (
+	"time"
+)
+
+// HistoryRecord is the result of one probe execution
+type HistoryRecord struct {
+	Time    time.Time     `json:"time" yaml:"time"`
+	Status  Status        `json:"status" yaml:"status"`
+	RTT     time.Duration `json:"rtt" yaml:"rtt"`
+	Message string        `json:"message" yaml:"message"`
+}
+
+// History is the bounded history of the probe executions, it is a ring buffer,
+// the oldest record is overwritten when the history is full
+type History struct {
+	records []HistoryRecord // the ring buffer
+	next    int             // the index of the oldest record when the buffer is full
+	maxLen  int             // the max length of the history
+}
+
+// historyYAML is the YAML/JSON format of the history, the records are in chronological order
+type historyYAML struct {
+	MaxLen  int             `json:"maxlen" yaml:"maxlen"`
+	Records []HistoryRecord `json:"records" yaml:"records"`
+}
+
+// NewHistory return a History object
+func NewHistory(maxLen int) *History {
+	return &History{
+		records: make([]HistoryRecord, 0),
+		maxLen:  maxLen,
+	}
+}
+
+// MaxLen returns the max length of the history
+func (h *History) MaxLen() int {
+	return h.maxLen
+}
+
+// Len returns the number of the records in the history
+func (h *History) Len() int {
+	return len(h.records)
+}
+
+// Append appends the record, the oldest record is dropped if the history is full
+func (h *History) Append(r HistoryRecord) {
+	if h.maxLen <= 0 {
+		return
+	}
+	if len(h.records) < h.maxLen {
+		h.records = append(h.records, r)
+		return
+	}
+	h.records[h.next] = r
+	h.next = (h.next + 1) % h.maxLen
+}
+
+// ordered returns the copy of the records in chronological order, nil is returned if no records
+func (h *History) ordered() []HistoryRecord {
+	if len(h.records) == 0 {
+		return nil
+	}
+	list := make([]HistoryRecord, 0, len(h.records))
+	list = append(list, h.records[h.next:]...)
+	list = append(list, h.records[:h.next]...)
+	return list
+}
+
+// Records returns the copy of the records in chronological order
+func (h *History) Records() []HistoryRecord {
+	if list := h.ordered(); list != nil {
+		return list
+	}
+	return []HistoryRecord{}
+}
+
+// Since returns the records which are not earlier than the time
+func (h *History) Since(t time.Time) []HistoryRecord {
+	list := h.Records()
+	for i := range list {
+		if !list[i].Time.Before(t) {
+			return list[i:]
+		}
+	}
+	return []HistoryRecord{}
+}
+
+// SetMaxLen sets the max length of the history, the oldest records are dropped if it is shrunk
+func (h *History) SetMaxLen(maxLen int) {
+	list := h.ordered()
+	if maxLen < 0 {
+		maxLen = 0
+	}
+	if len(list) > maxLen {
+		list = list[len(list)-maxLen:]
+	}
+	h.records = list
+	h.next = 0
+	h.maxLen = maxLen
+}
+
+// Clone returns a deep copy of the History, the ring buffer could not be shared
+func (h *History) Clone() History {
+	return History{
+		records: h.ordered(),
+		next:    0,
+		maxLen:  h.maxLen,
+	}
+}
+
+// MarshalYAML marshals the history as the records in chronological order
+func (h History) MarshalYAML() (interface{}, error) {
+	return historyYAML{MaxLen: h.maxLen, Records: h.ordered()}, nil
+}
+
+// UnmarshalYAML unmarshals the history
+func (h *History) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v historyYAML
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	h.records = v.Records
+	h.next = 0
+	h.maxLen = len(v.Records)
+	h.SetMaxLen(v.MaxLen)
+	return nil
+}
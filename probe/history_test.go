@@ -0,0 +1,92 @@
+package probe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func newRecord(i int) HistoryRecord {
+	return HistoryRecord{
+		Time:    time.Date(2024, 1, 1, 0, i, 0, 0, time.UTC),
+		Status:  StatusUp,
+		RTT:     time.Duration(i) * time.Millisecond,
+		Message: fmt.Sprintf("message %d", i),
+	}
+}
+
+func messages(records []HistoryRecord) []string {
+	list := []string{}
+	for _, r := range records {
+		list = append(list, r.Message)
+	}
+	return list
+}
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(3)
+	assert.Equal(t, 3, h.MaxLen())
+	assert.Equal(t, 0, h.Len())
+	assert.NotNil(t, h.Records())
+
+	for i := 1; i <= 5; i++ {
+		h.Append(newRecord(i))
+	}
+	assert.Equal(t, 3, h.Len())
+	assert.Equal(t, []string{"message 3", "message 4", "message 5"}, messages(h.Records()))
+	assert.Equal(t, []string{"message 4", "message 5"}, messages(h.Since(newRecord(4).Time)))
+	assert.Equal(t, 0, len(h.Since(newRecord(6).Time)))
+
+	// the clone doesn't share the ring buffer
+	c := h.Clone()
+	h.Append(newRecord(6))
+	assert.Equal(t, []string{"message 3", "message 4", "message 5"}, messages(c.Records()))
+	assert.Equal(t, []string{"message 4", "message 5", "message 6"}, messages(h.Records()))
+
+	// shrink and enlarge
+	h.SetMaxLen(2)
+	assert.Equal(t, []string{"message 5", "message 6"}, messages(h.Records()))
+	h.SetMaxLen(4)
+	h.Append(newRecord(7))
+	h.Append(newRecord(8))
+	h.Append(newRecord(9))
+	assert.Equal(t, []string{"message 6", "message 7", "message 8", "message 9"}, messages(h.Records()))
+
+	// disabled
+	h.SetMaxLen(-1)
+	h.Append(newRecord(10))
+	assert.Equal(t, 0, h.Len())
+}
+
+func TestHistoryYAML(t *testing.T) {
+	h := NewHistory(3)
+	for i := 1; i <= 4; i++ {
+		h.Append(newRecord(i))
+	}
+	buf, err := yaml.Marshal(h)
+	assert.Nil(t, err)
+	assert.Contains(t, string(buf), "maxlen: 3")
+
+	var h2 History
+	assert.Nil(t, yaml.Unmarshal(buf, &h2))
+	assert.Equal(t, 3, h2.MaxLen())
+	assert.Equal(t, h.Records(), h2.Records())
+	h2.Append(newRecord(5))
+	assert.Equal(t, []string{"message 3", "message 4", "message 5"}, messages(h2.Records()))
+
+	// the history is saved with the result, but not in the JSON
+	r := NewResult()
+	r.History.Append(newRecord(1))
+	buf, err = yaml.Marshal(r)
+	assert.Nil(t, err)
+	assert.Contains(t, string(buf), "message 1")
+	assert.NotContains(t, r.DebugJSON(), "message 1")
+	r2 := Result{}
+	assert.Nil(t, yaml.Unmarshal(buf, &r2))
+	assert.Equal(t, r.History.Records(), r2.History.Records())
+
+	assert.NotNil(t, yaml.Unmarshal([]byte("records: invalid"), &h2))
+}
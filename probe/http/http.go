@@ -209,6 +209,9 @@ func (h *HTTP) DoProbe() (bool, string) {
 
 	resp, err := h.client.Do(req)
 	h.traceStats.Done()
+	if h.ProbeResult != nil {
+		h.SetPhases(h.traceStats.Phases())
+	}
 	prometheus.NewRegistry()
 
 	h.ExportMetrics(resp)
@@ -6,6 +6,7 @@ import (
 	"net/http/httptrace"
 	"time"
 
+	"github.com/megaease/easeprobe/probe"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -160,6 +161,26 @@ func (s *TraceStats) Report() {
 	log.Debugf("[%s %s %s] ===========================================================", s.kind, s.tag, s.name)
 }
 
+// Phases returns the time spent in the phases of the request,
+// nil is returned if the request is not started, and the phases which are not started are zero
+func (s *TraceStats) Phases() *probe.Phases {
+	if s.totalStartAt.IsZero() {
+		return nil
+	}
+	p := &probe.Phases{
+		DNS:     s.dnsTook,
+		Connect: s.connTook,
+		TLS:     s.tlsTook,
+		Send:    s.sendTook,
+		Wait:    s.waitTook,
+		Total:   s.totalTook,
+	}
+	if !s.transferStartAt.IsZero() {
+		p.Transfer = s.transferTook
+	}
+	return p
+}
+
 func toMS(t time.Duration) float64 {
 	return float64(t.Nanoseconds()) / 1000000.0
 }
@@ -119,3 +119,23 @@ func TestTraceDone(t *testing.T) {
 	assert.Equal(t, took, s.connTook)
 
 }
+
+func TestTracePhases(t *testing.T) {
+	s := NewTraceStats("http", "tag", "test")
+	assert.Nil(t, s.Phases())
+
+	s.getConn("8080")
+	s.dnsTook = time.Millisecond
+	s.connTook = 2 * time.Millisecond
+	s.Done()
+	p := s.Phases()
+	assert.Equal(t, time.Millisecond, p.DNS)
+	assert.Equal(t, 2*time.Millisecond, p.Connect)
+	// the response is not received
+	assert.Equal(t, time.Duration(0), p.Transfer)
+	assert.Less(t, p.Total, time.Minute)
+
+	s.gotFirstResponseByte()
+	s.Done()
+	assert.Less(t, s.Phases().Transfer, time.Minute)
+}
@@ -0,0 +1,25 @@
+package probe
+
+import (
+	"time"
+)
+
+// Phases is the time spent in the phases of the HTTP request
+type Phases struct {
+	DNS      time.Duration `json:"dns" yaml:"dns"`
+	Connect  time.Duration `json:"connect" yaml:"connect"`
+	TLS      time.Duration `json:"tls" yaml:"tls"`
+	Send     time.Duration `json:"send" yaml:"send"`
+	Wait     time.Duration `json:"wait" yaml:"wait"`
+	Transfer time.Duration `json:"transfer" yaml:"transfer"`
+	Total    time.Duration `json:"total" yaml:"total"`
+}
+
+// Clone returns a copy of the Phases
+func (p *Phases) Clone() *Phases {
+	if p == nil {
+		return nil
+	}
+	dst := *p
+	return &dst
+}
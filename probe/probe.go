@@ -23,3 +23,21 @@ type Prober interface {
 	Config(global.ProbeSettings) error
 	Probe() Result
 }
+
+// Snapshotter is the prober which copies its result safely while probing
+type Snapshotter interface {
+	Snapshot() Result
+}
+
+// Snapshot return the copy of the prober's result, it should be used to read the result outside the probe goroutine,
+// the control state is the latest one, because the paused probe doesn't update its result
+func Snapshot(p Prober) Result {
+	var r Result
+	if s, ok := p.(Snapshotter); ok {
+		r = s.Snapshot()
+	} else {
+		r = p.Result().DeepClone()
+	}
+	r.Control = GetControl(p.Name())
+	return r
+}
@@ -33,7 +33,18 @@ type Result struct {
 	Message          string        `json:"message" yaml:"message"`
 	LatestDownTime   time.Time     `json:"latestdowntime" yaml:"latestdowntime"`
 	RecoveryDuration time.Duration `json:"recoverytime" yaml:"recoverytime"`
+	Maintenance      string        `json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+	SLAExcluded      bool          `json:"sla_excluded,omitempty" yaml:"-"` // the maintenance window is excluded from the SLA
+	Control          Control       `json:"-" yaml:"control,omitempty"`
 	Stat             Stat          `json:"stat" yaml:"stat"`
+	History          History       `json:"-" yaml:"history"`
+	Daily            Daily         `json:"-" yaml:"daily,omitempty"`
+	Incidents        Incidents     `json:"-" yaml:"incidents,omitempty"`
+	Buckets          Buckets       `json:"-" yaml:"buckets,omitempty"`
+	Minutes          MinuteBuckets `json:"-" yaml:"minutes,omitempty"`
+	Windows          SLAWindows    `json:"windows,omitempty" yaml:"-"`
+	SLO              *SLOStatus    `json:"slo,omitempty" yaml:"slo,omitempty"`
+	Phases           *Phases       `json:"phases,omitempty" yaml:"-"`
 }
 
 // NewResult return a Result object
@@ -65,6 +76,7 @@ func NewResult() *Result {
 				global.DefaultNotificationFactor,
 			),
 		},
+		History: *NewHistory(global.DefaultHistorySize),
 	}
 }
 
@@ -82,7 +94,8 @@ func NewResultWithName(name string) *Result {
 	return r
 }
 
-// Clone return a clone of the Result
+// Clone return a clone of the Result which is sent to the notifications and the events,
+// the history, the daily uptime, the incidents and the hourly buckets are not copied, see DeepClone
 func (r *Result) Clone() Result {
 	dst := Result{}
 	dst.Name = r.Name
@@ -95,7 +108,25 @@ func (r *Result) Clone() Result {
 	dst.Message = r.Message
 	dst.LatestDownTime = r.LatestDownTime
 	dst.RecoveryDuration = r.RecoveryDuration
+	dst.Maintenance = r.Maintenance
+	dst.SLAExcluded = r.SLAExcluded
+	dst.Control = r.Control
 	dst.Stat = r.Stat.Clone()
+	dst.Windows = r.Windows.Clone()
+	dst.SLO = r.SLO.Clone()
+	dst.Phases = r.Phases.Clone()
+	return dst
+}
+
+// DeepClone return a clone of the Result with the history, the daily uptime, the incidents and the buckets,
+// it's used by the data file and the readers of the whole result, e.g. the web pages and the reports
+func (r *Result) DeepClone() Result {
+	dst := r.Clone()
+	dst.History = r.History.Clone()
+	dst.Daily = r.Daily.Clone()
+	dst.Incidents = r.Incidents.Clone()
+	dst.Buckets = r.Buckets.Clone()
+	dst.Minutes = r.Minutes.Clone()
 	return dst
 }
 
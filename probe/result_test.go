@@ -53,6 +53,32 @@ func TestResult(t *testing.T) {
 	assert.Equal(t, name, d.Name)
 }
 
+func TestResultDeepClone(t *testing.T) {
+	r := CreateTestResult()
+	now := r.StartTime
+	r.History.SetMaxLen(10)
+	r.History.Append(HistoryRecord{Time: now, Status: StatusUp, Message: "up"})
+	r.Daily.Add(now, true, time.Minute)
+	r.Buckets.Add(now, true, time.Minute)
+	r.Incidents.Update(now, StatusDown, "down")
+
+	// the light clone is sent with every probe result
+	c := r.Clone()
+	assert.Equal(t, r.Stat, c.Stat)
+	assert.Equal(t, 0, c.History.Len())
+	assert.Nil(t, c.Daily)
+	assert.Nil(t, c.Buckets)
+	assert.Nil(t, c.Incidents)
+
+	d := r.DeepClone()
+	assert.Equal(t, r.History.Records(), d.History.Records())
+	assert.Equal(t, r.Daily, d.Daily)
+	assert.Equal(t, r.Buckets, d.Buckets)
+	assert.Equal(t, r.Incidents, d.Incidents)
+	d.Buckets[0].UpTime = 0
+	assert.Equal(t, time.Minute, r.Buckets[0].UpTime)
+}
+
 func TestStatClone(t *testing.T) {
 	s := Stat{
 		Since: time.Now().UTC().Round(time.Millisecond),
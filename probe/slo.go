@@ -0,0 +1,225 @@
+package probe
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+)
+
+// The default settings of the SLO
+const (
+	DefaultSLOWindow = 30 * 24 * time.Hour
+	DefaultFastBurn  = 14.4
+	DefaultSlowBurn  = 6
+)
+
+// BurnWindows is the long and short windows of the multi-window burn rate alert,
+// the alert is raised only if the burn rates of both windows reach the threshold,
+// so that the alert is resolved soon after the burning stops
+type BurnWindows struct {
+	Long  RollingWindow
+	Short RollingWindow
+}
+
+// The windows of the fast and slow burn rate alerts
+var (
+	FastBurnWindows = BurnWindows{RollingWindow{"1h", time.Hour}, RollingWindow{"5m", 5 * time.Minute}}
+	SlowBurnWindows = BurnWindows{RollingWindow{"6h", 6 * time.Hour}, RollingWindow{"30m", 30 * time.Minute}}
+)
+
+// SLO is the service level objective of the probe,
+// the error budget is the downtime allowed by the target in the window
+type SLO struct {
+	Target   float64       `yaml:"target,omitempty" json:"target,omitempty" jsonschema:"title=SLO Target,description=the target of the availability in percentage (e.g. 99.9)\\, 0 means no SLO,minimum=0,exclusiveMaximum=100"`
+	Window   time.Duration `yaml:"window,omitempty" json:"window,omitempty" jsonschema:"type=string,format=duration,title=SLO Window,description=the window of the error budget which is 30 days at most,default=720h"`
+	FastBurn float64       `yaml:"fast_burn,omitempty" json:"fast_burn,omitempty" jsonschema:"title=Fast Burn Threshold,description=the burn rate of the last 1h and 5m which raises the fast burn alert,default=14.4"`
+	SlowBurn float64       `yaml:"slow_burn,omitempty" json:"slow_burn,omitempty" jsonschema:"title=Slow Burn Threshold,description=the burn rate of the last 6h and 30m which raises the slow burn alert,default=6"`
+}
+
+// Enabled returns true if the SLO target is set
+func (s SLO) Enabled() bool {
+	return s.Target > 0
+}
+
+// Normalize returns the SLO with the default settings
+func (s SLO) Normalize() SLO {
+	if s.Window <= 0 {
+		s.Window = DefaultSLOWindow
+	}
+	if s.FastBurn <= 0 {
+		s.FastBurn = DefaultFastBurn
+	}
+	if s.SlowBurn <= 0 {
+		s.SlowBurn = DefaultSlowBurn
+	}
+	return s
+}
+
+// Check checks the SLO settings
+func (s SLO) Check() error {
+	if s.Target < 0 || s.Target >= 100 {
+		return fmt.Errorf("invalid SLO target [%v], it should be in (0, 100)", s.Target)
+	}
+	if longest := RollingWindows[len(RollingWindows)-1]; s.Window > longest.Duration {
+		return fmt.Errorf("invalid SLO window [%s], it should not be longer than %s", s.Window, longest.Duration)
+	}
+	return nil
+}
+
+// BurnAlert is the level of the error budget burn rate alert
+type BurnAlert int
+
+// The levels of the burn rate alert
+const (
+	BurnNone BurnAlert = iota
+	BurnSlow
+	BurnFast
+)
+
+var (
+	burnToString = map[BurnAlert]string{
+		BurnNone: "none",
+		BurnSlow: "slow",
+		BurnFast: "fast",
+	}
+	stringToBurn = global.ReverseMap(burnToString)
+)
+
+// String convert the BurnAlert to string
+func (b BurnAlert) String() string {
+	if val, ok := burnToString[b]; ok {
+		return val
+	}
+	return burnToString[BurnNone]
+}
+
+// Title convert the BurnAlert to the title
+func (b BurnAlert) Title() string {
+	if b == BurnNone {
+		return "Error Budget Burn Resolved"
+	}
+	return "Error Budget " + strings.ToUpper(b.String()[:1]) + b.String()[1:] + " Burn"
+}
+
+// Emoji convert the BurnAlert to emoji
+func (b BurnAlert) Emoji() string {
+	switch b {
+	case BurnFast:
+		return "🔥"
+	case BurnSlow:
+		return "⚠️"
+	}
+	return "✅"
+}
+
+// MarshalYAML is marshal the burn alert
+func (b BurnAlert) MarshalYAML() (interface{}, error) {
+	return global.EnumMarshalYaml(burnToString, b, "BurnAlert")
+}
+
+// UnmarshalYAML is unmarshal the burn alert
+func (b *BurnAlert) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return global.EnumUnmarshalYaml(unmarshal, stringToBurn, b, BurnNone, "BurnAlert")
+}
+
+// MarshalJSON is marshal the burn alert
+func (b BurnAlert) MarshalJSON() ([]byte, error) {
+	return global.EnumMarshalJSON(burnToString, b, "BurnAlert")
+}
+
+// UnmarshalJSON is unmarshal the burn alert
+func (b *BurnAlert) UnmarshalJSON(data []byte) error {
+	return global.EnumUnmarshalJSON(data, stringToBurn, b, BurnNone, "BurnAlert")
+}
+
+// SLOStatus is the error budget and the burn rates of the probe
+type SLOStatus struct {
+	Target    float64            `json:"target" yaml:"target"`
+	Window    time.Duration      `json:"window" yaml:"window"`
+	Budget    float64            `json:"budget" yaml:"budget"` // the remaining error budget in percentage, it is negative if the budget is exhausted
+	BurnRates map[string]float64 `json:"burn_rates" yaml:"burn_rates"`
+	Alert     BurnAlert          `json:"alert" yaml:"alert"`
+	PreAlert  BurnAlert          `json:"prealert" yaml:"prealert"`
+}
+
+// Clone returns a copy of the SLOStatus
+func (s *SLOStatus) Clone() *SLOStatus {
+	if s == nil {
+		return nil
+	}
+	dst := *s
+	dst.BurnRates = map[string]float64{}
+	for k, v := range s.BurnRates {
+		dst.BurnRates[k] = v
+	}
+	return &dst
+}
+
+// IsChanged returns true if the burn rate alert is raised, changed or resolved
+func (s *SLOStatus) IsChanged() bool {
+	return s != nil && s.Alert != s.PreAlert
+}
+
+// BurnRate calculates how fast the error budget is consumed in the rolling window before now,
+// 1 means the budget is exactly exhausted at the end of the SLO window
+func (b Buckets) BurnRate(now time.Time, window time.Duration, target float64) float64 {
+	up, down := b.Window(now, window)
+	return burnRate(up, down, target)
+}
+
+// BurnRate calculates how fast the error budget is consumed in the short rolling window before now
+func (b MinuteBuckets) BurnRate(now time.Time, window time.Duration, target float64) float64 {
+	up, down := b.Window(now, window)
+	return burnRate(up, down, target)
+}
+
+// burnRate return the ratio of the downtime to the downtime allowed by the target
+func burnRate(up, down time.Duration, target float64) float64 {
+	if up+down <= 0 || target >= 100 {
+		return 0
+	}
+	return down.Seconds() / (up + down).Seconds() / (1 - target/100)
+}
+
+// UpdateSLO calculates the error budget and the burn rates of the SLO,
+// the previous alert is kept to find out whether the alert is changed
+func (r *Result) UpdateSLO(slo SLO, now time.Time) {
+	if !slo.Enabled() {
+		r.SLO = nil
+		return
+	}
+	s := &SLOStatus{
+		Target:    slo.Target,
+		Window:    slo.Window,
+		Budget:    100,
+		BurnRates: map[string]float64{},
+	}
+	if r.SLO != nil {
+		s.PreAlert = r.SLO.Alert
+	}
+
+	up, down := r.Buckets.Window(now, slo.Window)
+	if allowed := (up + down).Seconds() * (1 - slo.Target/100); allowed > 0 {
+		s.Budget = (1 - down.Seconds()/allowed) * 100
+	}
+
+	burning := func(w BurnWindows, threshold float64) bool {
+		long := r.Buckets.BurnRate(now, w.Long.Duration, slo.Target)
+		// the short window is less than one hour, it's calculated by the minute buckets,
+		// so that the alert is resolved soon after the burning stops
+		short := r.Minutes.BurnRate(now, w.Short.Duration, slo.Target)
+		s.BurnRates[w.Long.Name], s.BurnRates[w.Short.Name] = long, short
+		return long >= threshold && short >= threshold
+	}
+	fast := burning(FastBurnWindows, slo.FastBurn)
+	slow := burning(SlowBurnWindows, slo.SlowBurn)
+	switch {
+	case fast:
+		s.Alert = BurnFast
+	case slow:
+		s.Alert = BurnSlow
+	}
+	r.SLO = s
+}
@@ -0,0 +1,131 @@
+package probe
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSLOConfig(t *testing.T) {
+	var s SLO
+	assert.Nil(t, yaml.Unmarshal([]byte("target: 99.9\nwindow: 168h\nfast_burn: 10\n"), &s))
+	assert.True(t, s.Enabled())
+	s = s.Normalize()
+	assert.Equal(t, SLO{Target: 99.9, Window: 7 * 24 * time.Hour, FastBurn: 10, SlowBurn: DefaultSlowBurn}, s)
+	assert.Nil(t, s.Check())
+
+	assert.False(t, SLO{}.Enabled())
+	assert.Equal(t, DefaultSLOWindow, SLO{Target: 99}.Normalize().Window)
+	assert.Equal(t, DefaultFastBurn, SLO{Target: 99}.Normalize().FastBurn)
+
+	for _, bad := range []SLO{{Target: 100}, {Target: -1}, {Target: 99, Window: 31 * 24 * time.Hour}} {
+		assert.NotNil(t, bad.Check(), bad)
+	}
+}
+
+func TestBurnAlert(t *testing.T) {
+	for _, b := range []BurnAlert{BurnNone, BurnSlow, BurnFast} {
+		buf, err := yaml.Marshal(b)
+		assert.Nil(t, err)
+		var y BurnAlert
+		assert.Nil(t, yaml.Unmarshal(buf, &y))
+		assert.Equal(t, b, y)
+
+		buf, err = json.Marshal(b)
+		assert.Nil(t, err)
+		var j BurnAlert
+		assert.Nil(t, json.Unmarshal(buf, &j))
+		assert.Equal(t, b, j)
+	}
+	assert.Equal(t, "Error Budget Fast Burn", BurnFast.Title())
+	assert.Equal(t, "Error Budget Slow Burn", BurnSlow.Title())
+	assert.Equal(t, "Error Budget Burn Resolved", BurnNone.Title())
+	assert.Equal(t, "none", BurnAlert(10).String())
+	assert.Equal(t, "🔥", BurnFast.Emoji())
+
+	var b BurnAlert
+	assert.NotNil(t, json.Unmarshal([]byte(`"bad"`), &b))
+	assert.Equal(t, BurnNone, b)
+}
+
+func TestUpdateSLO(t *testing.T) {
+	now := time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC)
+	r := NewResult()
+	for i := 29 * 24; i > 0; i-- {
+		r.Buckets.Add(now.Add(-time.Duration(i)*time.Hour), true, time.Hour)
+	}
+	// the probe runs every minute
+	probeAt := func(t time.Time, up bool) {
+		r.Buckets.Add(t, up, time.Minute)
+		r.Minutes.Add(t, up, time.Minute)
+	}
+	for i := 29; i >= 0; i-- {
+		probeAt(now.Add(-time.Duration(i)*time.Minute), false)
+	}
+
+	// no SLO
+	r.UpdateSLO(SLO{}, now)
+	assert.Nil(t, r.SLO)
+	assert.False(t, r.SLO.IsChanged())
+
+	slo := SLO{Target: 99}.Normalize()
+	r.UpdateSLO(slo, now)
+	assert.Equal(t, BurnFast, r.SLO.Alert)
+	assert.Equal(t, BurnNone, r.SLO.PreAlert)
+	assert.True(t, r.SLO.IsChanged())
+	assert.InDelta(t, (1-0.5/(696.5*0.01))*100, r.SLO.Budget, 1e-9)
+	assert.InDelta(t, 50, r.SLO.BurnRates["1h"], 1e-9)
+	assert.InDelta(t, 100, r.SLO.BurnRates["5m"], 1e-9)
+	assert.InDelta(t, 0.5/6*100, r.SLO.BurnRates["6h"], 1e-9)
+	assert.InDelta(t, 100, r.SLO.BurnRates["30m"], 1e-9)
+
+	// the alert is kept
+	r.UpdateSLO(slo, now)
+	assert.Equal(t, BurnFast, r.SLO.PreAlert)
+	assert.False(t, r.SLO.IsChanged())
+
+	c := r.Clone()
+	c.SLO.BurnRates["1h"] = 0
+	assert.InDelta(t, 50, r.SLO.BurnRates["1h"], 1e-9)
+
+	// only the slow burn threshold is reached
+	r.SLO = nil
+	r.UpdateSLO(SLO{Target: 99, FastBurn: 200}.Normalize(), now)
+	assert.Equal(t, BurnSlow, r.SLO.Alert)
+
+	// the burning stops, the short windows are recovered at first
+	r.UpdateSLO(slo, now)
+	for i := 1; i <= 40; i++ {
+		probeAt(now.Add(time.Duration(i)*time.Minute), true)
+		if i == 10 {
+			// the fast burn alert is resolved though the 1h window is still burning
+			r.UpdateSLO(slo, now.Add(10*time.Minute))
+			assert.Equal(t, BurnSlow, r.SLO.Alert)
+			assert.Equal(t, BurnFast, r.SLO.PreAlert)
+			assert.Equal(t, float64(0), r.SLO.BurnRates["5m"])
+			assert.Greater(t, r.SLO.BurnRates["1h"], slo.FastBurn)
+		}
+	}
+	later := now.Add(40 * time.Minute)
+	r.UpdateSLO(slo, later)
+	assert.Equal(t, BurnNone, r.SLO.Alert)
+	assert.Equal(t, BurnSlow, r.SLO.PreAlert)
+	assert.True(t, r.SLO.IsChanged())
+	assert.Equal(t, float64(0), r.SLO.BurnRates["30m"])
+	assert.Greater(t, r.SLO.BurnRates["6h"], slo.SlowBurn)
+
+	c = r.DeepClone()
+	assert.Equal(t, r.Minutes, c.Minutes)
+	c.Minutes[0].DownTime = 0
+	assert.NotEqual(t, r.Minutes[0].DownTime, c.Minutes[0].DownTime)
+	assert.Nil(t, r.Clone().Minutes)
+
+	// no data in the window
+	r = NewResult()
+	r.UpdateSLO(slo, now)
+	assert.Equal(t, float64(100), r.SLO.Budget)
+	assert.Equal(t, float64(0), r.SLO.BurnRates["1h"])
+}
@@ -0,0 +1,167 @@
+package probe
+
+import (
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+)
+
+// DailyUptime is the uptime and downtime of one day in the global time zone
+type DailyUptime struct {
+	Date     string        `json:"date" yaml:"date"`
+	UpTime   time.Duration `json:"uptime" yaml:"uptime"`
+	DownTime time.Duration `json:"downtime" yaml:"downtime"`
+}
+
+// Percent returns the uptime percentage of the day, -1 is returned if there is no data
+func (d DailyUptime) Percent() float64 {
+	total := d.UpTime + d.DownTime
+	if total <= 0 {
+		return -1
+	}
+	return float64(d.UpTime) / float64(total) * 100
+}
+
+// Daily is the daily uptime of the recent days in chronological order
+type Daily []DailyUptime
+
+// DateOf returns the date of the time in the global time zone
+func DateOf(t time.Time) string {
+	return t.In(global.GetTimeLocation()).Format("2006-01-02")
+}
+
+// Add adds the duration into the day of the time, only the recent days are kept
+func (d *Daily) Add(t time.Time, up bool, dur time.Duration) {
+	date := DateOf(t)
+	if n := len(*d); n == 0 || (*d)[n-1].Date != date {
+		*d = append(*d, DailyUptime{Date: date})
+	}
+	day := &(*d)[len(*d)-1]
+	if up {
+		day.UpTime += dur
+	} else {
+		day.DownTime += dur
+	}
+	if len(*d) > global.DefaultUptimeDays {
+		*d = (*d)[len(*d)-global.DefaultUptimeDays:]
+	}
+}
+
+// Get returns the uptime of the date
+func (d Daily) Get(date string) (DailyUptime, bool) {
+	for i := len(d) - 1; i >= 0; i-- {
+		if d[i].Date == date {
+			return d[i], true
+		}
+	}
+	return DailyUptime{Date: date}, false
+}
+
+// Between returns the total uptime and downtime of the days in [from, to], the dates are "2006-01-02"
+func (d Daily) Between(from, to string) (time.Duration, time.Duration) {
+	var up, down time.Duration
+	for _, day := range d {
+		if day.Date >= from && day.Date <= to {
+			up += day.UpTime
+			down += day.DownTime
+		}
+	}
+	return up, down
+}
+
+// Clone returns a copy of the Daily
+func (d Daily) Clone() Daily {
+	if d == nil {
+		return nil
+	}
+	return append(Daily{}, d...)
+}
+
+// Incident is the period which the probe is not up
+type Incident struct {
+	Start   time.Time `json:"start" yaml:"start"`
+	End     time.Time `json:"end,omitempty" yaml:"end,omitempty"` // zero if the incident is ongoing
+	Status  Status    `json:"status" yaml:"status"`
+	Message string    `json:"message" yaml:"message"`
+}
+
+// Ongoing returns true if the incident is not resolved
+func (i Incident) Ongoing() bool {
+	return i.End.IsZero()
+}
+
+// Duration returns the duration of the incident, the ongoing incident is calculated until now
+func (i Incident) Duration(now time.Time) time.Duration {
+	if i.Ongoing() {
+		return now.Sub(i.Start)
+	}
+	return i.End.Sub(i.Start)
+}
+
+// Incidents is the incident timeline of the probe in chronological order
+type Incidents []Incident
+
+// maxIncidents is the max number of the incidents kept for one probe
+const maxIncidents = 100
+
+// Update updates the timeline with the current status,
+// an incident starts when the probe is not up, and it is resolved when the probe is up again
+func (in *Incidents) Update(t time.Time, status Status, message string) {
+	n := len(*in)
+	ongoing := n > 0 && (*in)[n-1].Ongoing()
+	switch {
+	case status == StatusUp && ongoing:
+		(*in)[n-1].End = t
+	case status != StatusUp && status != StatusInit && !ongoing:
+		*in = append(*in, Incident{Start: t, Status: status, Message: message})
+	}
+
+	// remove the incidents which are resolved before the recent days
+	expired := t.AddDate(0, 0, -global.DefaultUptimeDays)
+	i := 0
+	for i < len(*in) && !(*in)[i].Ongoing() && (*in)[i].End.Before(expired) {
+		i++
+	}
+	if len(*in)-i > maxIncidents {
+		i = len(*in) - maxIncidents
+	}
+	if i > 0 {
+		*in = append(Incidents{}, (*in)[i:]...)
+	}
+}
+
+// Between returns the number of the incidents which overlap the period [from, to),
+// and the longest duration of them within the period, the ongoing incident is calculated until now
+func (in Incidents) Between(from, to, now time.Time) (int, time.Duration) {
+	count := 0
+	var longest time.Duration
+	for _, i := range in {
+		end := i.End
+		if i.Ongoing() {
+			end = now
+		}
+		start := i.Start
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !start.Before(end) {
+			continue
+		}
+		count++
+		if d := end.Sub(start); d > longest {
+			longest = d
+		}
+	}
+	return count, longest
+}
+
+// Clone returns a copy of the Incidents
+func (in Incidents) Clone() Incidents {
+	if in == nil {
+		return nil
+	}
+	return append(Incidents{}, in...)
+}
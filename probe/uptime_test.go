@@ -0,0 +1,104 @@
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaily(t *testing.T) {
+	var d Daily
+	assert.Nil(t, d.Clone())
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, global.GetTimeLocation())
+	d.Add(now, true, 3*time.Minute)
+	d.Add(now, false, time.Minute)
+	assert.Equal(t, 1, len(d))
+	assert.Equal(t, float64(75), d[0].Percent())
+
+	day, ok := d.Get(DateOf(now))
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, day.DownTime)
+	day, ok = d.Get("2000-01-01")
+	assert.False(t, ok)
+	assert.Equal(t, float64(-1), day.Percent())
+
+	// only the recent days are kept
+	for i := 1; i <= global.DefaultUptimeDays; i++ {
+		d.Add(now.AddDate(0, 0, i), true, time.Minute)
+	}
+	assert.Equal(t, global.DefaultUptimeDays, len(d))
+	assert.Equal(t, DateOf(now.AddDate(0, 0, 1)), d[0].Date)
+
+	c := d.Clone()
+	c[0].UpTime = 0
+	assert.Equal(t, time.Minute, d[0].UpTime)
+}
+
+func TestIncidents(t *testing.T) {
+	var in Incidents
+	assert.Nil(t, in.Clone())
+
+	now := time.Now()
+	in.Update(now, StatusInit, "init")
+	in.Update(now, StatusUp, "up")
+	assert.Equal(t, 0, len(in))
+
+	in.Update(now, StatusDown, "down")
+	in.Update(now.Add(time.Minute), StatusDown, "still down")
+	assert.Equal(t, 1, len(in))
+	assert.True(t, in[0].Ongoing())
+	assert.Equal(t, "down", in[0].Message)
+	assert.Equal(t, 2*time.Minute, in[0].Duration(now.Add(2*time.Minute)))
+
+	in.Update(now.Add(3*time.Minute), StatusUp, "up")
+	assert.False(t, in[0].Ongoing())
+	assert.Equal(t, 3*time.Minute, in[0].Duration(now.Add(time.Hour)))
+
+	// the expired incidents are removed
+	later := now.AddDate(0, 0, global.DefaultUptimeDays+1)
+	in.Update(later, StatusDown, "down again")
+	assert.Equal(t, 1, len(in))
+	assert.Equal(t, "down again", in[0].Message)
+
+	// the number of the incidents is limited
+	for i := 0; i < maxIncidents+10; i++ {
+		in.Update(later.Add(time.Duration(i)*time.Second), StatusUp, "up")
+		in.Update(later.Add(time.Duration(i)*time.Second), StatusDown, "down")
+	}
+	assert.Equal(t, maxIncidents, len(in))
+}
+
+func TestBetween(t *testing.T) {
+	d := Daily{
+		{Date: "2022-06-01", UpTime: time.Hour, DownTime: time.Minute},
+		{Date: "2022-06-02", UpTime: 2 * time.Hour},
+		{Date: "2022-07-01", DownTime: time.Hour},
+	}
+	up, down := d.Between("2022-06-01", "2022-06-30")
+	assert.Equal(t, 3*time.Hour, up)
+	assert.Equal(t, time.Minute, down)
+	up, down = d.Between("2022-08-01", "2022-08-31")
+	assert.Equal(t, time.Duration(0), up+down)
+
+	base := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	in := Incidents{
+		{Start: base.Add(-time.Hour), End: base.Add(time.Hour), Status: StatusDown},
+		{Start: base.Add(2 * time.Hour), End: base.Add(2*time.Hour + time.Minute), Status: StatusDown},
+		{Start: base.Add(5 * time.Hour), Status: StatusDown},
+	}
+	now := base.Add(8 * time.Hour)
+	count, longest := in.Between(base, base.Add(24*time.Hour), now)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 3*time.Hour, longest) // the ongoing incident until now
+
+	count, longest = in.Between(base, base.Add(3*time.Hour), now)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, time.Hour, longest) // the first incident is clipped by the period
+
+	count, longest = in.Between(base.Add(-24*time.Hour), base.Add(-2*time.Hour), now)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, time.Duration(0), longest)
+}
@@ -0,0 +1,216 @@
+package probe
+
+import (
+	"time"
+)
+
+// RollingWindow is the rolling window of the SLA, e.g. the last 30 days
+type RollingWindow struct {
+	Name     string
+	Duration time.Duration
+}
+
+// RollingWindows are the rolling windows of the SLA which are reported, the longest is the last
+var RollingWindows = []RollingWindow{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// BucketSize is the time span of one availability bucket
+const BucketSize = time.Hour
+
+// Bucket is the uptime and downtime of the probe in one hour
+type Bucket struct {
+	Start    time.Time     `json:"start" yaml:"start"`
+	UpTime   time.Duration `json:"uptime" yaml:"uptime"`
+	DownTime time.Duration `json:"downtime" yaml:"downtime"`
+}
+
+// Buckets is the hourly availability of the probe in chronological order,
+// only the buckets of the longest rolling window are kept
+type Buckets []Bucket
+
+// Add adds the duration into the bucket of the time
+func (b *Buckets) Add(t time.Time, up bool, dur time.Duration) {
+	*b = addBucket(*b, t, up, dur, BucketSize, RollingWindows[len(RollingWindows)-1].Duration)
+}
+
+// Window returns the uptime and downtime in the rolling window before now,
+// the bucket which is partially in the window is counted in proportion
+func (b Buckets) Window(now time.Time, window time.Duration) (time.Duration, time.Duration) {
+	return b.Between(now.Add(-window), now, now)
+}
+
+// Between returns the uptime and downtime in the period [from, to),
+// the bucket which is partially in the period is counted in proportion,
+// and the current bucket is regarded as filled until now
+func (b Buckets) Between(from, to, now time.Time) (time.Duration, time.Duration) {
+	return bucketsBetween(b, from, to, now, BucketSize)
+}
+
+// MinuteBucketSize is the time span of one bucket of the short burn rate windows
+const MinuteBucketSize = time.Minute
+
+// MinuteBucketsKeep is how long the minute buckets are kept, it covers the short burn rate windows
+const MinuteBucketsKeep = time.Hour
+
+// MinuteBuckets is the availability of the probe by minute in chronological order,
+// it's used by the short windows of the burn rate alerts which are shorter than one hourly bucket
+type MinuteBuckets []Bucket
+
+// Add adds the duration into the bucket of the time
+func (b *MinuteBuckets) Add(t time.Time, up bool, dur time.Duration) {
+	*b = addBucket(*b, t, up, dur, MinuteBucketSize, MinuteBucketsKeep)
+}
+
+// Window returns the uptime and downtime in the rolling window before now,
+// the bucket which is partially in the window is counted in proportion
+func (b MinuteBuckets) Window(now time.Time, window time.Duration) (time.Duration, time.Duration) {
+	return bucketsBetween(b, now.Add(-window), now, now, MinuteBucketSize)
+}
+
+// Clone returns a copy of the MinuteBuckets
+func (b MinuteBuckets) Clone() MinuteBuckets {
+	if b == nil {
+		return nil
+	}
+	return append(MinuteBuckets{}, b...)
+}
+
+// addBucket adds the duration into the bucket of the time,
+// and removes the buckets which end before the `keep` duration
+func addBucket[T ~[]Bucket](b T, t time.Time, up bool, dur, size, keep time.Duration) T {
+	start := t.Truncate(size)
+	if n := len(b); n == 0 || b[n-1].Start.Before(start) {
+		b = append(b, Bucket{Start: start})
+	}
+	bucket := &b[len(b)-1]
+	if up {
+		bucket.UpTime += dur
+	} else {
+		bucket.DownTime += dur
+	}
+
+	expired := start.Add(-keep)
+	i := 0
+	for i < len(b) && !b[i].Start.Add(size).After(expired) {
+		i++
+	}
+	if i > 0 {
+		b = append(T{}, b[i:]...)
+	}
+	return b
+}
+
+// bucketsBetween returns the uptime and downtime of the buckets in the period [from, to),
+// the bucket which is partially in the period is counted in proportion
+func bucketsBetween[T ~[]Bucket](b T, from, to, now time.Time, size time.Duration) (time.Duration, time.Duration) {
+	var up, down time.Duration
+	for i := len(b) - 1; i >= 0; i-- {
+		start, end := b[i].Start, b[i].Start.Add(size)
+		if !end.After(from) {
+			break
+		}
+		if !start.Before(to) {
+			continue
+		}
+		if !start.Before(from) && !end.After(to) {
+			up += b[i].UpTime
+			down += b[i].DownTime
+			continue
+		}
+		// the current bucket is only filled until now
+		if end.After(now) {
+			end = now
+		}
+		lo, hi := maxTime(start, from), minTime(end, to)
+		if !hi.After(lo) || !end.After(start) {
+			continue
+		}
+		ratio := float64(hi.Sub(lo)) / float64(end.Sub(start))
+		up += time.Duration(float64(b[i].UpTime) * ratio)
+		down += time.Duration(float64(b[i].DownTime) * ratio)
+	}
+	return up, down
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// Clone returns a copy of the Buckets
+func (b Buckets) Clone() Buckets {
+	if b == nil {
+		return nil
+	}
+	return append(Buckets{}, b...)
+}
+
+// SLAWindows is the SLA percentage of the rolling windows, the key is the name of the window
+type SLAWindows map[string]float64
+
+// Clone returns a copy of the SLAWindows
+func (w SLAWindows) Clone() SLAWindows {
+	if w == nil {
+		return nil
+	}
+	dst := SLAWindows{}
+	for k, v := range w {
+		dst[k] = v
+	}
+	return dst
+}
+
+// WindowSLA calculates the SLA percentage in the rolling window before now,
+// it is the same as SLAPercent() if there is no data in the window
+func (r *Result) WindowSLA(now time.Time, window time.Duration) float64 {
+	up, down := r.Buckets.Window(now, window)
+	if up+down <= 0 {
+		return r.SLAPercent()
+	}
+	return up.Seconds() / (up + down).Seconds() * 100
+}
+
+// UptimeBetween returns the uptime and downtime in the period [from, to),
+// the hourly buckets are used for the last 30 days, so the resolution is one hour,
+// and the daily uptime is used before the buckets, so the earlier days are counted in whole days
+func (r *Result) UptimeBetween(from, to, now time.Time) (time.Duration, time.Duration) {
+	split := to
+	if len(r.Buckets) > 0 && r.Buckets[0].Start.Before(to) {
+		split = maxTime(from, r.Buckets[0].Start)
+	}
+	up, down := r.Buckets.Between(split, to, now)
+	if !from.Before(split) {
+		return up, down
+	}
+	// the days before the buckets, the day of the first bucket is counted by the buckets
+	first, last := DateOf(from), DateOf(split.Add(-time.Nanosecond))
+	for _, d := range r.Daily {
+		if d.Date < first || d.Date > last || (split != to && d.Date == DateOf(split)) {
+			continue
+		}
+		up += d.UpTime
+		down += d.DownTime
+	}
+	return up, down
+}
+
+// UpdateWindows calculates the SLA of all of the rolling windows
+func (r *Result) UpdateWindows(now time.Time) {
+	r.Windows = SLAWindows{}
+	for _, w := range RollingWindows {
+		r.Windows[w.Name] = r.WindowSLA(now, w.Duration)
+	}
+}
@@ -0,0 +1,109 @@
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuckets(t *testing.T) {
+	now := time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC)
+	var b Buckets
+	b.Add(now.Add(-2*time.Hour), true, 60*time.Minute)
+	b.Add(now.Add(-time.Hour), true, 30*time.Minute)
+	b.Add(now.Add(-time.Hour), false, 30*time.Minute)
+	b.Add(now, true, 30*time.Minute)
+	assert.Equal(t, 3, len(b))
+	assert.Equal(t, time.Date(2022, 6, 15, 9, 0, 0, 0, time.UTC), b[1].Start)
+
+	// the current bucket and the half of the previous one
+	up, down := b.Window(now, time.Hour)
+	assert.Equal(t, 45*time.Minute, up)
+	assert.Equal(t, 15*time.Minute, down)
+
+	up, down = b.Window(now, 24*time.Hour)
+	assert.Equal(t, 120*time.Minute, up)
+	assert.Equal(t, 30*time.Minute, down)
+
+	up, down = b.Window(now.Add(48*time.Hour), 24*time.Hour)
+	assert.Equal(t, time.Duration(0), up+down)
+
+	// the buckets out of the longest window are removed
+	b.Add(now.Add(30*24*time.Hour), true, time.Minute)
+	assert.Equal(t, 2, len(b))
+	assert.Equal(t, now.Truncate(time.Hour), b[0].Start)
+
+	c := b.Clone()
+	c[0].UpTime = 0
+	assert.NotEqual(t, c[0].UpTime, b[0].UpTime)
+	assert.Nil(t, Buckets(nil).Clone())
+}
+
+func TestWindowSLA(t *testing.T) {
+	now := time.Now()
+	r := NewResult()
+	r.Status = StatusUp
+	assert.Equal(t, float64(100), r.WindowSLA(now, time.Hour))
+	r.Status = StatusDown
+	assert.Equal(t, float64(0), r.WindowSLA(now, time.Hour))
+
+	// no data in the window, e.g. in the maintenance which is excluded from the SLA
+	r.Stat.UpTime, r.Stat.DownTime = 3*time.Hour, time.Hour
+	assert.Equal(t, r.SLAPercent(), r.WindowSLA(now, time.Hour))
+	assert.Equal(t, float64(75), r.WindowSLA(now, time.Hour))
+	r.Stat.UpTime, r.Stat.DownTime = 0, 0
+
+	r.Buckets.Add(now.Add(-3*24*time.Hour), false, time.Hour)
+	r.Buckets.Add(now, true, time.Hour)
+	r.UpdateWindows(now)
+	assert.Equal(t, SLAWindows{"1h": 100, "24h": 100, "7d": 50, "30d": 50}, r.Windows)
+
+	c := r.Clone()
+	c.Windows["1h"] = 0
+	assert.Equal(t, float64(100), r.Windows["1h"])
+	assert.Nil(t, c.Buckets)
+	assert.Equal(t, r.Buckets, r.DeepClone().Buckets)
+	assert.Nil(t, SLAWindows(nil).Clone())
+}
+
+func TestCurrentBucketWindow(t *testing.T) {
+	now := time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC)
+	var b Buckets
+	b.Add(now, true, 20*time.Minute)
+	b.Add(now, false, 10*time.Minute)
+
+	// the current bucket is only filled until now
+	up, down := b.Window(now, 15*time.Minute)
+	assert.Equal(t, 10*time.Minute, up)
+	assert.Equal(t, 5*time.Minute, down)
+}
+
+func TestUptimeBetween(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	now := time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC)
+	r := NewResult()
+	r.Daily = Daily{
+		{Date: "2022-06-13", UpTime: 20 * time.Hour, DownTime: 4 * time.Hour},
+		{Date: "2022-06-14", UpTime: 24 * time.Hour},
+	}
+	r.Buckets.Add(time.Date(2022, 6, 14, 12, 0, 0, 0, time.UTC), true, time.Hour)
+	r.Buckets.Add(now.Add(-time.Hour), false, time.Hour)
+	r.Buckets.Add(now, true, 30*time.Minute)
+
+	// the partial hours are counted in proportion
+	up, down := r.UptimeBetween(now.Add(-time.Hour), now, now)
+	assert.Equal(t, 30*time.Minute, up)
+	assert.Equal(t, 30*time.Minute, down)
+
+	// the whole days before the buckets, the day of the first bucket is counted by the buckets
+	up, down = r.UptimeBetween(time.Date(2022, 6, 13, 0, 0, 0, 0, time.UTC), now, now)
+	assert.Equal(t, 20*time.Hour+90*time.Minute, up)
+	assert.Equal(t, 5*time.Hour, down)
+
+	// no buckets in the period
+	up, down = r.UptimeBetween(time.Date(2022, 6, 13, 0, 0, 0, 0, time.UTC), time.Date(2022, 6, 14, 0, 0, 0, 0, time.UTC), now)
+	assert.Equal(t, 20*time.Hour, up)
+	assert.Equal(t, 4*time.Hour, down)
+}
@@ -0,0 +1,362 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// Color is the RGB color of the status which is used by the chat platforms
+type Color int
+
+var statusColor = map[probe.Status]Color{
+	probe.StatusInit:    0x3AA3E3, // blue
+	probe.StatusUp:      0x36A64F, // green
+	probe.StatusDown:    0xE01E5A, // red
+	probe.StatusUnknown: 0xECB22E, // yellow
+	probe.StatusBad:     0x9E9E9E, // gray
+}
+
+// StatusColor return the color of the status
+func StatusColor(s probe.Status) Color {
+	if c, ok := statusColor[s]; ok {
+		return c
+	}
+	return statusColor[probe.StatusUnknown]
+}
+
+// Hex return the hex string of the color, e.g. "#36A64F"
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%06X", int(c))
+}
+
+// Field is the key-value pair of the result which is shown in the chat message
+type Field struct {
+	Name  string
+	Value string
+}
+
+// ResultFields return the fields of the result, all of the chat formats share the same fields
+func ResultFields(r probe.Result) []Field {
+	return []Field{
+		{"Endpoint", r.Endpoint},
+		{"Status", r.Status.Emoji() + " " + r.Status.Title()},
+		{"Round Trip Time", r.RoundTripTime.Round(time.Millisecond).String()},
+		{"SLA", SLAText(r)},
+		{"Time", FormatTime(r.StartTime)},
+	}
+}
+
+// SLAText return the SLA percentage with the rolling windows, e.g. "99.50% (1h 100.00%, 24h 99.90%)"
+func SLAText(r probe.Result) string {
+	text := fmt.Sprintf("%.2f%%", r.SLAPercent())
+	list := []string{}
+	for _, w := range probe.RollingWindows {
+		if v, ok := r.Windows[w.Name]; ok {
+			list = append(list, fmt.Sprintf("%s %.2f%%", w.Name, v))
+		}
+	}
+	if len(list) > 0 {
+		text += " (" + strings.Join(list, ", ") + ")"
+	}
+	return text
+}
+
+// ResultTitle return the title of the result with the status emoji
+func ResultTitle(r probe.Result) string {
+	return r.Status.Emoji() + " " + r.Title()
+}
+
+func toJSONString(v interface{}) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		log.Errorf("error: %v", err)
+		return ""
+	}
+	return string(buf)
+}
+
+// ToMarkdown convert the result object to markdown
+func ToMarkdown(r probe.Result) string {
+	return markdownCard(ResultTitle(r), ResultFields(r), r.Message)
+}
+
+func markdownCard(title string, fields []Field, message string) string {
+	var sb strings.Builder
+	sb.WriteString("**" + title + "**\n\n")
+	for _, f := range fields {
+		sb.WriteString("- **" + f.Name + "**: " + f.Value + "\n")
+	}
+	sb.WriteString("\n> " + message + "\n\n")
+	sb.WriteString("_" + global.FooterString() + "_")
+	return sb.String()
+}
+
+// ----------------------------------------------------------------------------
+//                                   Slack
+// ----------------------------------------------------------------------------
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// SlackEscape escape the special chars of the Slack mrkdwn
+func SlackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// ToSlack convert the result object to the Slack message with blocks
+func ToSlack(r probe.Result) string {
+	return ToSlackCard(r, ResultTitle(r), r.Message)
+}
+
+// ToSlackCard convert the result object to the Slack message with the title and message, e.g. rendered by the templates
+func ToSlackCard(r probe.Result, title, message string) string {
+	return slackCard(title, r.Status, ResultFields(r), message)
+}
+
+func slackCard(title string, status probe.Status, fields []Field, message string) string {
+	texts := []slackText{}
+	for _, f := range fields {
+		name := "*" + f.Name + "*\n"
+		texts = append(texts, slackText{"mrkdwn", name + truncateEscaped(f.Value, slackFieldLimit-textLen(name), SlackEscape)})
+	}
+	msg := slackMessage{
+		Text: truncateEscaped(title, slackSectionLimit, SlackEscape),
+		Attachments: []slackAttachment{{
+			Color: StatusColor(status).Hex(),
+			Blocks: []slackBlock{
+				{Type: "header", Text: &slackText{"plain_text", truncate(title, slackHeaderLimit)}},
+				{Type: "section", Fields: texts},
+				{Type: "section", Text: &slackText{"mrkdwn", "```" + truncateEscaped(message, slackSectionLimit-6, SlackEscape) + "```"}},
+				{Type: "context", Elements: []slackText{{"mrkdwn", SlackEscape(global.FooterString())}}},
+			},
+		}},
+	}
+	return toJSONString(msg)
+}
+
+// ----------------------------------------------------------------------------
+//                                  Discord
+// ----------------------------------------------------------------------------
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text    string `json:"text"`
+	IconURL string `json:"icon_url,omitempty"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields"`
+	Footer      discordFooter  `json:"footer"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordMessage struct {
+	Username  string         `json:"username"`
+	AvatarURL string         `json:"avatar_url,omitempty"`
+	Embeds    []discordEmbed `json:"embeds"`
+}
+
+// ToDiscord convert the result object to the Discord message with embeds
+func ToDiscord(r probe.Result) string {
+	return ToDiscordCard(r, ResultTitle(r), r.Message)
+}
+
+// ToDiscordCard convert the result object to the Discord message with the title and message, e.g. rendered by the templates
+func ToDiscordCard(r probe.Result, title, message string) string {
+	return discordCard(title, r.Status, ResultFields(r), message, r.StartTime)
+}
+
+func discordCard(title string, status probe.Status, fields []Field, message string, t time.Time) string {
+	e := global.GetEaseProbe()
+	title = truncate(title, discordTitleLimit)
+	size := textLen(title) + textLen(global.FooterString())
+	embedFields := []discordField{}
+	for _, f := range fields {
+		f := discordField{truncate(f.Name, discordFieldNameLimit), truncate(f.Value, discordFieldValueLimit), true}
+		size += textLen(f.Name) + textLen(f.Value)
+		embedFields = append(embedFields, f)
+	}
+	// the message is in the rest of the total characters of the embed
+	limit := min(discordDescriptionLimit, discordEmbedLimit-size) - 6
+	msg := discordMessage{
+		Username:  e.Name,
+		AvatarURL: e.IconURL,
+		Embeds: []discordEmbed{{
+			Title:       title,
+			Description: "```" + truncate(message, limit) + "```",
+			Color:       int(StatusColor(status)),
+			Fields:      embedFields,
+			Footer:      discordFooter{global.FooterString(), e.IconURL},
+			Timestamp:   t.UTC().Format(time.RFC3339),
+		}},
+	}
+	return toJSONString(msg)
+}
+
+// ----------------------------------------------------------------------------
+//                             Microsoft Teams
+// ----------------------------------------------------------------------------
+
+var teamsColor = map[probe.Status]string{
+	probe.StatusInit:    "Accent",
+	probe.StatusUp:      "Good",
+	probe.StatusDown:    "Attention",
+	probe.StatusUnknown: "Warning",
+	probe.StatusBad:     "Warning",
+}
+
+var teamsStyle = map[probe.Status]string{
+	probe.StatusInit:    "accent",
+	probe.StatusUp:      "good",
+	probe.StatusDown:    "attention",
+	probe.StatusUnknown: "warning",
+	probe.StatusBad:     "warning",
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type teamsElement struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	Size     string         `json:"size,omitempty"`
+	Weight   string         `json:"weight,omitempty"`
+	Color    string         `json:"color,omitempty"`
+	Style    string         `json:"style,omitempty"`
+	Wrap     bool           `json:"wrap,omitempty"`
+	IsSubtle bool           `json:"isSubtle,omitempty"`
+	Bleed    bool           `json:"bleed,omitempty"`
+	Facts    []teamsFact    `json:"facts,omitempty"`
+	Items    []teamsElement `json:"items,omitempty"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+// ToTeams convert the result object to the Microsoft Teams message with adaptive card
+func ToTeams(r probe.Result) string {
+	return ToTeamsCard(r, ResultTitle(r), r.Message)
+}
+
+// ToTeamsCard convert the result object to the Microsoft Teams message with the title and message, e.g. rendered by the templates
+func ToTeamsCard(r probe.Result, title, message string) string {
+	return teamsCardMessage(title, r.Status, ResultFields(r), message)
+}
+
+func teamsCardMessage(title string, status probe.Status, fields []Field, message string) string {
+	facts := []teamsFact{}
+	for _, f := range fields {
+		facts = append(facts, teamsFact{f.Name, f.Value})
+	}
+	msg := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body: []teamsElement{
+					{Type: "Container", Style: teamsStyle[status], Bleed: true, Items: []teamsElement{
+						{Type: "TextBlock", Text: title, Size: "Large", Weight: "Bolder",
+							Color: teamsColor[status], Wrap: true},
+					}},
+					{Type: "FactSet", Facts: facts},
+					{Type: "TextBlock", Text: message, Wrap: true},
+					{Type: "TextBlock", Text: global.FooterString(), Size: "Small", IsSubtle: true},
+				},
+			},
+		}},
+	}
+	return toJSONString(msg)
+}
+
+// ----------------------------------------------------------------------------
+//                                 Telegram
+// ----------------------------------------------------------------------------
+
+var telegramEscaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// telegramCodeEscaper escape the special chars in the Telegram MarkdownV2 code block
+var telegramCodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+// TelegramEscape escape the special chars of the Telegram MarkdownV2
+func TelegramEscape(s string) string {
+	return telegramEscaper.Replace(s)
+}
+
+// ToTelegram convert the result object to the Telegram MarkdownV2 message
+func ToTelegram(r probe.Result) string {
+	return ToTelegramCard(r, ResultTitle(r), r.Message)
+}
+
+// ToTelegramCard convert the result object to the Telegram MarkdownV2 message with the title and message, e.g. rendered by the templates
+func ToTelegramCard(r probe.Result, title, message string) string {
+	return telegramCard(title, ResultFields(r), message)
+}
+
+func telegramCard(title string, fields []Field, message string) string {
+	var sb strings.Builder
+	sb.WriteString("*" + truncateEscaped(title, telegramLineLimit, TelegramEscape) + "*\n\n")
+	for _, f := range fields {
+		sb.WriteString("*" + TelegramEscape(f.Name) + "*: " + truncateEscaped(f.Value, telegramLineLimit, TelegramEscape) + "\n")
+	}
+	footer := "\n```\n_" + TelegramEscape(global.FooterString()) + "_"
+	// the message is in the rest of the characters of the Telegram message
+	limit := telegramTextLimit - textLen(sb.String()) - textLen("\n```\n") - textLen(footer)
+	sb.WriteString("\n```\n" + truncateEscaped(message, limit, telegramCodeEscaper.Replace) + footer)
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusColor(t *testing.T) {
+	assert.Equal(t, "#36A64F", StatusColor(probe.StatusUp).Hex())
+	assert.Equal(t, "#E01E5A", StatusColor(probe.StatusDown).Hex())
+	assert.Equal(t, StatusColor(probe.StatusUnknown), StatusColor(probe.Status(100)))
+	assert.NotEqual(t, StatusColor(probe.StatusInit), StatusColor(probe.StatusBad))
+}
+
+func TestResultFields(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	r := newResult()
+	fields := ResultFields(r)
+	assert.Equal(t, 5, len(fields))
+	assert.Equal(t, Field{"Endpoint", "http://example.com"}, fields[0])
+	assert.Equal(t, Field{"Status", "❌ Error"}, fields[1])
+	assert.Equal(t, Field{"Round Trip Time", "1ms"}, fields[2])
+	assert.Equal(t, Field{"Time", "2022-01-01 00:00:00"}, fields[4])
+	assert.Equal(t, "❌ Test Name Failure", ResultTitle(r))
+
+	assert.Equal(t, "0.00%", SLAText(r))
+	r.Windows = probe.SLAWindows{"30d": 99.5, "1h": 100, "unknown": 1}
+	assert.Equal(t, "0.00% (1h 100.00%, 30d 99.50%)", SLAText(r))
+	assert.Equal(t, Field{"SLA", SLAText(r)}, ResultFields(r)[3])
+}
+
+func TestToMarkdown(t *testing.T) {
+	r := newResult()
+	str := ToMarkdown(r)
+	assert.True(t, strings.HasPrefix(str, "**❌ Test Name Failure**"))
+	assert.Contains(t, str, "- **Endpoint**: http://example.com")
+	assert.Contains(t, str, "> Error (http): timeout")
+	assert.Equal(t, str, FormatFuncs[Markdown].ResultFn(r))
+}
+
+func TestToSlack(t *testing.T) {
+	r := newResult()
+	r.Message = "<script> & more"
+	str := ToSlack(r)
+	m := slackMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(str), &m))
+	assert.Equal(t, "❌ Test Name Failure", m.Text)
+	assert.Equal(t, 1, len(m.Attachments))
+	assert.Equal(t, "#E01E5A", m.Attachments[0].Color)
+	blocks := m.Attachments[0].Blocks
+	assert.Equal(t, "header", blocks[0].Type)
+	assert.Equal(t, 5, len(blocks[1].Fields))
+	assert.Contains(t, blocks[2].Text.Text, "&lt;script&gt; &amp; more")
+	assert.Equal(t, str, FormatFuncs[Slack].ResultFn(r))
+}
+
+func TestToDiscord(t *testing.T) {
+	global.InitEaseProbe("EaseProbe", "icon")
+	r := newResult()
+	str := ToDiscord(r)
+	m := discordMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(str), &m))
+	assert.Equal(t, "EaseProbe", m.Username)
+	assert.Equal(t, "icon", m.AvatarURL)
+	assert.Equal(t, 1, len(m.Embeds))
+	assert.Equal(t, 0xE01E5A, m.Embeds[0].Color)
+	assert.Equal(t, "❌ Test Name Failure", m.Embeds[0].Title)
+	assert.Equal(t, "2022-01-01T00:00:00Z", m.Embeds[0].Timestamp)
+	assert.Equal(t, 5, len(m.Embeds[0].Fields))
+	assert.Equal(t, str, FormatFuncs[Discord].ResultFn(r))
+}
+
+func TestToTeams(t *testing.T) {
+	r := newResult()
+	r.Status = probe.StatusUp
+	str := ToTeams(r)
+	m := teamsMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(str), &m))
+	assert.Equal(t, "message", m.Type)
+	card := m.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	assert.Equal(t, "good", card.Body[0].Style)
+	assert.Equal(t, "Good", card.Body[0].Items[0].Color)
+	assert.Equal(t, "✅ "+r.Title(), card.Body[0].Items[0].Text)
+	assert.Equal(t, 5, len(card.Body[1].Facts))
+	assert.Equal(t, str, FormatFuncs[Teams].ResultFn(r))
+}
+
+func TestToTelegram(t *testing.T) {
+	assert.Equal(t, `a\_b\*c\[d\]\(e\)\.f\!`, TelegramEscape("a_b*c[d](e).f!"))
+
+	r := newResult()
+	r.Message = "a `quoted` message"
+	str := ToTelegram(r)
+	assert.True(t, strings.HasPrefix(str, "*❌ Test Name Failure*"))
+	assert.Contains(t, str, `*Endpoint*: http://example\.com`)
+	assert.Contains(t, str, "a \\`quoted\\` message")
+	assert.Equal(t, str, FormatFuncs[Telegram].ResultFn(r))
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+var csvResultHeader = []string{"name", "endpoint", "status", "time", "rtt", "message"}
+
+func toCSVString(rows [][]string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		log.Errorf("error: %v", err)
+		return ""
+	}
+	return buf.String()
+}
+
+// csvResultRow return the CSV row of the result, the round trip time is in milliseconds
+func csvResultRow(r probe.Result) []string {
+	return []string{
+		r.Name, r.Endpoint, r.Status.String(), r.StartTime.UTC().Format(time.RFC3339),
+		fmt.Sprint(r.RoundTripTime.Milliseconds()), r.Message,
+	}
+}
+
+// ToCSV convert the result object to CSV with the header line
+func ToCSV(r probe.Result) string {
+	return toCSVString([][]string{csvResultHeader, csvResultRow(r)})
+}
+
+// ToDigestCSV convert the results to CSV with the header line
+func ToDigestCSV(results []probe.Result) string {
+	rows := [][]string{csvResultHeader}
+	for _, r := range results {
+		rows = append(rows, csvResultRow(r))
+	}
+	return toCSVString(rows)
+}
@@ -0,0 +1,26 @@
+package report
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToCSV(t *testing.T) {
+	r := newResult()
+	r.Message = `Error, "quoted"`
+	rows, err := csv.NewReader(strings.NewReader(ToCSV(r))).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{
+		csvResultHeader,
+		{"Test Name", "http://example.com", "down", "2022-01-01T00:00:00Z", "1", `Error, "quoted"`},
+	}, rows)
+	assert.Equal(t, ToCSV(r), FormatFuncs[CSV].ResultFn(r))
+
+	rows, err = csv.NewReader(strings.NewReader(ToDigestCSV(newDigest()))).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(rows))
+	assert.Equal(t, "Another <Name>", rows[2][0])
+}
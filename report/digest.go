@@ -0,0 +1,200 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// IsRecovery return true if all of the results in the digest are recovered
+func IsRecovery(results []probe.Result) bool {
+	for _, r := range results {
+		if r.Status != probe.StatusUp {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+// digestStatus return the representative status of the digest
+func digestStatus(results []probe.Result) probe.Status {
+	if IsRecovery(results) {
+		return probe.StatusUp
+	}
+	return probe.StatusDown
+}
+
+// DigestTitle return the title of the digest, e.g. "3 Probes Failure" or "3 Probes Recovery"
+func DigestTitle(results []probe.Result) string {
+	if IsRecovery(results) {
+		return fmt.Sprintf("%d Probes Recovery", len(results))
+	}
+	return fmt.Sprintf("%d Probes Failure", len(results))
+}
+
+// digestLine return the one line summary of the result in the digest
+func digestLine(r probe.Result) string {
+	return fmt.Sprintf("%s %s (%s) - %s", r.Status.Emoji(), r.Name, r.Endpoint, r.Message)
+}
+
+// ToDigestText convert the results to plain text
+func ToDigestText(results []probe.Result) string {
+	var sb strings.Builder
+	s := digestStatus(results)
+	sb.WriteString("[" + DigestTitle(results) + "] " + s.Emoji() + "\n")
+	for _, r := range results {
+		sb.WriteString(digestLine(r) + "\n")
+	}
+	sb.WriteString(FormatTime(time.Now()))
+	return sb.String()
+}
+
+// ToDigestJSON convert the results to JSON array
+func ToDigestJSON(results []probe.Result) string {
+	j, err := json.Marshal(results)
+	if err != nil {
+		log.Errorf("error: %v", err)
+		return ""
+	}
+	return string(j)
+}
+
+// ToDigestHTML convert the results to HTML table
+func ToDigestHTML(results []probe.Result) string {
+	var sb strings.Builder
+	sb.WriteString(HTMLHeader(DigestTitle(results)))
+	sb.WriteString(`<table>
+	<tr><th style="text-align:left">Name</th><th style="text-align:left">Status</th><th style="text-align:left">Endpoint</th><th style="text-align:left">Time</th><th style="text-align:left">Message</th></tr>`)
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf(`
+	<tr><td>%s</td><td>%s %s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+			html.EscapeString(r.Name),
+			r.Status.Emoji(), html.EscapeString(r.Status.Title()),
+			html.EscapeString(r.Endpoint),
+			FormatTime(r.StartTime),
+			html.EscapeString(r.Message)))
+	}
+	sb.WriteString("\n\t</table>")
+	sb.WriteString(HTMLFooter())
+	return sb.String()
+}
+
+// ToDigestMarkdown convert the results to markdown
+func ToDigestMarkdown(results []probe.Result) string {
+	var sb strings.Builder
+	s := digestStatus(results)
+	sb.WriteString("**" + s.Emoji() + " " + DigestTitle(results) + "**\n\n")
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf("- %s **%s** (%s) - %s\n", r.Status.Emoji(), r.Name, r.Endpoint, r.Message))
+	}
+	sb.WriteString("\n_" + global.FooterString() + "_")
+	return sb.String()
+}
+
+// ToDigestSlack convert the results to the Slack message with blocks
+func ToDigestSlack(results []probe.Result) string {
+	s := digestStatus(results)
+	title := s.Emoji() + " " + DigestTitle(results)
+	lines := []string{}
+	for _, r := range results {
+		lines = append(lines, fmt.Sprintf("%s *%s* (%s)\n`%s`",
+			r.Status.Emoji(), SlackEscape(r.Name), SlackEscape(r.Endpoint), SlackEscape(r.Message)))
+	}
+	msg := slackMessage{
+		Text: SlackEscape(title),
+		Attachments: []slackAttachment{{
+			Color: StatusColor(s).Hex(),
+			Blocks: []slackBlock{
+				{Type: "header", Text: &slackText{"plain_text", title}},
+			},
+		}},
+	}
+	msg.Attachments[0].Blocks = append(msg.Attachments[0].Blocks, slackSections(lines)...)
+	msg.Attachments[0].Blocks = append(msg.Attachments[0].Blocks,
+		slackBlock{Type: "context", Elements: []slackText{{"mrkdwn", SlackEscape(global.FooterString())}}})
+	return toJSONString(msg)
+}
+
+// ToDigestDiscord convert the results to the Discord message with embeds
+func ToDigestDiscord(results []probe.Result) string {
+	e := global.GetEaseProbe()
+	s := digestStatus(results)
+	title := s.Emoji() + " " + DigestTitle(results)
+	// the fields are limited by the number and the total characters of the embed,
+	// the results which are omitted are counted in the description
+	fields := []discordField{}
+	description := ""
+	size := textLen(title) + textLen(global.FooterString()) + textLen(moreLine(len(results)))
+	for i, r := range results {
+		f := discordField{
+			truncate(r.Status.Emoji()+" "+r.Name, discordFieldNameLimit),
+			truncate(r.Endpoint+"\n"+r.Message, discordFieldValueLimit),
+			false,
+		}
+		size += textLen(f.Name) + textLen(f.Value)
+		if len(fields) >= discordFieldLimit || size > discordEmbedLimit {
+			description = moreLine(len(results) - i)
+			break
+		}
+		fields = append(fields, f)
+	}
+	msg := discordMessage{
+		Username:  e.Name,
+		AvatarURL: e.IconURL,
+		Embeds: []discordEmbed{{
+			Title:       title,
+			Description: description,
+			Color:       int(StatusColor(s)),
+			Fields:      fields,
+			Footer:      discordFooter{global.FooterString(), e.IconURL},
+			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		}},
+	}
+	return toJSONString(msg)
+}
+
+// ToDigestTeams convert the results to the Microsoft Teams message with adaptive card
+func ToDigestTeams(results []probe.Result) string {
+	s := digestStatus(results)
+	facts := []teamsFact{}
+	for _, r := range results {
+		facts = append(facts, teamsFact{r.Status.Emoji() + " " + r.Name, r.Endpoint + " - " + r.Message})
+	}
+	msg := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body: []teamsElement{
+					{Type: "Container", Style: teamsStyle[s], Bleed: true, Items: []teamsElement{
+						{Type: "TextBlock", Text: s.Emoji() + " " + DigestTitle(results), Size: "Large", Weight: "Bolder",
+							Color: teamsColor[s], Wrap: true},
+					}},
+					{Type: "FactSet", Facts: facts},
+					{Type: "TextBlock", Text: global.FooterString(), Size: "Small", IsSubtle: true},
+				},
+			},
+		}},
+	}
+	return toJSONString(msg)
+}
+
+// ToDigestTelegram convert the results to the Telegram MarkdownV2 message
+func ToDigestTelegram(results []probe.Result) string {
+	s := digestStatus(results)
+	lines := []string{}
+	for _, r := range results {
+		lines = append(lines, TelegramEscape(r.Status.Emoji()+" ")+"*"+TelegramEscape(r.Name)+"* "+
+			TelegramEscape("("+r.Endpoint+") - "+r.Message))
+	}
+	return telegramList(TelegramEscape(s.Emoji()+" "+DigestTitle(results)), lines)
+}
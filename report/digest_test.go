@@ -0,0 +1,89 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDigest() []probe.Result {
+	r1 := newResult()
+	r2 := newResult()
+	r2.Name = "Another <Name>"
+	r2.Endpoint = "tcp://example.com:22"
+	r2.Message = "Error (tcp): connection refused"
+	return []probe.Result{r1, r2}
+}
+
+func recovered(results []probe.Result) []probe.Result {
+	list := []probe.Result{}
+	for _, r := range results {
+		r.PreStatus, r.Status = r.Status, probe.StatusUp
+		list = append(list, r)
+	}
+	return list
+}
+
+func TestDigestTitle(t *testing.T) {
+	results := newDigest()
+	assert.False(t, IsRecovery(results))
+	assert.Equal(t, "2 Probes Failure", DigestTitle(results))
+
+	results = recovered(results)
+	assert.True(t, IsRecovery(results))
+	assert.Equal(t, "2 Probes Recovery", DigestTitle(results))
+
+	assert.False(t, IsRecovery(nil))
+}
+
+func TestToDigest(t *testing.T) {
+	global.InitEaseProbe("EaseProbe", "icon")
+	results := newDigest()
+
+	str := ToDigestText(results)
+	assert.True(t, strings.HasPrefix(str, "[2 Probes Failure] ❌\n"))
+	assert.Contains(t, str, "❌ Test Name (http://example.com) - Error (http): timeout")
+	assert.Contains(t, str, "❌ Another <Name> (tcp://example.com:22)")
+
+	list := []probe.Result{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDigestJSON(results)), &list))
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "Another <Name>", list[1].Name)
+
+	str = ToDigestHTML(results)
+	assert.Contains(t, str, "<h2>2 Probes Failure</h2>")
+	assert.Contains(t, str, "<td>Another &lt;Name&gt;</td>")
+
+	str = ToDigestMarkdown(recovered(results))
+	assert.True(t, strings.HasPrefix(str, "**✅ 2 Probes Recovery**"))
+	assert.Contains(t, str, "- ✅ **Test Name** (http://example.com)")
+
+	s := slackMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDigestSlack(results)), &s))
+	assert.Equal(t, "❌ 2 Probes Failure", s.Text)
+	assert.Equal(t, StatusColor(probe.StatusDown).Hex(), s.Attachments[0].Color)
+	assert.Contains(t, s.Attachments[0].Blocks[1].Text.Text, "Another &lt;Name&gt;")
+
+	d := discordMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDigestDiscord(recovered(results))), &d))
+	assert.Equal(t, "✅ 2 Probes Recovery", d.Embeds[0].Title)
+	assert.Equal(t, int(StatusColor(probe.StatusUp)), d.Embeds[0].Color)
+	assert.Equal(t, 2, len(d.Embeds[0].Fields))
+
+	m := teamsMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDigestTeams(results)), &m))
+	body := m.Attachments[0].Content.Body
+	assert.Equal(t, "❌ 2 Probes Failure", body[0].Items[0].Text)
+	assert.Equal(t, 2, len(body[1].Facts))
+
+	str = ToDigestTelegram(results)
+	assert.Contains(t, str, `*Another <Name\>* \(tcp://example\.com:22\)`)
+
+	for f, fn := range FormatFuncs {
+		assert.NotNil(t, fn.DigestFn, f.String())
+	}
+}
@@ -0,0 +1,104 @@
+// Package report is the package for rendering the probe results into messages
+package report
+
+import (
+	"strings"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+)
+
+// Format is the format of the message
+type Format int
+
+// The format types
+const (
+	Unknown Format = iota
+	Text
+	JSON
+	HTML
+	Markdown
+	Slack
+	Discord
+	Teams
+	Telegram
+	CSV
+)
+
+var (
+	fmtToString = map[Format]string{
+		Unknown:  "unknown",
+		Text:     "text",
+		JSON:     "json",
+		HTML:     "html",
+		Markdown: "markdown",
+		Slack:    "slack",
+		Discord:  "discord",
+		Teams:    "teams",
+		Telegram: "telegram",
+		CSV:      "csv",
+	}
+	stringToFmt = global.ReverseMap(fmtToString)
+)
+
+// String covert the Format to string
+func (f Format) String() string {
+	if val, ok := fmtToString[f]; ok {
+		return val
+	}
+	return fmtToString[Unknown]
+}
+
+// Format convert the string to Format
+func (f *Format) Format(s string) {
+	if val, ok := stringToFmt[strings.ToLower(s)]; ok {
+		*f = val
+	} else {
+		*f = Unknown
+	}
+}
+
+// MarshalYAML is marshal the format
+func (f Format) MarshalYAML() (interface{}, error) {
+	return global.EnumMarshalYaml(fmtToString, f, "Format")
+}
+
+// UnmarshalYAML is unmarshal the format
+func (f *Format) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return global.EnumUnmarshalYaml(unmarshal, stringToFmt, f, Unknown, "Format")
+}
+
+// MarshalJSON is marshal the format
+func (f Format) MarshalJSON() ([]byte, error) {
+	return global.EnumMarshalJSON(fmtToString, f, "Format")
+}
+
+// UnmarshalJSON is unmarshal the format
+func (f *Format) UnmarshalJSON(b []byte) error {
+	return global.EnumUnmarshalJSON(b, stringToFmt, f, Unknown, "Format")
+}
+
+// FormatFuncType is the format functions for a specific format
+// CardFn builds the structured message of the chat platforms with the title and message,
+// so that the message templates could be used without breaking the payload
+type FormatFuncType struct {
+	ResultFn func(result probe.Result) string
+	DigestFn func(results []probe.Result) string
+	StatFn   func(probers []probe.Prober) string
+	SLOFn    func(result probe.Result) string
+	CardFn   func(result probe.Result, title, message string) string
+}
+
+// FormatFuncs is the format functions map
+var FormatFuncs = map[Format]FormatFuncType{
+	Unknown:  {ToText, ToDigestText, ToSLAText, ToSLOText, nil},
+	Text:     {ToText, ToDigestText, ToSLAText, ToSLOText, nil},
+	JSON:     {ToJSON, ToDigestJSON, ToSLAJSON, ToJSON, nil},
+	HTML:     {ToHTML, ToDigestHTML, ToSLAHTML, ToSLOHTML, nil},
+	Markdown: {ToMarkdown, ToDigestMarkdown, ToSLAMarkdown, ToSLOMarkdown, nil},
+	Slack:    {ToSlack, ToDigestSlack, ToSLASlack, ToSLOSlack, ToSlackCard},
+	Discord:  {ToDiscord, ToDigestDiscord, ToSLADiscord, ToSLODiscord, ToDiscordCard},
+	Teams:    {ToTeams, ToDigestTeams, ToSLATeams, ToSLOTeams, ToTeamsCard},
+	Telegram: {ToTelegram, ToDigestTelegram, ToSLATelegram, ToSLOTelegram, ToTelegramCard},
+	CSV:      {ToCSV, ToDigestCSV, ToSLACSV, ToSLOCSV, nil},
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func testFormat(t *testing.T, str string, f Format, good bool) {
+	var fmt Format
+	fmt.Format(str)
+	if good {
+		assert.Equal(t, f, fmt)
+		assert.Equal(t, str, fmt.String())
+	} else {
+		assert.Equal(t, Unknown, fmt)
+	}
+
+	err := yaml.Unmarshal([]byte(str), &fmt)
+	if good {
+		assert.Nil(t, err)
+		assert.Equal(t, f, fmt)
+	} else {
+		assert.NotNil(t, err)
+	}
+
+	err = json.Unmarshal([]byte(`"`+str+`"`), &fmt)
+	if good {
+		assert.Nil(t, err)
+		assert.Equal(t, f, fmt)
+	} else {
+		assert.NotNil(t, err)
+	}
+
+	buf, err := yaml.Marshal(f)
+	if good {
+		assert.Nil(t, err)
+		assert.Equal(t, str+"\n", string(buf))
+	} else {
+		assert.NotNil(t, err)
+	}
+
+	buf, err = json.Marshal(f)
+	if good {
+		assert.Nil(t, err)
+		assert.Equal(t, `"`+str+`"`, string(buf))
+	} else {
+		assert.NotNil(t, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	testFormat(t, "text", Text, true)
+	testFormat(t, "json", JSON, true)
+	testFormat(t, "html", HTML, true)
+	testFormat(t, "csv", CSV, true)
+	testFormat(t, "unknown", Unknown, true)
+	testFormat(t, "bad", 100, false)
+
+	assert.Equal(t, "unknown", Format(100).String())
+
+	for f, fn := range FormatFuncs {
+		assert.NotNil(t, fn.ResultFn, f.String())
+		assert.NotNil(t, fn.StatFn, f.String())
+	}
+}
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/megaease/easeprobe/global"
+)
+
+// The message size limits of the chat platforms, the longer messages are rejected
+const (
+	slackHeaderLimit        = 150  // the characters of the Slack header text
+	slackFieldLimit         = 2000 // the characters of the Slack section field
+	slackSectionLimit       = 3000 // the characters of the Slack section text
+	slackSectionsLimit      = 20   // the sections of the Slack message, it's less than the 50 blocks limit
+	discordTitleLimit       = 256  // the characters of the Discord embed title
+	discordFieldLimit       = 25   // the fields of the Discord embed
+	discordFieldNameLimit   = 256  // the characters of the Discord field name
+	discordFieldValueLimit  = 1024 // the characters of the Discord field value
+	discordDescriptionLimit = 4096 // the characters of the Discord embed description
+	discordEmbedLimit       = 6000 // the total characters of the Discord embed
+	telegramTextLimit       = 4096 // the characters of the Telegram message
+	telegramLineLimit       = 256  // the characters of the title and the fields of the Telegram card, it leaves the room for the message
+)
+
+// textLen return the number of the characters
+func textLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// truncate cut the string to the limit of the characters, the ellipsis is appended if it's cut
+func truncate(s string, limit int) string {
+	if textLen(s) <= limit {
+		return s
+	}
+	if limit < 1 {
+		return ""
+	}
+	r := []rune(s)
+	return string(r[:limit-1]) + "…"
+}
+
+// truncateEscaped return the escaped string in the limit of the characters, the string is cut before
+// it's escaped, so that the escaped sequences are never broken
+func truncateEscaped(s string, limit int, escape func(string) string) string {
+	if e := escape(s); textLen(e) <= limit {
+		return e
+	}
+	if limit < 1 {
+		return ""
+	}
+	// the longest leading characters which fit with the ellipsis
+	r := []rune(s)
+	n := sort.Search(len(r), func(i int) bool {
+		return textLen(escape(string(r[:i+1])+"…")) > limit
+	})
+	return escape(string(r[:n]) + "…")
+}
+
+// moreLine return the line of the omitted items, e.g. "+3 more"
+func moreLine(n int) string {
+	return fmt.Sprintf("+%d more", n)
+}
+
+// fitLines return the leading lines which are joined by the newline in the limit of the characters,
+// the omitted lines are replaced by the line of the `more` function
+func fitLines(lines []string, limit int, more func(n int) string) []string {
+	if textLen(strings.Join(lines, "\n")) <= limit {
+		return lines
+	}
+	size := 0
+	for i, l := range lines {
+		size += textLen(l) + 1
+		if size+textLen(more(len(lines)-i-1)) > limit {
+			return append(lines[:i:i], more(len(lines)-i))
+		}
+	}
+	return lines
+}
+
+// slackSections pack the lines into the Slack sections, every section is in the limit of the characters,
+// the lines which don't fit into the last section are replaced by the "+N more" line
+func slackSections(lines []string) []slackBlock {
+	blocks := []slackBlock{}
+	section := func(lines []string) {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{"mrkdwn", strings.Join(lines, "\n")}})
+	}
+	for len(lines) > 0 {
+		if len(blocks) == slackSectionsLimit-1 {
+			section(fitLines(lines, slackSectionLimit, moreLine))
+			break
+		}
+		n, size := 0, 0
+		for n < len(lines) && size+textLen(lines[n]) <= slackSectionLimit {
+			size += textLen(lines[n]) + 1
+			n++
+		}
+		if n == 0 {
+			// the line is too long for one section
+			section([]string{truncate(lines[0], slackSectionLimit)})
+			n = 1
+		} else {
+			section(lines[:n])
+		}
+		lines = lines[n:]
+	}
+	return blocks
+}
+
+// telegramList return the Telegram message of the escaped title and lines in the limit of the characters,
+// the lines which don't fit are replaced by the "+N more" line
+func telegramList(title string, lines []string) string {
+	header := "*" + title + "*\n\n"
+	footer := "\n\n_" + TelegramEscape(global.FooterString()) + "_"
+	limit := telegramTextLimit - textLen(header) - textLen(footer)
+	lines = fitLines(lines, limit, func(n int) string { return TelegramEscape(moreLine(n)) })
+	return header + strings.Join(lines, "\n") + footer
+}
@@ -0,0 +1,170 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFitLines(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 3))
+	assert.Equal(t, "ab…", truncate("abcd", 3))
+	assert.Equal(t, "测…", truncate("测试测试", 2))
+	assert.Equal(t, "", truncate("abc", 0))
+	assert.Equal(t, "&lt;&gt;", truncateEscaped("<>", 8, SlackEscape))
+	assert.Equal(t, "&lt;…", truncateEscaped("<><>", 7, SlackEscape))
+	assert.Equal(t, "", truncateEscaped("<>", -1, SlackEscape))
+
+	lines := []string{"aaaa", "bbbb", "cccc"}
+	assert.Equal(t, lines, fitLines(lines, 14, moreLine))
+	assert.Equal(t, []string{"aaaa", "+2 more"}, fitLines(lines, 13, moreLine))
+	assert.Equal(t, []string{"+3 more"}, fitLines(lines, 8, moreLine))
+	assert.Empty(t, fitLines(nil, 10, moreLine))
+	for limit := 8; limit < 20; limit++ {
+		assert.True(t, len(strings.Join(fitLines(lines, limit, moreLine), "\n")) <= limit, limit)
+	}
+}
+
+func TestSlackSections(t *testing.T) {
+	assert.Empty(t, slackSections(nil))
+
+	line := strings.Repeat("x", 999)
+	blocks := slackSections([]string{line, line, line, line})
+	assert.Equal(t, 2, len(blocks))
+	assert.Equal(t, 3*999+2, len(blocks[0].Text.Text))
+
+	// the line longer than the section is truncated
+	blocks = slackSections([]string{strings.Repeat("x", 4000)})
+	assert.Equal(t, 1, len(blocks))
+	assert.Equal(t, slackSectionLimit, textLen(blocks[0].Text.Text))
+
+	lines := []string{}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, strings.Repeat("x", 1500))
+	}
+	blocks = slackSections(lines)
+	assert.Equal(t, slackSectionsLimit, len(blocks))
+	for _, b := range blocks {
+		assert.True(t, textLen(b.Text.Text) <= slackSectionLimit)
+	}
+	assert.True(t, strings.HasSuffix(blocks[len(blocks)-1].Text.Text, fmt.Sprintf("+%d more", 100-slackSectionsLimit)))
+}
+
+func TestDigestLimits(t *testing.T) {
+	global.InitEaseProbe("EaseProbe", "icon")
+	results := []probe.Result{}
+	for i := 0; i < 40; i++ {
+		r := newResult()
+		r.Name = fmt.Sprintf("probe-%02d", i)
+		r.Message = strings.Repeat("error ", 30)
+		results = append(results, r)
+	}
+
+	d := discordMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDigestDiscord(results)), &d))
+	assert.Equal(t, discordFieldLimit, len(d.Embeds[0].Fields))
+	assert.Equal(t, "+15 more", d.Embeds[0].Description)
+
+	// the long messages are limited by the total characters of the embed
+	for i := range results {
+		results[i].Message = strings.Repeat("e", 2000)
+	}
+	d = discordMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDigestDiscord(results)), &d))
+	size := textLen(d.Embeds[0].Title) + textLen(d.Embeds[0].Description) + textLen(d.Embeds[0].Footer.Text)
+	for _, f := range d.Embeds[0].Fields {
+		assert.True(t, textLen(f.Value) <= discordFieldValueLimit)
+		size += textLen(f.Name) + textLen(f.Value)
+	}
+	assert.True(t, size <= discordEmbedLimit)
+	assert.Equal(t, fmt.Sprintf("+%d more", 40-len(d.Embeds[0].Fields)), d.Embeds[0].Description)
+
+	s := slackMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDigestSlack(results)), &s))
+	for _, b := range s.Attachments[0].Blocks {
+		if b.Type == "section" {
+			assert.True(t, textLen(b.Text.Text) <= slackSectionLimit)
+		}
+	}
+	assert.Equal(t, "context", s.Attachments[0].Blocks[len(s.Attachments[0].Blocks)-1].Type)
+
+	str := ToDigestTelegram(results)
+	assert.True(t, textLen(str) <= telegramTextLimit)
+	assert.Contains(t, str, `\+`)
+	assert.True(t, strings.HasSuffix(str, "_"))
+}
+
+func TestSLALimits(t *testing.T) {
+	global.InitEaseProbe("EaseProbe", "icon")
+	probers := []probe.Prober{}
+	for i := 0; i < 200; i++ {
+		r := newResult()
+		r.Name = fmt.Sprintf("probe-%03d-%s", i, strings.Repeat("n", 20))
+		probers = append(probers, &slaProber{name: r.Name, result: &r})
+	}
+
+	d := discordMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToSLADiscord(probers)), &d))
+	assert.True(t, textLen(d.Embeds[0].Description) <= discordDescriptionLimit)
+	assert.Contains(t, d.Embeds[0].Description, " more")
+
+	s := slackMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToSLASlack(probers)), &s))
+	for _, b := range s.Attachments[0].Blocks {
+		if b.Type == "section" {
+			assert.True(t, textLen(b.Text.Text) <= slackSectionLimit)
+		}
+	}
+
+	str := ToSLATelegram(probers)
+	assert.True(t, textLen(str) <= telegramTextLimit)
+	assert.Contains(t, str, ` more`)
+}
+
+func TestCardLimits(t *testing.T) {
+	global.InitEaseProbe("EaseProbe", "icon")
+	r := newResult()
+	r.Endpoint = "http://example.com/" + strings.Repeat("a", 3000)
+	r.Message = strings.Repeat("<error> ", 1000)
+	title := strings.Repeat("title ", 100)
+
+	s := slackMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToSlackCard(r, title, r.Message)), &s))
+	blocks := s.Attachments[0].Blocks
+	assert.Equal(t, slackHeaderLimit, textLen(blocks[0].Text.Text))
+	for _, f := range blocks[1].Fields {
+		assert.True(t, textLen(f.Text) <= slackFieldLimit)
+	}
+	assert.True(t, textLen(blocks[2].Text.Text) <= slackSectionLimit)
+	assert.True(t, textLen(blocks[2].Text.Text) > slackSectionLimit-textLen(SlackEscape("<error> ")))
+	assert.True(t, strings.HasSuffix(blocks[2].Text.Text, "…```"))
+	// the escaped sequence is not broken
+	assert.NotContains(t, strings.ReplaceAll(blocks[2].Text.Text, "&lt;", ""), "&l")
+
+	d := discordMessage{}
+	assert.Nil(t, json.Unmarshal([]byte(ToDiscordCard(r, title, r.Message)), &d))
+	embed := d.Embeds[0]
+	assert.Equal(t, discordTitleLimit, textLen(embed.Title))
+	assert.Equal(t, discordDescriptionLimit, textLen(embed.Description))
+	size := textLen(embed.Title) + textLen(embed.Description) + textLen(embed.Footer.Text)
+	for _, f := range embed.Fields {
+		assert.True(t, textLen(f.Value) <= discordFieldValueLimit)
+		size += textLen(f.Name) + textLen(f.Value)
+	}
+	assert.True(t, size <= discordEmbedLimit)
+
+	str := ToTelegramCard(r, title, r.Message)
+	assert.Equal(t, telegramTextLimit, textLen(str))
+	assert.True(t, strings.HasSuffix(str, "…\n```\n_"+TelegramEscape(global.FooterString())+"_"))
+
+	// the short cards are not changed
+	r = newResult()
+	assert.Contains(t, ToSlack(r), r.Message)
+	assert.Contains(t, ToDiscord(r), r.Message)
+	assert.True(t, textLen(ToTelegram(r)) < telegramTextLimit)
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// FormatTime format the time with the global time format and time zone
+func FormatTime(t time.Time) string {
+	return t.In(global.GetTimeLocation()).Format(global.GetTimeFormat())
+}
+
+// ToText convert the result object to plain text
+func ToText(r probe.Result) string {
+	tpl := "[%s] %s\n%s - ⏱ %s\n%s\n%s"
+	return fmt.Sprintf(tpl,
+		r.Title(), r.Status.Emoji(), r.Endpoint, r.RoundTripTime.Round(time.Millisecond),
+		r.Message, FormatTime(r.StartTime))
+}
+
+// ToJSON convert the result object to JSON
+func ToJSON(r probe.Result) string {
+	j, err := json.Marshal(&r)
+	if err != nil {
+		log.Errorf("error: %v", err)
+		return ""
+	}
+	return string(j)
+}
+
+// HTMLHeader return the HTML head with the title
+func HTMLHeader(title string) string {
+	return `<html><head><meta charset="UTF-8"><style>
+	 table, th, td { border: 1px solid #ddd; border-collapse: collapse; padding: 6px; }
+	 th { text-align: right; background-color: #f5f5f5; }
+	</style></head><body>
+	<h2>` + html.EscapeString(title) + `</h2>`
+}
+
+// HTMLFooter return the HTML footer
+func HTMLFooter() string {
+	return `<p style="color:#888;font-size:small">` + html.EscapeString(global.FooterString()) + `</p></body></html>`
+}
+
+// ToHTML convert the result object to HTML
+func ToHTML(r probe.Result) string {
+	tpl := `<table>
+	<tr><th>Status</th><td>%s %s</td></tr>
+	<tr><th>Endpoint</th><td>%s</td></tr>
+	<tr><th>Round Trip Time</th><td>%s</td></tr>
+	<tr><th>SLA</th><td>%s</td></tr>
+	<tr><th>Time</th><td>%s</td></tr>
+	<tr><th>Message</th><td>%s</td></tr>
+	</table>`
+	body := fmt.Sprintf(tpl,
+		r.Status.Emoji(), html.EscapeString(r.Status.Title()),
+		html.EscapeString(r.Endpoint),
+		r.RoundTripTime.Round(time.Millisecond),
+		html.EscapeString(SLAText(r)),
+		FormatTime(r.StartTime),
+		html.EscapeString(r.Message))
+	return HTMLHeader(r.Title()) + body + HTMLFooter()
+}
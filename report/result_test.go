@@ -0,0 +1,68 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/monkey"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult() probe.Result {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := probe.NewResult()
+	r.Name = "Test Name"
+	r.Endpoint = "http://example.com"
+	r.StartTime = now
+	r.StartTimestamp = now.UnixMilli()
+	r.RoundTripTime = 1234 * time.Microsecond
+	r.PreStatus = probe.StatusUp
+	r.Status = probe.StatusDown
+	r.Message = "Error (http): timeout"
+	return *r
+}
+
+func TestToText(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	r := newResult()
+	str := ToText(r)
+	assert.Contains(t, str, "[Test Name Failure] ❌")
+	assert.Contains(t, str, "http://example.com - ⏱ 1ms")
+	assert.Contains(t, str, "Error (http): timeout")
+	assert.Contains(t, str, "2022-01-01 00:00:00")
+
+	assert.Equal(t, str, FormatFuncs[Text].ResultFn(r))
+}
+
+func TestToJSON(t *testing.T) {
+	r := newResult()
+	str := ToJSON(r)
+	res := probe.Result{}
+	err := json.Unmarshal([]byte(str), &res)
+	assert.Nil(t, err)
+	assert.Equal(t, r.Name, res.Name)
+	assert.Equal(t, r.Status, res.Status)
+
+	monkey.Patch(json.Marshal, func(v interface{}) ([]byte, error) {
+		return nil, fmt.Errorf("marshal error")
+	})
+	assert.Empty(t, ToJSON(r))
+	monkey.UnpatchAll()
+}
+
+func TestToHTML(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	r := newResult()
+	r.Message = "<b>timeout</b>"
+	str := ToHTML(r)
+	assert.Contains(t, str, "<h2>Test Name Failure</h2>")
+	assert.Contains(t, str, "❌ Error")
+	assert.Contains(t, str, "&lt;b&gt;timeout&lt;/b&gt;")
+	assert.Contains(t, str, "2022-01-01 00:00:00")
+	assert.Contains(t, str, global.FooterString())
+	assert.Equal(t, str, FormatFuncs[HTML].ResultFn(r))
+}
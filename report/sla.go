@@ -0,0 +1,436 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// SLA is the SLA statistics of one probe, it is calculated from the time-series store if it's enabled,
+// otherwise from the `Stat` of the probe result, or from the buckets, the history and the incidents for a period
+type SLA struct {
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind"`
+	Endpoint      string            `json:"endpoint"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Status        probe.Status      `json:"status"`
+	Since         time.Time         `json:"since"`
+	Until         time.Time         `json:"until,omitzero"`
+	UpTime        time.Duration     `json:"uptime"`
+	DownTime      time.Duration     `json:"downtime"`
+	Total         int64             `json:"total"`
+	Up            int64             `json:"up"`
+	Down          int64             `json:"down"`
+	Failures      int               `json:"failures"`
+	LongestOutage time.Duration     `json:"longest_outage"`
+	SLA           float64           `json:"sla"`
+}
+
+// NewSLA return the SLA statistics of the prober since it starts, the statistics are from the time-series store
+// if it's enabled, and the period starts at the first data of the store which is kept in the retention
+func NewSLA(p probe.Prober) SLA {
+	r := probe.Snapshot(p)
+	s := SLA{
+		Name:     p.Name(),
+		Kind:     p.Kind(),
+		Endpoint: r.Endpoint,
+		Labels:   p.LabelMap(),
+		Status:   r.Status,
+		Since:    r.Stat.Since,
+		UpTime:   r.Stat.UpTime,
+		DownTime: r.Stat.DownTime,
+		Total:    r.Stat.Total,
+		Up:       r.Stat.Status[probe.StatusUp],
+		Down:     r.Stat.Status[probe.StatusDown],
+		SLA:      r.SLAPercent(),
+	}
+	now := time.Now()
+	if st := store.Default(); st != nil {
+		// the store could be enabled after the probe starts, the period starts at the first stored data
+		if first, ok := storeSLA(&s, st, p, r.Stat.Since, now); ok {
+			s.Since = first
+		}
+	}
+	s.Failures, s.LongestOutage = r.Incidents.Between(s.Since, now, now)
+	return s
+}
+
+// storeSLA calculates the counts and the SLA of the period [from, to) from the time-series store,
+// the uptime and downtime are estimated by the interval of the prober, and the executions in the maintenance
+// windows which are excluded from the SLA are not counted. The start of the first stored data is returned
+// in the resolution of the store, false is returned if the store has no data of the period.
+func storeSLA(s *SLA, st *store.Store, p probe.Prober, from, to time.Time) (time.Time, bool) {
+	points := st.Series(p.Name(), from, to, st.Resolution)
+	if len(points) == 0 {
+		return time.Time{}, false
+	}
+	var excluded int64
+	s.Total, s.Up, s.Down = 0, 0, 0
+	for _, pt := range points {
+		s.Total += pt.Count
+		s.Up += pt.Up
+		s.Down += pt.Down
+		excluded += pt.Excluded
+	}
+	s.UpTime = time.Duration(s.Up) * p.Interval()
+	s.DownTime = time.Duration(s.Down) * p.Interval()
+	s.SLA = slaPercent(s.UpTime, s.DownTime, s.Up, s.Total-excluded)
+	return points[0].Time, true
+}
+
+// NewSLARange return the SLA statistics of the prober in the period [from, to),
+// the statistics are from the time-series store if it's enabled and it has the data of the period.
+// Otherwise, the uptime and downtime are summed by the hourly buckets of the last 30 days and by day before them,
+// so the resolution of the period is one hour, or one day before the last 30 days,
+// and the counts are from the history records which only cover the recent records
+func NewSLARange(p probe.Prober, from, to time.Time) SLA {
+	r := probe.Snapshot(p)
+	s := SLA{
+		Name:     p.Name(),
+		Kind:     p.Kind(),
+		Endpoint: r.Endpoint,
+		Labels:   p.LabelMap(),
+		Status:   r.Status,
+		Since:    from,
+		Until:    to,
+	}
+	s.Failures, s.LongestOutage = r.Incidents.Between(from, to, time.Now())
+	if st := store.Default(); st != nil {
+		if _, ok := storeSLA(&s, st, p, from, to); ok {
+			return s
+		}
+	}
+	s.UpTime, s.DownTime = r.UptimeBetween(from, to, time.Now())
+	for _, h := range r.History.Since(from) {
+		if !h.Time.Before(to) {
+			break
+		}
+		s.Total++
+		switch h.Status {
+		case probe.StatusUp:
+			s.Up++
+		case probe.StatusDown:
+			s.Down++
+		}
+	}
+	s.SLA = slaPercent(s.UpTime, s.DownTime, s.Up, s.Total)
+	return s
+}
+
+// slaPercent return the SLA percentage by the uptime, or by the up records if there is no uptime data
+func slaPercent(up, down time.Duration, upCount, total int64) float64 {
+	if up+down > 0 {
+		return up.Seconds() / (up + down).Seconds() * 100
+	}
+	if total > 0 {
+		return float64(upCount) / float64(total) * 100
+	}
+	return 0
+}
+
+// SLAs return the SLA statistics of all of the probers
+func SLAs(probers []probe.Prober) []SLA {
+	slas := make([]SLA, 0, len(probers))
+	for _, p := range probers {
+		slas = append(slas, NewSLA(p))
+	}
+	return slas
+}
+
+// SLAGroup is the SLA statistics of the probes which have the same value of the label
+type SLAGroup struct {
+	Name          string        `json:"name"`
+	UpTime        time.Duration `json:"uptime"`
+	DownTime      time.Duration `json:"downtime"`
+	Total         int64         `json:"total"`
+	Up            int64         `json:"up"`
+	Down          int64         `json:"down"`
+	Failures      int           `json:"failures"`
+	LongestOutage time.Duration `json:"longest_outage"`
+	SLA           float64       `json:"sla"`
+	Probes        []SLA         `json:"probes"`
+}
+
+// GroupSLA groups the SLA statistics by the value of the label, the groups are sorted by the name,
+// the probes without the label are in the group with the empty name
+func GroupSLA(slas []SLA, label string) []SLAGroup {
+	index := map[string]int{}
+	groups := []SLAGroup{}
+	for _, s := range slas {
+		name := s.Labels[label]
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, SLAGroup{Name: name, Probes: []SLA{}})
+		}
+		g := &groups[i]
+		g.UpTime += s.UpTime
+		g.DownTime += s.DownTime
+		g.Total += s.Total
+		g.Up += s.Up
+		g.Down += s.Down
+		g.Failures += s.Failures
+		if s.LongestOutage > g.LongestOutage {
+			g.LongestOutage = s.LongestOutage
+		}
+		g.Probes = append(g.Probes, s)
+	}
+	for i := range groups {
+		groups[i].SLA = slaPercent(groups[i].UpTime, groups[i].DownTime, groups[i].Up, groups[i].Total)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// SLATitle return the title of the SLA report
+func SLATitle(probers []probe.Prober) string {
+	return fmt.Sprintf("SLA Report of %d Probes", len(probers))
+}
+
+// slaStatus return the representative status of the SLA report
+func slaStatus(slas []SLA) probe.Status {
+	for _, s := range slas {
+		if s.Status == probe.StatusDown {
+			return probe.StatusDown
+		}
+	}
+	return probe.StatusUp
+}
+
+// slaDuration round the duration to the second for the report
+func slaDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+// slaLine return the one line summary of the SLA
+func slaLine(s SLA) string {
+	return fmt.Sprintf("%s %s (%s) - SLA %.2f%% - Up %s / Down %s - %d Up / %d Down / %d Total",
+		s.Status.Emoji(), s.Name, s.Kind, s.SLA, slaDuration(s.UpTime), slaDuration(s.DownTime), s.Up, s.Down, s.Total)
+}
+
+// ToSLAText convert the SLA of the probers to plain text
+func ToSLAText(probers []probe.Prober) string {
+	var sb strings.Builder
+	sb.WriteString("[" + SLATitle(probers) + "]\n")
+	for _, s := range SLAs(probers) {
+		sb.WriteString(slaLine(s) + "\n")
+	}
+	sb.WriteString(FormatTime(time.Now()))
+	return sb.String()
+}
+
+// ToSLAJSON convert the SLA of the probers to JSON array
+func ToSLAJSON(probers []probe.Prober) string {
+	j, err := json.Marshal(SLAs(probers))
+	if err != nil {
+		log.Errorf("error: %v", err)
+		return ""
+	}
+	return string(j)
+}
+
+// ToSLAHTML convert the SLA of the probers to HTML table
+func ToSLAHTML(probers []probe.Prober) string {
+	var sb strings.Builder
+	sb.WriteString(HTMLHeader(SLATitle(probers)))
+	sb.WriteString(`<table>
+	<tr><th style="text-align:left">Name</th><th style="text-align:left">Kind</th><th style="text-align:left">Endpoint</th><th style="text-align:left">Status</th><th>Up Time</th><th>Down Time</th><th>Up</th><th>Down</th><th>Total</th><th>Failures</th><th>Longest Outage</th><th>SLA</th><th style="text-align:left">Since</th></tr>`)
+	for _, s := range SLAs(probers) {
+		sb.WriteString(fmt.Sprintf(`
+	<tr><td>%s</td><td>%s</td><td>%s</td><td>%s %s</td><td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%s</td><td><b>%.2f%%</b></td><td>%s</td></tr>`,
+			html.EscapeString(s.Name), html.EscapeString(s.Kind), html.EscapeString(s.Endpoint),
+			s.Status.Emoji(), html.EscapeString(s.Status.Title()),
+			slaDuration(s.UpTime), slaDuration(s.DownTime), s.Up, s.Down, s.Total,
+			s.Failures, slaDuration(s.LongestOutage), s.SLA,
+			FormatTime(s.Since)))
+	}
+	sb.WriteString("\n\t</table>")
+	sb.WriteString(HTMLFooter())
+	return sb.String()
+}
+
+// markdownEscaper escape the chars which break the markdown table
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// slaTableHeader is the header of the markdown table of the SLA statistics
+const slaTableHeader = "| Name | Kind | Endpoint | Status | Up Time | Down Time | Up | Down | Total | Failures | Longest Outage | SLA |\n" +
+	"|:-----|:-----|:---------|:-------|--------:|----------:|---:|-----:|------:|---------:|---------------:|----:|\n"
+
+func slaTable(sb *strings.Builder, slas []SLA) {
+	sb.WriteString(slaTableHeader)
+	for _, s := range slas {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s %s | %s | %s | %d | %d | %d | %d | %s | %.2f%% |\n",
+			markdownEscaper.Replace(s.Name), markdownEscaper.Replace(s.Kind), markdownEscaper.Replace(s.Endpoint),
+			s.Status.Emoji(), s.Status.Title(),
+			slaDuration(s.UpTime), slaDuration(s.DownTime), s.Up, s.Down, s.Total,
+			s.Failures, slaDuration(s.LongestOutage), s.SLA))
+	}
+}
+
+// SLAMarkdown convert the SLA statistics to markdown table with the title
+func SLAMarkdown(title string, slas []SLA) string {
+	var sb strings.Builder
+	sb.WriteString("**" + title + "**\n\n")
+	slaTable(&sb, slas)
+	sb.WriteString("\n_" + global.FooterString() + "_")
+	return sb.String()
+}
+
+// SLAGroupMarkdown convert the grouped SLA statistics to markdown, one table for each group
+func SLAGroupMarkdown(title, label string, groups []SLAGroup) string {
+	var sb strings.Builder
+	sb.WriteString("**" + title + "**\n\n")
+	for _, g := range groups {
+		name := g.Name
+		if name == "" {
+			name = "-"
+		}
+		sb.WriteString(fmt.Sprintf("### %s: %s - SLA %.2f%%\n\n", label, markdownEscaper.Replace(name), g.SLA))
+		slaTable(&sb, g.Probes)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("_" + global.FooterString() + "_")
+	return sb.String()
+}
+
+// ToSLAMarkdown convert the SLA of the probers to markdown table
+func ToSLAMarkdown(probers []probe.Prober) string {
+	return SLAMarkdown(SLATitle(probers), SLAs(probers))
+}
+
+var slaCSVHeader = []string{"name", "kind", "endpoint", "status", "uptime", "downtime", "up", "down", "total",
+	"failures", "longest_outage", "sla", "since"}
+
+// slaCSVRow return the CSV row of the SLA, the durations are in seconds
+func slaCSVRow(s SLA) []string {
+	return []string{
+		s.Name, s.Kind, s.Endpoint, s.Status.String(),
+		fmt.Sprintf("%.0f", s.UpTime.Seconds()), fmt.Sprintf("%.0f", s.DownTime.Seconds()),
+		fmt.Sprint(s.Up), fmt.Sprint(s.Down), fmt.Sprint(s.Total),
+		fmt.Sprint(s.Failures), fmt.Sprintf("%.0f", s.LongestOutage.Seconds()),
+		fmt.Sprintf("%.4f", s.SLA), s.Since.UTC().Format(time.RFC3339),
+	}
+}
+
+// SLACSV convert the SLA statistics to CSV with the header line
+func SLACSV(slas []SLA) string {
+	rows := [][]string{slaCSVHeader}
+	for _, s := range slas {
+		rows = append(rows, slaCSVRow(s))
+	}
+	return toCSVString(rows)
+}
+
+// SLAGroupCSV convert the grouped SLA statistics to CSV, the first column is the value of the label
+func SLAGroupCSV(label string, groups []SLAGroup) string {
+	rows := [][]string{append([]string{label}, slaCSVHeader...)}
+	for _, g := range groups {
+		for _, s := range g.Probes {
+			rows = append(rows, append([]string{g.Name}, slaCSVRow(s)...))
+		}
+	}
+	return toCSVString(rows)
+}
+
+// ToSLACSV convert the SLA of the probers to CSV with the header line
+func ToSLACSV(probers []probe.Prober) string {
+	return SLACSV(SLAs(probers))
+}
+
+// ToSLASlack convert the SLA of the probers to the Slack message with blocks
+func ToSLASlack(probers []probe.Prober) string {
+	slas := SLAs(probers)
+	title := SLATitle(probers)
+	lines := []string{}
+	for _, s := range slas {
+		lines = append(lines, fmt.Sprintf("%s *%s* (%s) - `%.2f%%`\nUp %s / Down %s - %d Up / %d Down / %d Total",
+			s.Status.Emoji(), SlackEscape(s.Name), SlackEscape(s.Kind), s.SLA,
+			slaDuration(s.UpTime), slaDuration(s.DownTime), s.Up, s.Down, s.Total))
+	}
+	msg := slackMessage{
+		Text: SlackEscape(title),
+		Attachments: []slackAttachment{{
+			Color: StatusColor(slaStatus(slas)).Hex(),
+			Blocks: []slackBlock{
+				{Type: "header", Text: &slackText{"plain_text", title}},
+			},
+		}},
+	}
+	msg.Attachments[0].Blocks = append(msg.Attachments[0].Blocks, slackSections(lines)...)
+	msg.Attachments[0].Blocks = append(msg.Attachments[0].Blocks,
+		slackBlock{Type: "context", Elements: []slackText{{"mrkdwn", SlackEscape(global.FooterString())}}})
+	return toJSONString(msg)
+}
+
+// ToSLADiscord convert the SLA of the probers to the Discord message with embeds
+func ToSLADiscord(probers []probe.Prober) string {
+	e := global.GetEaseProbe()
+	slas := SLAs(probers)
+	lines := []string{}
+	for _, s := range slas {
+		lines = append(lines, slaLine(s))
+	}
+	msg := discordMessage{
+		Username:  e.Name,
+		AvatarURL: e.IconURL,
+		Embeds: []discordEmbed{{
+			Title:       SLATitle(probers),
+			Description: strings.Join(fitLines(lines, discordDescriptionLimit, moreLine), "\n"),
+			Color:       int(StatusColor(slaStatus(slas))),
+			Footer:      discordFooter{global.FooterString(), e.IconURL},
+			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		}},
+	}
+	return toJSONString(msg)
+}
+
+// ToSLATeams convert the SLA of the probers to the Microsoft Teams message with adaptive card
+func ToSLATeams(probers []probe.Prober) string {
+	slas := SLAs(probers)
+	s := slaStatus(slas)
+	facts := []teamsFact{}
+	for _, sla := range slas {
+		facts = append(facts, teamsFact{sla.Status.Emoji() + " " + sla.Name,
+			fmt.Sprintf("%.2f%% - Up %s / Down %s", sla.SLA, slaDuration(sla.UpTime), slaDuration(sla.DownTime))})
+	}
+	msg := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body: []teamsElement{
+					{Type: "Container", Style: teamsStyle[s], Bleed: true, Items: []teamsElement{
+						{Type: "TextBlock", Text: SLATitle(probers), Size: "Large", Weight: "Bolder",
+							Color: teamsColor[s], Wrap: true},
+					}},
+					{Type: "FactSet", Facts: facts},
+					{Type: "TextBlock", Text: global.FooterString(), Size: "Small", IsSubtle: true},
+				},
+			},
+		}},
+	}
+	return toJSONString(msg)
+}
+
+// ToSLATelegram convert the SLA of the probers to the Telegram MarkdownV2 message
+func ToSLATelegram(probers []probe.Prober) string {
+	lines := []string{}
+	for _, s := range SLAs(probers) {
+		lines = append(lines, TelegramEscape(s.Status.Emoji()+" ")+"*"+TelegramEscape(s.Name)+"* "+
+			TelegramEscape(fmt.Sprintf("(%s) - SLA %.2f%% - Up %s / Down %s",
+				s.Kind, s.SLA, slaDuration(s.UpTime), slaDuration(s.DownTime))))
+	}
+	return telegramList(TelegramEscape(SLATitle(probers)), lines)
+}
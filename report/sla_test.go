@@ -0,0 +1,271 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type slaProber struct {
+	name   string
+	labels prometheus.Labels
+	result *probe.Result
+}
+
+func (d *slaProber) LabelMap() prometheus.Labels          { return d.labels }
+func (d *slaProber) SetLabelMap(labels prometheus.Labels) {}
+func (d *slaProber) Kind() string                         { return "http" }
+func (d *slaProber) Name() string                         { return d.name }
+func (d *slaProber) Channels() []string                   { return nil }
+func (d *slaProber) Timeout() time.Duration               { return time.Second }
+func (d *slaProber) Interval() time.Duration              { return time.Minute }
+func (d *slaProber) Result() *probe.Result                { return d.result }
+func (d *slaProber) Config(global.ProbeSettings) error    { return nil }
+func (d *slaProber) Probe() probe.Result                  { return *d.result }
+
+var _ probe.Prober = (*slaProber)(nil)
+
+func newSLAProbers() []probe.Prober {
+	web := newResult()
+	web.Status = probe.StatusUp
+	web.Stat.Since = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	web.Stat.Total = 100
+	web.Stat.Status[probe.StatusUp] = 99
+	web.Stat.Status[probe.StatusDown] = 1
+	web.Stat.UpTime = 99 * time.Minute
+	web.Stat.DownTime = time.Minute
+
+	db := newResult()
+	db.Name = "db|<primary>"
+	db.Endpoint = "tcp://db:3306"
+	db.Stat.Since = web.Stat.Since
+	db.Stat.Total = 10
+	db.Stat.Status[probe.StatusDown] = 10
+	db.Stat.DownTime = 10 * time.Minute
+
+	return []probe.Prober{
+		&slaProber{name: web.Name, result: &web},
+		&slaProber{name: db.Name, result: &db},
+	}
+}
+
+func TestSLA(t *testing.T) {
+	probers := newSLAProbers()
+	slas := SLAs(probers)
+	assert.Equal(t, 2, len(slas))
+	assert.Equal(t, SLA{
+		Name: "Test Name", Kind: "http", Endpoint: "http://example.com", Status: probe.StatusUp,
+		Since:  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpTime: 99 * time.Minute, DownTime: time.Minute, Total: 100, Up: 99, Down: 1, SLA: 99,
+	}, slas[0])
+	assert.Equal(t, float64(0), slas[1].SLA)
+	assert.Equal(t, probe.StatusDown, slaStatus(slas))
+	assert.Equal(t, probe.StatusUp, slaStatus(slas[:1]))
+	assert.Equal(t, "SLA Report of 2 Probes", SLATitle(probers))
+}
+
+func TestSLAFormat(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	probers := newSLAProbers()
+
+	str := ToSLAText(probers)
+	assert.Contains(t, str, "[SLA Report of 2 Probes]")
+	assert.Contains(t, str, "✅ Test Name (http) - SLA 99.00% - Up 1h39m0s / Down 1m0s - 99 Up / 1 Down / 100 Total")
+
+	var slas []SLA
+	assert.Nil(t, json.Unmarshal([]byte(ToSLAJSON(probers)), &slas))
+	assert.Equal(t, SLAs(probers), slas)
+
+	str = ToSLAHTML(probers)
+	assert.Contains(t, str, "<h2>SLA Report of 2 Probes</h2>")
+	assert.Contains(t, str, "<td>db|&lt;primary&gt;</td>")
+	assert.Contains(t, str, "<b>99.00%</b>")
+
+	str = ToSLAMarkdown(probers)
+	assert.Contains(t, str, "| Test Name | http | http://example.com | ✅ Success | 1h39m0s | 1m0s | 99 | 1 | 100 | 0 | 0s | 99.00% |")
+	assert.Contains(t, str, `| db\|<primary> |`)
+
+	rows, err := csv.NewReader(strings.NewReader(ToSLACSV(probers))).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(rows))
+	assert.Equal(t, "sla", rows[0][11])
+	assert.Equal(t, []string{"Test Name", "http", "http://example.com", "up", "5940", "60", "99", "1", "100", "0", "0", "99.0000", "2022-01-01T00:00:00Z"}, rows[1])
+	assert.Equal(t, "db|<primary>", rows[2][0])
+
+	// the chat messages must be valid JSON
+	for _, f := range []Format{Slack, Discord, Teams} {
+		str = FormatFuncs[f].StatFn(probers)
+		assert.True(t, json.Valid([]byte(str)), f.String())
+		assert.Contains(t, str, "SLA Report of 2 Probes", f.String())
+	}
+	assert.Contains(t, ToSLASlack(probers), StatusColor(probe.StatusDown).Hex())
+
+	str = ToSLATelegram(probers)
+	assert.Contains(t, str, "*SLA Report of 2 Probes*")
+	assert.Contains(t, str, `\(http\) \- SLA 99\.00%`)
+}
+
+func TestSLARange(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	from := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	r := newResult()
+	r.History = *probe.NewHistory(10)
+	r.Daily = probe.Daily{
+		{Date: "2022-05-31", UpTime: 10 * time.Hour},
+		{Date: "2022-06-01", UpTime: 3 * time.Hour, DownTime: time.Hour},
+		{Date: "2022-06-30", UpTime: 4 * time.Hour},
+		{Date: "2022-07-01", DownTime: 10 * time.Hour},
+	}
+	r.History.Append(probe.HistoryRecord{Time: from.Add(-time.Minute), Status: probe.StatusDown})
+	r.History.Append(probe.HistoryRecord{Time: from, Status: probe.StatusUp})
+	r.History.Append(probe.HistoryRecord{Time: from.Add(time.Minute), Status: probe.StatusDown})
+	r.History.Append(probe.HistoryRecord{Time: from.Add(2 * time.Minute), Status: probe.StatusUp})
+	r.History.Append(probe.HistoryRecord{Time: to, Status: probe.StatusDown})
+	r.Incidents = probe.Incidents{
+		{Start: from.Add(time.Minute), End: from.Add(time.Hour + time.Minute), Status: probe.StatusDown},
+		{Start: to.Add(-time.Minute), End: to.Add(time.Hour), Status: probe.StatusDown},
+	}
+	p := &slaProber{name: r.Name, labels: prometheus.Labels{"env": "prod"}, result: &r}
+
+	s := NewSLARange(p, from, to)
+	assert.Equal(t, from, s.Since)
+	assert.Equal(t, to, s.Until)
+	assert.Equal(t, 7*time.Hour, s.UpTime)
+	assert.Equal(t, time.Hour, s.DownTime)
+	assert.Equal(t, int64(3), s.Total)
+	assert.Equal(t, int64(2), s.Up)
+	assert.Equal(t, int64(1), s.Down)
+	assert.Equal(t, 2, s.Failures)
+	assert.Equal(t, time.Hour, s.LongestOutage)
+	assert.Equal(t, 87.5, s.SLA)
+	assert.Equal(t, map[string]string{"env": "prod"}, s.Labels)
+
+	// no uptime data, the SLA is calculated by the history records
+	r.Daily = nil
+	s = NewSLARange(p, from, to)
+	assert.InDelta(t, 66.67, s.SLA, 0.01)
+
+	// the partial day is counted by the hourly buckets
+	now := time.Now().UTC()
+	r.Buckets = nil
+	r.Buckets.Add(now.Add(-2*time.Hour), true, time.Hour)
+	r.Buckets.Add(now.Add(-time.Hour), false, time.Hour)
+	r.Daily = probe.Daily{{Date: probe.DateOf(now), UpTime: 20 * time.Hour}}
+	s = NewSLARange(p, now.Add(-time.Hour).Truncate(time.Hour), now.Truncate(time.Hour))
+	assert.Equal(t, time.Duration(0), s.UpTime)
+	assert.Equal(t, time.Hour, s.DownTime)
+
+	// no data at all
+	s = NewSLARange(p, to.AddDate(1, 0, 0), to.AddDate(2, 0, 0))
+	assert.Equal(t, float64(0), s.SLA)
+	assert.Equal(t, int64(0), s.Total)
+}
+
+func TestSLARangeStore(t *testing.T) {
+	st, err := store.Open(store.Settings{Dir: t.TempDir()})
+	assert.Nil(t, err)
+	store.SetDefault(st)
+	defer func() {
+		st.Close()
+		store.SetDefault(nil)
+	}()
+
+	to := time.Now().UTC().Truncate(time.Minute)
+	from := to.Add(-time.Hour)
+	r := newResult()
+	r.History = *probe.NewHistory(10)
+	r.Daily = nil
+	r.Incidents = nil
+	for i, status := range []probe.Status{probe.StatusDown, probe.StatusUp, probe.StatusUp, probe.StatusDown, probe.StatusUp} {
+		res := r.Clone()
+		res.StartTime = from.Add(time.Duration(i*20-10) * time.Minute)
+		res.Status = status
+		assert.Nil(t, st.Append(res))
+	}
+	p := &slaProber{name: r.Name, result: &r}
+
+	// the executions in the maintenance window which is excluded from the SLA
+	res := r.Clone()
+	res.StartTime = to.Add(-5 * time.Minute)
+	res.Status = probe.StatusDown
+	res.SLAExcluded = true
+	assert.Nil(t, st.Append(res))
+
+	// the statistics are from the store instead of the history and the buckets
+	s := NewSLARange(p, from, to)
+	assert.Equal(t, int64(4), s.Total)
+	assert.Equal(t, int64(2), s.Up)
+	assert.Equal(t, int64(1), s.Down)
+	assert.Equal(t, 2*time.Minute, s.UpTime)
+	assert.Equal(t, time.Minute, s.DownTime)
+	assert.InDelta(t, 66.67, s.SLA, 0.01)
+
+	// the scheduled report is from the store since the probe starts
+	r.Stat.Since = from
+	s = NewSLA(p)
+	assert.False(t, s.Since.Before(from))
+	assert.False(t, s.Since.After(from.Add(10*time.Minute)))
+	assert.Equal(t, int64(4), s.Total)
+	assert.InDelta(t, 66.67, s.SLA, 0.01)
+
+	// the store is enabled after the probe starts, the period starts at the first stored data
+	r.Stat.Since = from.AddDate(-1, 0, 0)
+	s = NewSLA(p)
+	first := from.Add(-10 * time.Minute)
+	assert.Equal(t, first.Truncate(st.Resolution), s.Since)
+	assert.Equal(t, int64(5), s.Total)
+
+	// no data in the store
+	s = NewSLARange(p, from.AddDate(0, 0, -2), from.AddDate(0, 0, -1))
+	assert.Equal(t, int64(0), s.Total)
+}
+
+func TestGroupSLA(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	slas := []SLA{
+		{Name: "web", Labels: map[string]string{"env": "prod"}, UpTime: 9 * time.Hour, DownTime: time.Hour, Failures: 1, LongestOutage: time.Hour},
+		{Name: "db", Labels: map[string]string{"env": "prod"}, UpTime: 10 * time.Hour, Failures: 2, LongestOutage: time.Minute},
+		{Name: "test", Labels: map[string]string{"env": "test"}, Total: 4, Up: 3},
+		{Name: "none"},
+	}
+	groups := GroupSLA(slas, "env")
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, []string{"", "prod", "test"}, []string{groups[0].Name, groups[1].Name, groups[2].Name})
+
+	prod := groups[1]
+	assert.Equal(t, 2, len(prod.Probes))
+	assert.Equal(t, 19*time.Hour, prod.UpTime)
+	assert.Equal(t, 3, prod.Failures)
+	assert.Equal(t, time.Hour, prod.LongestOutage)
+	assert.Equal(t, 95.0, prod.SLA)
+	assert.Equal(t, 75.0, groups[2].SLA)
+	assert.Equal(t, float64(0), groups[0].SLA)
+
+	md := SLAGroupMarkdown("Monthly SLA", "env", groups)
+	assert.Contains(t, md, "**Monthly SLA**")
+	assert.Contains(t, md, "### env: prod - SLA 95.00%")
+	assert.Contains(t, md, "### env: - - SLA 0.00%")
+	assert.Contains(t, SLAMarkdown("Monthly SLA", slas), "| web |")
+
+	rows, err := csv.NewReader(strings.NewReader(SLAGroupCSV("env", groups))).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(rows))
+	assert.Equal(t, "env", rows[0][0])
+	assert.Equal(t, []string{"", "none"}, rows[1][:2])
+	assert.Equal(t, []string{"prod", "web"}, rows[2][:2])
+	assert.Equal(t, "3600", rows[2][11]) // the longest outage in seconds
+
+	rows, err = csv.NewReader(strings.NewReader(SLACSV(slas))).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(rows))
+}
@@ -0,0 +1,175 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// sloStatus return the representative status of the burn rate alert for the color
+func sloStatus(s *probe.SLOStatus) probe.Status {
+	switch s.Alert {
+	case probe.BurnFast:
+		return probe.StatusDown
+	case probe.BurnSlow:
+		return probe.StatusUnknown
+	}
+	return probe.StatusUp
+}
+
+// sloBurnWindows return the windows of the burn rate alert, the windows of the previous alert is used if resolved
+func sloBurnWindows(s *probe.SLOStatus) probe.BurnWindows {
+	alert := s.Alert
+	if alert == probe.BurnNone {
+		alert = s.PreAlert
+	}
+	if alert == probe.BurnSlow {
+		return probe.SlowBurnWindows
+	}
+	return probe.FastBurnWindows
+}
+
+// sloWindow return the SLO window in days if possible, e.g. "30d"
+func sloWindow(d time.Duration) string {
+	if d > 0 && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+// BurnRateText return the burn rates of the windows, e.g. "1h 15.00x, 5m 20.00x, 6h 3.00x, 30m 8.00x"
+func BurnRateText(s *probe.SLOStatus) string {
+	list := []string{}
+	for _, w := range []probe.BurnWindows{probe.FastBurnWindows, probe.SlowBurnWindows} {
+		for _, rw := range []probe.RollingWindow{w.Long, w.Short} {
+			if v, ok := s.BurnRates[rw.Name]; ok {
+				list = append(list, fmt.Sprintf("%s %.2fx", rw.Name, v))
+			}
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+// SLOTitle return the title of the burn rate alert, e.g. "Web Error Budget Fast Burn"
+func SLOTitle(r probe.Result) string {
+	if r.SLO == nil {
+		return r.Title()
+	}
+	return r.Name + " " + r.SLO.Alert.Title()
+}
+
+// SLOMessage return the description of the burn rate alert
+func SLOMessage(r probe.Result) string {
+	s := r.SLO
+	if s == nil {
+		return r.Message
+	}
+	w := sloBurnWindows(s)
+	if s.Alert == probe.BurnNone {
+		return fmt.Sprintf("The error budget of the %v%% SLO is burning %.2fx in the last %s and %.2fx in the last %s, "+
+			"the %s burn is resolved, %.2f%% of the budget remains",
+			s.Target, s.BurnRates[w.Long.Name], w.Long.Name, s.BurnRates[w.Short.Name], w.Short.Name,
+			s.PreAlert, s.Budget)
+	}
+	return fmt.Sprintf("The error budget of the %v%% SLO is burning %.2fx in the last %s and %.2fx in the last %s, "+
+		"%.2f%% of the budget remains",
+		s.Target, s.BurnRates[w.Long.Name], w.Long.Name, s.BurnRates[w.Short.Name], w.Short.Name, s.Budget)
+}
+
+// SLOFields return the fields of the burn rate alert, all of the chat formats share the same fields
+func SLOFields(r probe.Result) []Field {
+	s := r.SLO
+	if s == nil {
+		return ResultFields(r)
+	}
+	return []Field{
+		{"Endpoint", r.Endpoint},
+		{"SLO", fmt.Sprintf("%v%% in %s", s.Target, sloWindow(s.Window))},
+		{"Error Budget", fmt.Sprintf("%.2f%% remaining", s.Budget)},
+		{"Burn Rate", BurnRateText(s)},
+		{"Time", FormatTime(r.StartTime)},
+	}
+}
+
+// sloEmojiTitle return the title of the burn rate alert with the emoji
+func sloEmojiTitle(r probe.Result) string {
+	if r.SLO == nil {
+		return ResultTitle(r)
+	}
+	return r.SLO.Alert.Emoji() + " " + SLOTitle(r)
+}
+
+// sloColorStatus return the status for the color of the burn rate alert
+func sloColorStatus(r probe.Result) probe.Status {
+	if r.SLO == nil {
+		return r.Status
+	}
+	return sloStatus(r.SLO)
+}
+
+// ToSLOText convert the burn rate alert to plain text
+func ToSLOText(r probe.Result) string {
+	var sb strings.Builder
+	sb.WriteString("[" + SLOTitle(r) + "]\n")
+	for _, f := range SLOFields(r) {
+		sb.WriteString(f.Name + ": " + f.Value + "\n")
+	}
+	sb.WriteString(SLOMessage(r))
+	return sb.String()
+}
+
+// ToSLOHTML convert the burn rate alert to HTML
+func ToSLOHTML(r probe.Result) string {
+	var sb strings.Builder
+	sb.WriteString(HTMLHeader(SLOTitle(r)))
+	sb.WriteString("<table>")
+	for _, f := range SLOFields(r) {
+		sb.WriteString(fmt.Sprintf("\n\t<tr><th>%s</th><td>%s</td></tr>", html.EscapeString(f.Name), html.EscapeString(f.Value)))
+	}
+	sb.WriteString(fmt.Sprintf("\n\t<tr><th>Message</th><td>%s</td></tr>\n\t</table>", html.EscapeString(SLOMessage(r))))
+	sb.WriteString(HTMLFooter())
+	return sb.String()
+}
+
+// ToSLOMarkdown convert the burn rate alert to markdown
+func ToSLOMarkdown(r probe.Result) string {
+	return markdownCard(sloEmojiTitle(r), SLOFields(r), SLOMessage(r))
+}
+
+// ToSLOSlack convert the burn rate alert to the Slack message with blocks
+func ToSLOSlack(r probe.Result) string {
+	return slackCard(sloEmojiTitle(r), sloColorStatus(r), SLOFields(r), SLOMessage(r))
+}
+
+// ToSLODiscord convert the burn rate alert to the Discord message with embeds
+func ToSLODiscord(r probe.Result) string {
+	return discordCard(sloEmojiTitle(r), sloColorStatus(r), SLOFields(r), SLOMessage(r), r.StartTime)
+}
+
+// ToSLOTeams convert the burn rate alert to the Microsoft Teams message with adaptive card
+func ToSLOTeams(r probe.Result) string {
+	return teamsCardMessage(sloEmojiTitle(r), sloColorStatus(r), SLOFields(r), SLOMessage(r))
+}
+
+// ToSLOTelegram convert the burn rate alert to the Telegram MarkdownV2 message
+func ToSLOTelegram(r probe.Result) string {
+	return telegramCard(sloEmojiTitle(r), SLOFields(r), SLOMessage(r))
+}
+
+var sloCSVHeader = []string{"name", "endpoint", "time", "target", "window", "budget", "alert", "prealert", "burn_rates"}
+
+// ToSLOCSV convert the burn rate alert to CSV with the header line, the window is in seconds
+func ToSLOCSV(r probe.Result) string {
+	s := r.SLO
+	if s == nil {
+		return ToCSV(r)
+	}
+	return toCSVString([][]string{sloCSVHeader, {
+		r.Name, r.Endpoint, r.StartTime.UTC().Format(time.RFC3339),
+		fmt.Sprint(s.Target), fmt.Sprintf("%.0f", s.Window.Seconds()), fmt.Sprintf("%.4f", s.Budget),
+		s.Alert.String(), s.PreAlert.String(), BurnRateText(s),
+	}})
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSLOResult(alert, pre probe.BurnAlert) probe.Result {
+	r := probe.NewResult()
+	r.Name = "Web"
+	r.Endpoint = "https://example.com"
+	r.Status = probe.StatusUp
+	r.StartTime = time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC)
+	r.SLO = &probe.SLOStatus{
+		Target:    99.9,
+		Window:    30 * 24 * time.Hour,
+		Budget:    80,
+		BurnRates: map[string]float64{"1h": 15, "5m": 20, "6h": 3, "30m": 8},
+		Alert:     alert,
+		PreAlert:  pre,
+	}
+	return *r
+}
+
+func TestSLOMessage(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	r := newSLOResult(probe.BurnFast, probe.BurnNone)
+	assert.Equal(t, "Web Error Budget Fast Burn", SLOTitle(r))
+	assert.Equal(t, "The error budget of the 99.9% SLO is burning 15.00x in the last 1h and 20.00x in the last 5m, "+
+		"80.00% of the budget remains", SLOMessage(r))
+	assert.Equal(t, "1h 15.00x, 5m 20.00x, 6h 3.00x, 30m 8.00x", BurnRateText(r.SLO))
+
+	fields := SLOFields(r)
+	assert.Equal(t, Field{"SLO", "99.9% in 30d"}, fields[1])
+	assert.Equal(t, Field{"Error Budget", "80.00% remaining"}, fields[2])
+
+	// resolved, the windows of the previous alert are used
+	r = newSLOResult(probe.BurnNone, probe.BurnSlow)
+	assert.Equal(t, "Web Error Budget Burn Resolved", SLOTitle(r))
+	assert.Contains(t, SLOMessage(r), "3.00x in the last 6h and 8.00x in the last 30m, the slow burn is resolved")
+	assert.Equal(t, probe.StatusUp, sloColorStatus(r))
+
+	assert.Equal(t, "1h30m0s", sloWindow(90*time.Minute))
+
+	// no SLO status
+	r.SLO = nil
+	r.Message = "message"
+	assert.Equal(t, r.Title(), SLOTitle(r))
+	assert.Equal(t, "message", SLOMessage(r))
+	assert.Equal(t, ResultFields(r), SLOFields(r))
+	assert.Equal(t, ToCSV(r), ToSLOCSV(r))
+}
+
+func TestSLOFormats(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	r := newSLOResult(probe.BurnFast, probe.BurnNone)
+
+	text := ToSLOText(r)
+	assert.True(t, strings.HasPrefix(text, "[Web Error Budget Fast Burn]\n"))
+	assert.Contains(t, text, "Burn Rate: 1h 15.00x")
+
+	assert.Contains(t, ToSLOHTML(r), "<h2>Web Error Budget Fast Burn</h2>")
+	assert.Contains(t, ToSLOMarkdown(r), "**🔥 Web Error Budget Fast Burn**")
+	assert.Contains(t, ToSLOTelegram(r), "*🔥 Web Error Budget Fast Burn*")
+
+	for _, fn := range []func(probe.Result) string{ToSLOSlack, ToSLODiscord, ToSLOTeams} {
+		var m map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(fn(r)), &m))
+	}
+	assert.Contains(t, ToSLOSlack(r), StatusColor(probe.StatusDown).Hex())
+	assert.Contains(t, ToSLODiscord(r), "Web Error Budget Fast Burn")
+
+	rows, err := csv.NewReader(strings.NewReader(ToSLOCSV(r))).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, sloCSVHeader, rows[0])
+	assert.Equal(t, []string{"Web", "https://example.com", "2022-06-15T10:30:00Z", "99.9", "2592000", "80.0000",
+		"fast", "none", "1h 15.00x, 5m 20.00x, 6h 3.00x, 30m 8.00x"}, rows[1])
+
+	for f, fn := range FormatFuncs {
+		assert.NotNil(t, fn.SLOFn, f.String())
+	}
+}
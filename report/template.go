@@ -0,0 +1,214 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+)
+
+// Template is the user-defined message template, the title is always rendered
+// by text/template, the body is rendered by html/template for the HTML notifications
+type Template struct {
+	Title string `yaml:"title,omitempty" json:"title,omitempty" jsonschema:"title=Title Template,description=the Go template of the message title (or the email subject)"`
+	Body  string `yaml:"body,omitempty" json:"body,omitempty" jsonschema:"title=Body Template,description=the Go template of the message body"`
+}
+
+// TemplateData is the data which is passed to the message template
+type TemplateData struct {
+	probe.Result
+	Kind   string
+	Labels map[string]string
+	Notify string
+}
+
+// ProbeContext is the probe information which is used by the message template
+type ProbeContext struct {
+	Kind     string
+	Labels   map[string]string
+	Template Template
+}
+
+var (
+	probeContexts = map[string]ProbeContext{}
+	contextMutex  = &sync.RWMutex{}
+)
+
+// SetProbeContext set the context of the probe, it is called when the probe is configured
+func SetProbeContext(name string, ctx ProbeContext) {
+	contextMutex.Lock()
+	defer contextMutex.Unlock()
+	probeContexts[name] = ctx
+}
+
+// GetProbeContext get the context of the probe
+func GetProbeContext(name string) ProbeContext {
+	contextMutex.RLock()
+	defer contextMutex.RUnlock()
+	return probeContexts[name]
+}
+
+// NewTemplateData create the template data of the result
+func NewTemplateData(r probe.Result, notifyName string) *TemplateData {
+	ctx := GetProbeContext(r.Name)
+	return &TemplateData{
+		Result: r,
+		Kind:   ctx.Kind,
+		Labels: ctx.Labels,
+		Notify: notifyName,
+	}
+}
+
+// TemplateFuncs is the helper functions of the message template
+var TemplateFuncs = map[string]interface{}{
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Millisecond).String()
+	},
+	"sla": func(r probe.Result) string {
+		return fmt.Sprintf("%.2f%%", r.SLAPercent())
+	},
+	"window": func(r probe.Result, name string) string {
+		if v, ok := r.Windows[name]; ok {
+			return fmt.Sprintf("%.2f%%", v)
+		}
+		return "-"
+	},
+	"percent": func(f float64) string {
+		return fmt.Sprintf("%.2f%%", f)
+	},
+	"time": FormatTime,
+	"timeFormat": func(t time.Time, layout string) string {
+		return t.In(global.GetTimeLocation()).Format(layout)
+	},
+	"emoji": func(s probe.Status) string {
+		return s.Emoji()
+	},
+	"label": func(labels map[string]string, key string) string {
+		return labels[key]
+	},
+	"labels": func(labels map[string]string) string {
+		list := make([]string, 0, len(labels))
+		for k, v := range labels {
+			list = append(list, k+"="+v)
+		}
+		sort.Strings(list)
+		return strings.Join(list, ", ")
+	},
+	"json": func(v interface{}) string {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return `""`
+		}
+		return string(buf)
+	},
+}
+
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+type templateKey struct {
+	name string
+	text string
+	html bool
+}
+
+var (
+	templateCache = map[templateKey]executor{}
+	cacheMutex    = &sync.RWMutex{}
+)
+
+// parse return the parsed template, the templates are parsed once and cached,
+// they are safe to be executed concurrently
+func parse(name, text string, html bool) (executor, error) {
+	key := templateKey{name, text, html}
+	cacheMutex.RLock()
+	tpl, ok := templateCache[key]
+	cacheMutex.RUnlock()
+	if ok {
+		return tpl, nil
+	}
+
+	var err error
+	if html {
+		tpl, err = htmltemplate.New(name).Funcs(TemplateFuncs).Parse(text)
+	} else {
+		tpl, err = template.New(name).Funcs(TemplateFuncs).Parse(text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	templateCache[key] = tpl
+	return tpl, nil
+}
+
+func execute(name, text string, html bool, data *TemplateData) (string, error) {
+	tpl, err := parse(name, text, html)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// IsEmpty return true if there is no template
+func (t Template) IsEmpty() bool {
+	return strings.TrimSpace(t.Title) == "" && strings.TrimSpace(t.Body) == ""
+}
+
+// Merge return the template whose empty parts are filled by the fallback template
+func (t Template) Merge(fallback Template) Template {
+	if strings.TrimSpace(t.Title) == "" {
+		t.Title = fallback.Title
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		t.Body = fallback.Body
+	}
+	return t
+}
+
+// Validate render the template with a sample result to make sure it is valid,
+// the parsed templates are cached for the notifications
+func (t Template) Validate() error {
+	data := NewTemplateData(*probe.NewResult(), "")
+	if _, err := t.RenderTitle(data); err != nil {
+		return fmt.Errorf("invalid title template: %v", err)
+	}
+	for _, html := range []bool{false, true} {
+		if _, err := t.RenderBody(data, html); err != nil {
+			return fmt.Errorf("invalid body template: %v", err)
+		}
+	}
+	return nil
+}
+
+// RenderTitle render the title with text/template, empty string is returned if no title template
+func (t Template) RenderTitle(data *TemplateData) (string, error) {
+	if strings.TrimSpace(t.Title) == "" {
+		return "", nil
+	}
+	title, err := execute("title", t.Title, false, data)
+	return strings.TrimSpace(title), err
+}
+
+// RenderBody render the body with html/template or text/template, empty string is returned if no body template
+func (t Template) RenderBody(data *TemplateData, html bool) (string, error) {
+	if strings.TrimSpace(t.Body) == "" {
+		return "", nil
+	}
+	return execute("body", t.Body, html, data)
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	SetProbeContext("Test Name", ProbeContext{
+		Kind:   "http",
+		Labels: map[string]string{"env": "prod", "team": "sre"},
+	})
+
+	r := newResult()
+	r.Stat.UpTime = 3 * time.Second
+	r.Stat.DownTime = time.Second
+	r.Windows = probe.SLAWindows{"30d": 99.95}
+	data := NewTemplateData(r, "dummy")
+	assert.Equal(t, "http", data.Kind)
+	assert.Equal(t, "dummy", data.Notify)
+
+	tpl := Template{
+		Title: `[{{label .Labels "env"}}] {{.Name}} is {{.Status}}`,
+		Body: `{{emoji .Status}} {{.Title}} via {{.Notify}}
+rtt={{duration .RoundTripTime}} sla={{sla .Result}} uptime={{percent 99.5}} 30d={{window .Result "30d"}} 1h={{window .Result "1h"}}
+at={{time .StartTime}} day={{timeFormat .StartTime "2006/01/02"}}
+labels={{labels .Labels}} msg={{json .Message}} <b>`,
+	}
+	assert.Nil(t, tpl.Validate())
+	assert.False(t, tpl.IsEmpty())
+
+	title, err := tpl.RenderTitle(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "[prod] Test Name is down", title)
+
+	body, err := tpl.RenderBody(data, false)
+	assert.Nil(t, err)
+	assert.Contains(t, body, "❌ Test Name Failure via dummy")
+	assert.Contains(t, body, "rtt=1ms sla=75.00% uptime=99.50% 30d=99.95% 1h=-")
+	assert.Contains(t, body, "at=2022-01-01 00:00:00 day=2022/01/01")
+	assert.Contains(t, body, `labels=env=prod, team=sre msg="Error (http): timeout" <b>`)
+
+	// html/template escapes the values but not the template itself
+	tpl.Body = `<b>{{.Message}}</b><i>{{"<tag>"}}</i>`
+	body, err = tpl.RenderBody(data, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "<b>Error (http): timeout</b><i>&lt;tag&gt;</i>", body)
+	body, err = tpl.RenderBody(data, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "<b>Error (http): timeout</b><i><tag></i>", body)
+
+	// the templates are parsed once
+	cacheMutex.RLock()
+	_, html := templateCache[templateKey{"body", tpl.Body, true}]
+	_, text := templateCache[templateKey{"body", tpl.Body, false}]
+	cacheMutex.RUnlock()
+	assert.True(t, html)
+	assert.True(t, text)
+	tpl1, _ := parse("body", tpl.Body, true)
+	tpl2, _ := parse("body", tpl.Body, true)
+	assert.Same(t, tpl1, tpl2)
+}
+
+func TestTemplateMerge(t *testing.T) {
+	var empty Template
+	assert.True(t, empty.IsEmpty())
+	title, err := empty.RenderTitle(NewTemplateData(newResult(), ""))
+	assert.Nil(t, err)
+	assert.Equal(t, "", title)
+
+	fallback := Template{Title: "fallback title", Body: "fallback body"}
+	assert.Equal(t, fallback, empty.Merge(fallback))
+
+	probeTpl := Template{Title: "probe title"}
+	assert.Equal(t, Template{Title: "probe title", Body: "fallback body"}, probeTpl.Merge(fallback))
+}
+
+func TestTemplateInvalid(t *testing.T) {
+	tpl := Template{Title: "{{.Name"}
+	err := tpl.Validate()
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid title template"))
+
+	tpl = Template{Body: "{{.NoSuchField}}"}
+	err = tpl.Validate()
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid body template"))
+
+	tpl = Template{Body: "{{unknown .Name}}"}
+	assert.NotNil(t, tpl.Validate())
+
+	assert.Equal(t, ProbeContext{}, GetProbeContext("no such probe"))
+	data := NewTemplateData(probe.Result{Name: "no such probe"}, "")
+	assert.Nil(t, data.Labels)
+}
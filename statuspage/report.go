@@ -0,0 +1,237 @@
+package statuspage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// State is the state of the service
+type State string
+
+// The states of the service
+const (
+	StateOperational   State = "operational"
+	StateMaintenance   State = "maintenance"
+	StatePartialOutage State = "partial_outage"
+	StateMajorOutage   State = "major_outage"
+	StateUnknown       State = "unknown"
+)
+
+var stateTitles = map[State]string{
+	StateOperational:   "Operational",
+	StateMaintenance:   "Under Maintenance",
+	StatePartialOutage: "Partial Outage",
+	StateMajorOutage:   "Major Outage",
+	StateUnknown:       "Unknown",
+}
+
+// the severity of the state, the worst state is the state of the whole page
+var stateSeverity = map[State]int{
+	StateOperational:   0,
+	StateUnknown:       1,
+	StateMaintenance:   2,
+	StatePartialOutage: 3,
+	StateMajorOutage:   4,
+}
+
+// Title returns the title of the state
+func (s State) Title() string {
+	return stateTitles[s]
+}
+
+// publicProber is implemented by the probers which could be shown on the public status page
+type publicProber interface {
+	IsPublic() bool
+}
+
+// IsPublic returns true if the name and the messages of the prober could be shown publicly
+func IsPublic(p probe.Prober) bool {
+	pp, ok := p.(publicProber)
+	return ok && pp.IsPublic()
+}
+
+// Day is the uptime of one day of the service
+type Day struct {
+	Date    string  `json:"date"`
+	Percent float64 `json:"percent"` // -1 if there is no data
+}
+
+// ProbeStatus is the status of a public probe of the service
+type ProbeStatus struct {
+	Name   string       `json:"name"`
+	Status probe.Status `json:"status"`
+	Uptime float64      `json:"uptime"`
+}
+
+// ServiceStatus is the status of the service
+type ServiceStatus struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	State       State         `json:"state"`
+	Uptime      float64       `json:"uptime"` // -1 if there is no data
+	Days        []Day         `json:"days"`
+	Probes      []ProbeStatus `json:"probes"`
+}
+
+// Incident is the incident of the service,
+// the probe name and the message are only shown if the probe is public
+type Incident struct {
+	Service  string        `json:"service"`
+	Probe    string        `json:"probe,omitempty"`
+	Status   probe.Status  `json:"status"`
+	Message  string        `json:"message,omitempty"`
+	Start    time.Time     `json:"start"`
+	End      *time.Time    `json:"end,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Ongoing returns true if the incident is not resolved
+func (i Incident) Ongoing() bool {
+	return i.End == nil
+}
+
+// Report is the data of the status page
+type Report struct {
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	State       State           `json:"state"`
+	Time        time.Time       `json:"time"`
+	Services    []ServiceStatus `json:"services"`
+	Incidents   []Incident      `json:"incidents"`
+}
+
+// Build builds the report of the status page from the probers
+func (p *Page) Build(probers []probe.Prober, now time.Time) Report {
+	byName := map[string]probe.Prober{}
+	for _, pr := range probers {
+		byName[pr.Name()] = pr
+	}
+
+	report := Report{
+		Title:       p.Title,
+		Description: p.Description,
+		State:       StateOperational,
+		Time:        now,
+		Services:    []ServiceStatus{},
+		Incidents:   []Incident{},
+	}
+	for _, s := range p.Services {
+		list := []probe.Prober{}
+		for _, name := range s.Probes {
+			if pr, ok := byName[name]; ok {
+				list = append(list, pr)
+			}
+		}
+		ss := p.buildService(s, list, now)
+		if stateSeverity[ss.State] > stateSeverity[report.State] {
+			report.State = ss.State
+		}
+		report.Services = append(report.Services, ss)
+		report.Incidents = append(report.Incidents, buildIncidents(s.Name, list, now)...)
+	}
+	sort.SliceStable(report.Incidents, func(i, j int) bool {
+		return report.Incidents[i].Start.After(report.Incidents[j].Start)
+	})
+	return report
+}
+
+func (p *Page) buildService(s Service, probers []probe.Prober, now time.Time) ServiceStatus {
+	ss := ServiceStatus{
+		Name:        s.Name,
+		Description: s.Description,
+		State:       serviceState(probers),
+		Uptime:      -1,
+		Days:        []Day{},
+		Probes:      []ProbeStatus{},
+	}
+
+	// the results are copied once, they are changed by the probe goroutines
+	results := make([]probe.Result, len(probers))
+	for i, pr := range probers {
+		results[i] = probe.Snapshot(pr)
+	}
+
+	var up, down time.Duration
+	for i := p.Days - 1; i >= 0; i-- {
+		date := probe.DateOf(now.AddDate(0, 0, -i))
+		total := probe.DailyUptime{Date: date}
+		for _, r := range results {
+			d, _ := r.Daily.Get(date)
+			total.UpTime += d.UpTime
+			total.DownTime += d.DownTime
+		}
+		up += total.UpTime
+		down += total.DownTime
+		ss.Days = append(ss.Days, Day{Date: date, Percent: total.Percent()})
+	}
+	if up+down > 0 {
+		ss.Uptime = float64(up) / float64(up+down) * 100
+	}
+
+	for i, pr := range probers {
+		if !IsPublic(pr) {
+			continue
+		}
+		r := results[i]
+		ss.Probes = append(ss.Probes, ProbeStatus{Name: pr.Name(), Status: r.Status, Uptime: r.SLAPercent()})
+	}
+	return ss
+}
+
+// serviceState returns the state of the service by the status of its probes
+func serviceState(probers []probe.Prober) State {
+	known, failed, maintenance := 0, 0, 0
+	for _, pr := range probers {
+		r := probe.Snapshot(pr)
+		if r.Status == probe.StatusInit {
+			continue
+		}
+		known++
+		if r.Status != probe.StatusUp {
+			failed++
+			if r.Maintenance != "" {
+				maintenance++
+			}
+		}
+	}
+	switch {
+	case known == 0:
+		return StateUnknown
+	case failed == 0:
+		return StateOperational
+	case failed == maintenance:
+		return StateMaintenance
+	case failed < known:
+		return StatePartialOutage
+	default:
+		return StateMajorOutage
+	}
+}
+
+// buildIncidents returns the incidents of the probes of the service
+func buildIncidents(service string, probers []probe.Prober, now time.Time) []Incident {
+	list := []Incident{}
+	for _, pr := range probers {
+		public := IsPublic(pr)
+		for _, in := range probe.Snapshot(pr).Incidents {
+			i := Incident{
+				Service:  service,
+				Status:   in.Status,
+				Start:    in.Start,
+				Duration: in.Duration(now).Round(time.Second),
+			}
+			if !in.Ongoing() {
+				end := in.End
+				i.End = &end
+			}
+			if public {
+				i.Probe = pr.Name()
+				i.Message = in.Message
+			}
+			list = append(list, i)
+		}
+	}
+	return list
+}
@@ -0,0 +1,106 @@
+package statuspage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyProber struct {
+	name   string
+	public bool
+	result *probe.Result
+}
+
+func (d *dummyProber) LabelMap() prometheus.Labels          { return nil }
+func (d *dummyProber) SetLabelMap(labels prometheus.Labels) {}
+func (d *dummyProber) Kind() string                         { return "dummy" }
+func (d *dummyProber) Name() string                         { return d.name }
+func (d *dummyProber) Channels() []string                   { return nil }
+func (d *dummyProber) Timeout() time.Duration               { return time.Second }
+func (d *dummyProber) Interval() time.Duration              { return time.Minute }
+func (d *dummyProber) Result() *probe.Result                { return d.result }
+func (d *dummyProber) Config(global.ProbeSettings) error    { return nil }
+func (d *dummyProber) Probe() probe.Result                  { return *d.result }
+func (d *dummyProber) IsPublic() bool                       { return d.public }
+
+var _ probe.Prober = (*dummyProber)(nil)
+
+func newDummyProber(name string, public bool, status probe.Status) *dummyProber {
+	r := probe.NewResult()
+	r.Name = name
+	r.Status = status
+	return &dummyProber{name: name, public: public, result: r}
+}
+
+func TestServiceState(t *testing.T) {
+	up := newDummyProber("up", true, probe.StatusUp)
+	down := newDummyProber("down", true, probe.StatusDown)
+	init := newDummyProber("init", true, probe.StatusInit)
+	maint := newDummyProber("maint", true, probe.StatusDown)
+	maint.result.Maintenance = "upgrade"
+
+	assert.Equal(t, StateUnknown, serviceState(nil))
+	assert.Equal(t, StateUnknown, serviceState([]probe.Prober{init}))
+	assert.Equal(t, StateOperational, serviceState([]probe.Prober{up, init}))
+	assert.Equal(t, StatePartialOutage, serviceState([]probe.Prober{up, down}))
+	assert.Equal(t, StateMajorOutage, serviceState([]probe.Prober{down}))
+	assert.Equal(t, StateMaintenance, serviceState([]probe.Prober{up, maint}))
+	assert.Equal(t, "Partial Outage", StatePartialOutage.Title())
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+
+	web := newDummyProber("web", true, probe.StatusUp)
+	web.result.Daily.Add(yesterday, true, 3*time.Hour)
+	web.result.Daily.Add(now, true, time.Hour)
+	web.result.Incidents.Update(yesterday, probe.StatusDown, "web is down")
+	web.result.Incidents.Update(yesterday.Add(time.Minute), probe.StatusUp, "web is up")
+
+	internal := newDummyProber("internal", false, probe.StatusDown)
+	internal.result.Daily.Add(yesterday, false, time.Hour)
+	internal.result.Incidents.Update(now.Add(-time.Minute), probe.StatusDown, "10.0.0.1 refused")
+
+	page := Page{Title: "Status", Days: 7, Services: []Service{
+		{Name: "Website", Probes: []string{"web", "internal", "missing"}},
+		{Name: "Empty", Probes: []string{"missing"}},
+	}}
+	assert.Nil(t, page.Config())
+	r := page.Build([]probe.Prober{web, internal}, now)
+
+	assert.Equal(t, "Status", r.Title)
+	assert.Equal(t, StatePartialOutage, r.State)
+	assert.Equal(t, 2, len(r.Services))
+
+	s := r.Services[0]
+	assert.Equal(t, StatePartialOutage, s.State)
+	assert.Equal(t, 7, len(s.Days))
+	assert.Equal(t, probe.DateOf(now), s.Days[6].Date)
+	assert.Equal(t, float64(100), s.Days[6].Percent)
+	assert.Equal(t, float64(75), s.Days[5].Percent)
+	assert.Equal(t, float64(-1), s.Days[0].Percent)
+	assert.Equal(t, float64(80), s.Uptime)
+	// only the public probes are listed
+	assert.Equal(t, []ProbeStatus{{Name: "web", Status: probe.StatusUp, Uptime: 100}}, s.Probes)
+
+	assert.Equal(t, StateUnknown, r.Services[1].State)
+	assert.Equal(t, float64(-1), r.Services[1].Uptime)
+
+	// the newest incident first, the private probe and message are hidden
+	assert.Equal(t, 2, len(r.Incidents))
+	assert.Equal(t, "Website", r.Incidents[0].Service)
+	assert.Equal(t, "", r.Incidents[0].Probe)
+	assert.Equal(t, "", r.Incidents[0].Message)
+	assert.True(t, r.Incidents[0].Ongoing())
+	assert.Equal(t, time.Minute, r.Incidents[0].Duration)
+	assert.Equal(t, "web", r.Incidents[1].Probe)
+	assert.Equal(t, "web is down", r.Incidents[1].Message)
+	assert.False(t, r.Incidents[1].Ongoing())
+	assert.Equal(t, time.Minute, r.Incidents[1].Duration)
+}
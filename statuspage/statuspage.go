@@ -0,0 +1,84 @@
+// Package statuspage is the public status page which groups the probes into services
+package statuspage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/megaease/easeprobe/global"
+	log "github.com/sirupsen/logrus"
+)
+
+const kind = "StatusPage"
+
+// Service is a group of the probes shown as one item on the status page
+type Service struct {
+	Name        string   `yaml:"name" json:"name" jsonschema:"required,title=Name,description=the unique name of the service"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"title=Description,description=the description of the service"`
+	Probes      []string `yaml:"probes" json:"probes" jsonschema:"required,title=Probes,description=the names of the probes of the service"`
+}
+
+// Page is the configuration of the public status page
+type Page struct {
+	Title       string    `yaml:"title,omitempty" json:"title,omitempty" jsonschema:"title=Title,description=the title of the status page"`
+	Description string    `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"title=Description,description=the description of the status page"`
+	Days        int       `yaml:"days,omitempty" json:"days,omitempty" jsonschema:"title=Days,description=the number of the days of the uptime bars,minimum=1,maximum=90,default=90"`
+	Services    []Service `yaml:"services,omitempty" json:"services,omitempty" jsonschema:"title=Services,description=the services shown on the status page"`
+}
+
+var (
+	mutex sync.RWMutex
+	page  *Page
+)
+
+// Config checks the status page and sets the default values
+func (p *Page) Config() error {
+	if strings.TrimSpace(p.Title) == "" {
+		p.Title = global.GetEaseProbe().Name + " Status"
+	}
+	if p.Days <= 0 || p.Days > global.DefaultUptimeDays {
+		p.Days = global.DefaultUptimeDays
+	}
+	names := map[string]bool{}
+	for i := range p.Services {
+		s := &p.Services[i]
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" {
+			return fmt.Errorf("the name of the service #%d is empty", i+1)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicated service name [%s]", s.Name)
+		}
+		names[s.Name] = true
+		if len(s.Probes) == 0 {
+			return fmt.Errorf("the service [%s] has no probes", s.Name)
+		}
+	}
+	return nil
+}
+
+// SetPage sets the status page, the status page is disabled if it has no services or is invalid
+func SetPage(p Page) {
+	if len(p.Services) == 0 {
+		mutex.Lock()
+		page = nil
+		mutex.Unlock()
+		return
+	}
+	if err := p.Config(); err != nil {
+		log.Errorf("[%s] Bad status page configuration: %v", kind, err)
+		return
+	}
+	mutex.Lock()
+	page = &p
+	mutex.Unlock()
+	log.Infof("[%s] Status page is configured with %d services", kind, len(p.Services))
+}
+
+// GetPage returns the status page, nil if the status page is disabled
+func GetPage() *Page {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return page
+}
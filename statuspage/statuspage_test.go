@@ -0,0 +1,48 @@
+package statuspage
+
+import (
+	"testing"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageConfig(t *testing.T) {
+	global.InitEaseProbe("EaseProbe", "icon")
+
+	p := Page{Days: 100, Services: []Service{{Name: " Web ", Probes: []string{"web"}}}}
+	assert.Nil(t, p.Config())
+	assert.Equal(t, "EaseProbe Status", p.Title)
+	assert.Equal(t, global.DefaultUptimeDays, p.Days)
+	assert.Equal(t, "Web", p.Services[0].Name)
+
+	p = Page{Title: "My Status", Days: 30}
+	assert.Nil(t, p.Config())
+	assert.Equal(t, "My Status", p.Title)
+	assert.Equal(t, 30, p.Days)
+
+	p = Page{Services: []Service{{Name: "", Probes: []string{"web"}}}}
+	assert.NotNil(t, p.Config())
+	p = Page{Services: []Service{{Name: "Web"}}}
+	assert.NotNil(t, p.Config())
+	p = Page{Services: []Service{{Name: "Web", Probes: []string{"a"}}, {Name: "Web", Probes: []string{"b"}}}}
+	assert.NotNil(t, p.Config())
+}
+
+func TestSetPage(t *testing.T) {
+	defer SetPage(Page{})
+
+	SetPage(Page{})
+	assert.Nil(t, GetPage())
+
+	SetPage(Page{Services: []Service{{Name: "Web", Probes: []string{"web"}}}})
+	assert.NotNil(t, GetPage())
+	assert.Equal(t, global.DefaultUptimeDays, GetPage().Days)
+
+	// the invalid page is ignored
+	SetPage(Page{Services: []Service{{Name: "Web"}}})
+	assert.Equal(t, []string{"web"}, GetPage().Services[0].Probes)
+
+	SetPage(Page{})
+	assert.Nil(t, GetPage())
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// cachedSegments return the number of the segments which are indexed in memory for the retention,
+// it covers all of the days in the retention, the current day and the day which is partially in the retention
+func cachedSegments(retention time.Duration) int {
+	return int((retention+day-1)/day) + 2
+}
+
+// segmentIndex is the items of one segment indexed by the probe name,
+// the offset is the end of the last complete line which has been read
+type segmentIndex[T any] struct {
+	offset int64
+	items  map[string][]T
+	used   uint64
+}
+
+// segmentCache indexes the segments in memory, so that the queries don't decode the whole segments every time.
+// The segments are only appended between the compactions, so only the new lines are read when a segment grows,
+// and the cache is reset after the compaction. The least recently used segment is dropped if there are more
+// segments than the size, the size should cover the retention, otherwise the long queries would drop all of them.
+type segmentCache[T any] struct {
+	mutex    sync.Mutex
+	size     int
+	name     func(*T) *string // the name field of the item
+	segments map[string]*segmentIndex[T]
+	names    map[string]string // the probe names are shared by the indexes
+	tick     uint64
+}
+
+func newSegmentCache[T any](size int, name func(*T) *string) *segmentCache[T] {
+	return &segmentCache[T]{
+		size:     size,
+		name:     name,
+		segments: map[string]*segmentIndex[T]{},
+		names:    map[string]string{},
+	}
+}
+
+// get return the items of the probe in the segment, the returned slice is never changed
+// because the new items are only appended after its length
+func (c *segmentCache[T]) get(file, name string) []T {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	idx, ok := c.segments[file]
+	if !ok {
+		idx = &segmentIndex[T]{items: map[string][]T{}}
+	}
+	offset, err := readLines(file, idx.offset, func(line []byte) {
+		var v T
+		if err := json.Unmarshal(line, &v); err != nil {
+			log.Debugf("[%s] Skip the broken line of [%s]: %v", kind, file, err)
+			return
+		}
+		n := c.name(&v)
+		*n = c.intern(*n)
+		idx.items[*n] = append(idx.items[*n], v)
+	})
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("[%s] Cannot read the segment [%s]: %v", kind, file, err)
+		}
+		return nil
+	}
+	idx.offset = offset
+	c.tick++
+	idx.used = c.tick
+	if !ok {
+		c.segments[file] = idx
+		c.evict()
+	}
+	return idx.items[name]
+}
+
+// intern return the shared string of the probe name
+func (c *segmentCache[T]) intern(name string) string {
+	if n, ok := c.names[name]; ok {
+		return n
+	}
+	c.names[name] = name
+	return name
+}
+
+// evict drops the least recently used segments if there are too many
+func (c *segmentCache[T]) evict() {
+	for len(c.segments) > c.size {
+		oldest := ""
+		for f, idx := range c.segments {
+			if oldest == "" || idx.used < c.segments[oldest].used {
+				oldest = f
+			}
+		}
+		delete(c.segments, oldest)
+	}
+}
+
+// reset drops all of the indexed segments
+func (c *segmentCache[T]) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.segments = map[string]*segmentIndex[T]{}
+	c.names = map[string]string{}
+}
+
+// readLines calls the function for the complete lines from the offset, and return the end of the last complete line,
+// the last line which is partially written is left to the next read
+func readLines(file string, offset int64, fn func([]byte)) (int64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+		offset += int64(len(line))
+		fn(line[:len(line)-1])
+	}
+}
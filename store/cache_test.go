@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentCache(t *testing.T) {
+	size := 3
+	c := newSegmentCache(size, func(r *Record) *string { return &r.Name })
+	file := filepath.Join(t.TempDir(), "segment"+segmentExt)
+	assert.Nil(t, c.get(file, "web"))
+	assert.Equal(t, 0, len(c.segments))
+
+	write := func(s string) {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		assert.Nil(t, err)
+		f.WriteString(s)
+		f.Close()
+	}
+	write(`{"name":"web"}` + "\n" + `{"name":"tcp"}` + "\n{broken\n" + `{"name":"web"`)
+	assert.Equal(t, 1, len(c.get(file, "web")))
+	assert.Equal(t, 1, len(c.get(file, "tcp")))
+	assert.Equal(t, unsafe.StringData(c.names["web"]), unsafe.StringData(c.get(file, "web")[0].Name))
+
+	// only the new lines are read, the partially written line is read when it's completed
+	offset := c.segments[file].offset
+	write("}\n")
+	assert.Equal(t, 2, len(c.get(file, "web")))
+	assert.Greater(t, c.segments[file].offset, offset)
+
+	// the least recently used segments are dropped
+	for i := 0; i < size; i++ {
+		f := filepath.Join(t.TempDir(), fmt.Sprintf("%d%s", i, segmentExt))
+		assert.Nil(t, os.WriteFile(f, []byte(`{"name":"web"}`+"\n"), 0644))
+		assert.Equal(t, 1, len(c.get(f, "web")))
+	}
+	assert.Equal(t, size, len(c.segments))
+	assert.Nil(t, c.segments[file])
+
+	c.reset()
+	assert.Equal(t, 0, len(c.segments))
+
+	// the cache covers the whole days of the retention, the current day and the partial day
+	assert.Equal(t, 9, cachedSegments(7*day))
+	assert.Equal(t, 3, cachedSegments(time.Hour))
+}
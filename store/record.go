@@ -0,0 +1,197 @@
+package store
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// Record is one execution of the probe
+type Record struct {
+	Name     string        `json:"name"`
+	Time     time.Time     `json:"time"`
+	Status   probe.Status  `json:"status"`
+	RTT      time.Duration `json:"rtt"`
+	Hash     string        `json:"hash"` // the hash of the message, it's changed if the message is changed
+	Phases   *probe.Phases `json:"phases,omitempty"`
+	Excluded bool          `json:"excluded,omitempty"` // the execution is in the maintenance window which is excluded from the SLA
+}
+
+// HashMessage return the FNV-1a hash of the message in hex
+func HashMessage(msg string) string {
+	h := fnv.New64a()
+	h.Write([]byte(msg))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// NewRecord return the record of the probe result
+func NewRecord(r probe.Result) Record {
+	return Record{
+		Name:     r.Name,
+		Time:     r.StartTime.UTC(),
+		Status:   r.Status,
+		RTT:      r.RoundTripTime,
+		Hash:     HashMessage(r.Message),
+		Phases:   r.Phases.Clone(),
+		Excluded: r.SLAExcluded,
+	}
+}
+
+// HistoryRecord converts the record to the history record, the message is not kept in the store
+func (r Record) HistoryRecord() probe.HistoryRecord {
+	return probe.HistoryRecord{
+		Time:   r.Time,
+		Status: r.Status,
+		RTT:    r.RTT,
+	}
+}
+
+// Point is the aggregation of the records in one step of the time series
+type Point struct {
+	Time     time.Time     `json:"time"` // the start of the step
+	Count    int64         `json:"count"`
+	Up       int64         `json:"up"`
+	Down     int64         `json:"down"`
+	Excluded int64         `json:"excluded,omitempty"` // the records which are excluded from the SLA, they are neither up nor down
+	Uptime   float64       `json:"uptime"`             // the percentage of the up records which are not excluded
+	RTT      time.Duration `json:"rtt"`                // the average round trip time
+	MinRTT   time.Duration `json:"min_rtt"`
+	MaxRTT   time.Duration `json:"max_rtt"`
+	Phases   *probe.Phases `json:"phases,omitempty"` // the average of the traced phases
+}
+
+// aggregate is the sums of the records in one step, it's the format of the downsampled data,
+// the sums could be merged without losing the accuracy of the averages
+type aggregate struct {
+	Name     string        `json:"name"`
+	Time     time.Time     `json:"time"`
+	Count    int64         `json:"count"`
+	Up       int64         `json:"up"`
+	Down     int64         `json:"down"`
+	Excluded int64         `json:"excluded,omitempty"` // the number of the records which are excluded from the SLA
+	RTT      time.Duration `json:"rtt"`
+	MinRTT   time.Duration `json:"min_rtt"`
+	MaxRTT   time.Duration `json:"max_rtt"`
+	Traced   int64         `json:"traced,omitempty"` // the number of the records with the phases
+	Phases   probe.Phases  `json:"phases"`
+}
+
+func addPhases(dst *probe.Phases, p probe.Phases) {
+	dst.DNS += p.DNS
+	dst.Connect += p.Connect
+	dst.TLS += p.TLS
+	dst.Send += p.Send
+	dst.Wait += p.Wait
+	dst.Transfer += p.Transfer
+	dst.Total += p.Total
+}
+
+// add adds the record into the aggregate
+func (a *aggregate) add(r Record) {
+	n := aggregate{Count: 1, RTT: r.RTT, MinRTT: r.RTT, MaxRTT: r.RTT}
+	switch {
+	case r.Excluded:
+		n.Excluded = 1
+	case r.Status == probe.StatusUp:
+		n.Up = 1
+	case r.Status == probe.StatusDown:
+		n.Down = 1
+	}
+	if r.Phases != nil {
+		n.Traced = 1
+		n.Phases = *r.Phases
+	}
+	a.merge(n)
+}
+
+// merge merges the sums of the other aggregate
+func (a *aggregate) merge(o aggregate) {
+	if o.Count <= 0 {
+		return
+	}
+	if a.Count == 0 || o.MinRTT < a.MinRTT {
+		a.MinRTT = o.MinRTT
+	}
+	if a.Count == 0 || o.MaxRTT > a.MaxRTT {
+		a.MaxRTT = o.MaxRTT
+	}
+	a.Count += o.Count
+	a.Up += o.Up
+	a.Down += o.Down
+	a.Excluded += o.Excluded
+	a.RTT += o.RTT
+	a.Traced += o.Traced
+	addPhases(&a.Phases, o.Phases)
+}
+
+// point converts the sums to the averages
+func (a *aggregate) point() Point {
+	p := Point{
+		Time:     a.Time,
+		Count:    a.Count,
+		Up:       a.Up,
+		Down:     a.Down,
+		Excluded: a.Excluded,
+		MinRTT:   a.MinRTT,
+		MaxRTT:   a.MaxRTT,
+	}
+	if n := a.Count - a.Excluded; n > 0 {
+		p.Uptime = float64(a.Up) / float64(n) * 100
+	}
+	if a.Count > 0 {
+		p.RTT = a.RTT / time.Duration(a.Count)
+	}
+	if n := time.Duration(a.Traced); n > 0 {
+		p.Phases = &probe.Phases{
+			DNS:      a.Phases.DNS / n,
+			Connect:  a.Phases.Connect / n,
+			TLS:      a.Phases.TLS / n,
+			Send:     a.Phases.Send / n,
+			Wait:     a.Phases.Wait / n,
+			Transfer: a.Phases.Transfer / n,
+			Total:    a.Phases.Total / n,
+		}
+	}
+	return p
+}
+
+// series aggregates the records into the steps of the time series
+type series struct {
+	step  time.Duration
+	from  time.Time
+	steps map[int64]*aggregate
+}
+
+func newSeries(from time.Time, step time.Duration) *series {
+	return &series{step: step, from: from, steps: map[int64]*aggregate{}}
+}
+
+// get return the aggregate of the step which contains the time
+func (s *series) get(t time.Time) *aggregate {
+	start := s.from
+	if s.step > 0 {
+		start = t.Truncate(s.step)
+		if start.Before(s.from) {
+			start = s.from
+		}
+	}
+	key := start.UnixNano()
+	a, ok := s.steps[key]
+	if !ok {
+		a = &aggregate{Time: start}
+		s.steps[key] = a
+	}
+	return a
+}
+
+// points return the points in chronological order, the empty steps are omitted
+func (s *series) points() []Point {
+	list := make([]Point, 0, len(s.steps))
+	for _, a := range s.steps {
+		list = append(list, a.point())
+	}
+	sortPoints(list)
+	return list
+}
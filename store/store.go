@@ -0,0 +1,393 @@
+// Package store is the embedded time-series store of the probe executions.
+//
+// The executions are appended into the raw segment file of the day (UTC) as JSON lines,
+// the raw segments which are older than the raw retention are downsampled into the
+// aggregates of the resolution, and all of the segments older than the retention are removed.
+// The compaction runs in the background when the day changes, and the recent segments are indexed
+// in memory, so that the queries neither decode the whole segments every time nor block the appending.
+//
+//	<dir>/raw/2006-01-02.jsonl          - the raw records of the day
+//	<dir>/downsampled/2006-01-02.jsonl  - the aggregates of the day
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+const kind = "store"
+
+const (
+	rawDir         = "raw"
+	downsampledDir = "downsampled"
+	segmentExt     = ".jsonl"
+	dayFormat      = "2006-01-02"
+	day            = 24 * time.Hour
+)
+
+// Settings is the settings of the time-series store
+type Settings struct {
+	Dir        string        `yaml:"dir,omitempty"        json:"dir,omitempty"        jsonschema:"title=Directory,description=the directory of the time-series store of the probe executions ('-' means no store),default=data/store"`
+	Retention  time.Duration `yaml:"retention,omitempty"  json:"retention,omitempty"  jsonschema:"type=string,format=duration,title=Retention,description=how long the probe executions are kept,default=2160h"`
+	Raw        time.Duration `yaml:"raw,omitempty"        json:"raw,omitempty"        jsonschema:"type=string,format=duration,title=Raw Retention,description=how long every probe execution is kept before it is downsampled,default=168h"`
+	Resolution time.Duration `yaml:"resolution,omitempty" json:"resolution,omitempty" jsonschema:"type=string,format=duration,title=Resolution,description=the time span of the downsampled data,default=1h"`
+}
+
+// Enabled returns true if the directory of the store is set
+func (s Settings) Enabled() bool {
+	dir := strings.TrimSpace(s.Dir)
+	return dir != "" && dir != "-"
+}
+
+// Normalize returns the settings with the default values
+func (s Settings) Normalize() Settings {
+	s.Dir = strings.TrimSpace(s.Dir)
+	if s.Retention <= 0 {
+		s.Retention = global.DefaultStoreRetention
+	}
+	if s.Raw <= 0 {
+		s.Raw = global.DefaultStoreRawRetention
+	}
+	if s.Resolution <= 0 {
+		s.Resolution = global.DefaultStoreResolution
+	}
+	return s
+}
+
+// Check checks the settings
+func (s Settings) Check() error {
+	if s.Raw > s.Retention {
+		return fmt.Errorf("the raw retention [%s] should not be longer than the retention [%s]", s.Raw, s.Retention)
+	}
+	if s.Resolution < time.Minute || s.Resolution > day || day%s.Resolution != 0 {
+		return fmt.Errorf("invalid resolution [%s], it should divide one day and not be less than 1m", s.Resolution)
+	}
+	return nil
+}
+
+// Store is the embedded time-series store of the probe executions
+type Store struct {
+	Settings
+	mutex        sync.Mutex
+	file         *os.File     // the raw segment which is being appended
+	day          string       // the day of the raw segment which is being appended
+	compactMutex sync.RWMutex // the segments are not read while they are compacted
+	compacting   sync.WaitGroup
+	raw          *segmentCache[Record]
+	downsampled  *segmentCache[aggregate]
+}
+
+// Open opens the store in the directory of the settings, the expired data is downsampled or removed
+func Open(s Settings) (*Store, error) {
+	s = s.Normalize()
+	if !s.Enabled() {
+		return nil, fmt.Errorf("the directory of the store is not set")
+	}
+	if err := s.Check(); err != nil {
+		return nil, err
+	}
+	for _, d := range []string{rawDir, downsampledDir} {
+		if err := os.MkdirAll(filepath.Join(s.Dir, d), 0755); err != nil {
+			return nil, err
+		}
+	}
+	st := &Store{
+		Settings:    s,
+		raw:         newSegmentCache(cachedSegments(s.Raw), func(r *Record) *string { return &r.Name }),
+		downsampled: newSegmentCache(cachedSegments(s.Retention), func(a *aggregate) *string { return &a.Name }),
+	}
+	st.Compact(time.Now())
+	return st, nil
+}
+
+func (s *Store) segment(dir string, t time.Time) string {
+	return filepath.Join(s.Dir, dir, t.UTC().Format(dayFormat)+segmentExt)
+}
+
+// Append appends the probe result into the store, the data is compacted in the background when the day changes
+func (s *Store) Append(r probe.Result) error {
+	if s == nil {
+		return nil
+	}
+	rec := NewRecord(r)
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if d := rec.Time.Format(dayFormat); d != s.day || s.file == nil {
+		if s.file != nil {
+			s.file.Close()
+			s.file = nil
+		}
+		f, err := os.OpenFile(s.segment(rawDir, rec.Time), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		rolled := s.day != ""
+		s.file, s.day = f, d
+		if rolled {
+			s.compacting.Add(1)
+			go func(now time.Time) {
+				defer s.compacting.Done()
+				s.Compact(now)
+			}(rec.Time)
+		}
+	}
+	_, err = s.file.Write(append(buf, '\n'))
+	return err
+}
+
+// Close closes the raw segment which is being appended, and waits for the background compaction
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.compacting.Wait()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file, s.day = nil, ""
+	return err
+}
+
+// Compact downsamples the raw segments older than the raw retention,
+// and removes the segments older than the retention
+func (s *Store) Compact(now time.Time) {
+	if s == nil {
+		return
+	}
+	s.compactMutex.Lock()
+	defer s.compactMutex.Unlock()
+	s.compact(now)
+	s.raw.reset()
+	s.downsampled.reset()
+}
+
+func (s *Store) compact(now time.Time) {
+	for _, t := range s.days(rawDir) {
+		if t.Add(day).After(now.Add(-s.Raw)) {
+			continue
+		}
+		if err := s.downsample(t); err != nil {
+			log.Errorf("[%s] Cannot downsample the segment of %s: %v", kind, t.Format(dayFormat), err)
+		}
+	}
+	for _, dir := range []string{rawDir, downsampledDir} {
+		for _, t := range s.days(dir) {
+			if t.Add(day).After(now.Add(-s.Retention)) {
+				continue
+			}
+			file := s.segment(dir, t)
+			if err := os.Remove(file); err != nil {
+				log.Errorf("[%s] Cannot remove the expired segment [%s]: %v", kind, file, err)
+				continue
+			}
+			log.Debugf("[%s] The expired segment [%s] is removed", kind, file)
+		}
+	}
+}
+
+// downsample aggregates the raw segment of the day into the downsampled segment, then removes the raw segment
+func (s *Store) downsample(t time.Time) error {
+	aggs := map[string]*series{}
+	get := func(name string, at time.Time) *aggregate {
+		if _, ok := aggs[name]; !ok {
+			aggs[name] = newSeries(t, s.Resolution)
+		}
+		a := aggs[name].get(at)
+		a.Name = name
+		return a
+	}
+	// the segment could be downsampled partially if it's appended after the downsampling
+	err := readSegment(s.segment(downsampledDir, t), func(a aggregate) {
+		get(a.Name, a.Time).merge(a)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	raw := s.segment(rawDir, t)
+	if err := readSegment(raw, func(r Record) { get(r.Name, r.Time).add(r) }); err != nil {
+		return err
+	}
+
+	list := []*aggregate{}
+	for _, ser := range aggs {
+		for _, a := range ser.steps {
+			list = append(list, a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Time.Equal(list[j].Time) {
+			return list[i].Time.Before(list[j].Time)
+		}
+		return list[i].Name < list[j].Name
+	})
+	if err := writeSegment(s.segment(downsampledDir, t), list); err != nil {
+		return err
+	}
+	log.Infof("[%s] The segment of %s is downsampled to %d points", kind, t.Format(dayFormat), len(list))
+	return os.Remove(raw)
+}
+
+// days return the days of the segments in the directory in chronological order
+func (s *Store) days(dir string) []time.Time {
+	files, err := filepath.Glob(filepath.Join(s.Dir, dir, "*"+segmentExt))
+	if err != nil {
+		return nil
+	}
+	list := []time.Time{}
+	for _, f := range files {
+		t, err := time.Parse(dayFormat, strings.TrimSuffix(filepath.Base(f), segmentExt))
+		if err != nil {
+			continue
+		}
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Before(list[j]) })
+	return list
+}
+
+// dayOf return the beginning of the day (UTC) of the time
+func dayOf(t time.Time) time.Time {
+	return t.UTC().Truncate(day)
+}
+
+// readSegment reads the JSON lines of the segment, the broken lines are skipped,
+// e.g. the last line which is partially written when the process is killed
+func readSegment[T any](file string, fn func(T)) error {
+	_, err := readLines(file, 0, func(line []byte) {
+		var v T
+		if err := json.Unmarshal(line, &v); err != nil {
+			log.Debugf("[%s] Skip the broken line of [%s]: %v", kind, file, err)
+			return
+		}
+		fn(v)
+	})
+	return err
+}
+
+// writeSegment writes the JSON lines into the temporary file, then renames it to the segment
+func writeSegment[T any](file string, list []T) error {
+	tmp := file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, v := range list {
+		if err = enc.Encode(v); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, file)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// scan calls the functions for the raw records and the aggregates of the probe in [from, to),
+// the segments are read from the index in memory, so the appending is not blocked
+func (s *Store) scan(name string, from, to time.Time, rec func(Record), agg func(aggregate)) {
+	s.compactMutex.RLock()
+	defer s.compactMutex.RUnlock()
+	in := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	// the segments older than the retention have been removed
+	start := dayOf(from)
+	if oldest := dayOf(time.Now().Add(-s.Retention)); start.Before(oldest) {
+		start = oldest
+	}
+	for t := start; t.Before(to); t = t.Add(day) {
+		if rec != nil {
+			for _, r := range s.raw.get(s.segment(rawDir, t), name) {
+				if in(r.Time) {
+					rec(r)
+				}
+			}
+		}
+		if agg != nil {
+			for _, a := range s.downsampled.get(s.segment(downsampledDir, t), name) {
+				if in(a.Time) {
+					agg(a)
+				}
+			}
+		}
+	}
+}
+
+// Records returns the raw records of the probe in [from, to) in chronological order,
+// the records older than the raw retention have been downsampled, so they are not returned
+func (s *Store) Records(name string, from, to time.Time) []Record {
+	list := []Record{}
+	if s == nil {
+		return list
+	}
+	s.scan(name, from, to, func(r Record) { list = append(list, r) }, nil)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+	return list
+}
+
+// Series returns the points of the probe in [from, to), the records are aggregated by the step
+// which is aligned to the step in UTC, the whole period is one point if the step is zero.
+// The downsampled data is aggregated into the step which contains its start time,
+// so the step should not be less than the resolution if the period is older than the raw retention.
+func (s *Store) Series(name string, from, to time.Time, step time.Duration) []Point {
+	if s == nil {
+		return []Point{}
+	}
+	ser := newSeries(from, step)
+	s.scan(name, from, to,
+		func(r Record) { ser.get(r.Time).add(r) },
+		func(a aggregate) { ser.get(a.Time).merge(a) })
+	return ser.points()
+}
+
+func sortPoints(list []Point) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+}
+
+var (
+	defaultStore *Store
+	mutex        = &sync.RWMutex{}
+)
+
+// SetDefault sets the store which is used by the dashboard, the SLA reports and the APIs
+func SetDefault(s *Store) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	defaultStore = s
+}
+
+// Default returns the default store, nil is returned if the store is disabled,
+// all of the methods of the nil store are no-op
+func Default() *Store {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return defaultStore
+}
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResult(name string, t time.Time, status probe.Status, rtt time.Duration) probe.Result {
+	r := probe.NewResultWithName(name)
+	r.StartTime = t
+	r.Status = status
+	r.RoundTripTime = rtt
+	r.Message = status.String()
+	return *r
+}
+
+func newStore(t *testing.T) *Store {
+	s, err := Open(Settings{Dir: t.TempDir(), Retention: 10 * day, Raw: 2 * day})
+	assert.Nil(t, err)
+	return s
+}
+
+func TestSettings(t *testing.T) {
+	s := Settings{Dir: " dir "}.Normalize()
+	assert.True(t, s.Enabled())
+	assert.Equal(t, "dir", s.Dir)
+	assert.Equal(t, global.DefaultStoreRetention, s.Retention)
+	assert.Equal(t, global.DefaultStoreRawRetention, s.Raw)
+	assert.Equal(t, global.DefaultStoreResolution, s.Resolution)
+	assert.Nil(t, s.Check())
+
+	assert.False(t, Settings{}.Enabled())
+	assert.False(t, Settings{Dir: "-"}.Enabled())
+
+	for _, bad := range []Settings{
+		{Retention: time.Hour, Raw: day, Resolution: time.Hour},
+		{Retention: day, Raw: day, Resolution: time.Second},
+		{Retention: day, Raw: day, Resolution: 7 * time.Hour},
+		{Retention: 3 * day, Raw: day, Resolution: 2 * day},
+	} {
+		assert.NotNil(t, bad.Check(), bad)
+	}
+
+	_, err := Open(Settings{Dir: "-"})
+	assert.NotNil(t, err)
+	_, err = Open(Settings{Dir: t.TempDir(), Resolution: 7 * time.Hour})
+	assert.NotNil(t, err)
+}
+
+func TestAppendAndQuery(t *testing.T) {
+	s := newStore(t)
+	defer s.Close()
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	start := now.Add(-3 * time.Hour)
+	for i := 0; i < 6; i++ {
+		status := probe.StatusUp
+		if i%3 == 0 {
+			status = probe.StatusDown
+		}
+		r := newResult("web", start.Add(time.Duration(i)*30*time.Minute), status, time.Duration(i+1)*time.Millisecond)
+		if i%2 == 0 {
+			r.Phases = &probe.Phases{DNS: time.Millisecond, Total: time.Duration(i+1) * time.Millisecond}
+		}
+		assert.Nil(t, s.Append(r))
+		assert.Nil(t, s.Append(newResult("tcp", r.StartTime, probe.StatusUp, time.Millisecond)))
+	}
+
+	records := s.Records("web", start, now)
+	assert.Equal(t, 6, len(records))
+	assert.Equal(t, start, records[0].Time)
+	assert.Equal(t, probe.StatusDown, records[0].Status)
+	assert.Equal(t, HashMessage("down"), records[0].Hash)
+	assert.NotNil(t, records[0].Phases)
+	assert.Nil(t, records[1].Phases)
+	assert.Equal(t, probe.HistoryRecord{Time: start, Status: probe.StatusDown, RTT: time.Millisecond},
+		records[0].HistoryRecord())
+
+	// [from, to)
+	assert.Equal(t, 2, len(s.Records("web", start.Add(30*time.Minute), start.Add(90*time.Minute))))
+	assert.Equal(t, 0, len(s.Records("none", start, now)))
+
+	points := s.Series("web", start, now, time.Hour)
+	assert.Equal(t, 3, len(points))
+	assert.Equal(t, Point{
+		Time: start, Count: 2, Up: 1, Down: 1, Uptime: 50,
+		RTT: 1500 * time.Microsecond, MinRTT: time.Millisecond, MaxRTT: 2 * time.Millisecond,
+		Phases: &probe.Phases{DNS: time.Millisecond, Total: time.Millisecond},
+	}, points[0])
+
+	// one point of the whole period
+	points = s.Series("web", start, now, 0)
+	assert.Equal(t, 1, len(points))
+	assert.Equal(t, start, points[0].Time)
+	assert.Equal(t, int64(6), points[0].Count)
+	assert.Equal(t, int64(2), points[0].Down)
+	assert.Equal(t, 3*time.Millisecond, points[0].Phases.Total)
+
+	// the records which are excluded from the SLA are neither up nor down
+	excluded := newResult("web", now, probe.StatusDown, time.Millisecond)
+	excluded.SLAExcluded = true
+	assert.Nil(t, s.Append(excluded))
+	assert.True(t, s.Records("web", now, now.Add(time.Second))[0].Excluded)
+	points = s.Series("web", start, now.Add(time.Second), 0)
+	assert.Equal(t, int64(7), points[0].Count)
+	assert.Equal(t, int64(2), points[0].Down)
+	assert.Equal(t, int64(1), points[0].Excluded)
+	assert.Equal(t, float64(4)/6*100, points[0].Uptime)
+
+	// nil store
+	var n *Store
+	assert.Nil(t, n.Append(records[0].toResult()))
+	assert.Empty(t, n.Records("web", start, now))
+	assert.Empty(t, n.Series("web", start, now, time.Hour))
+	assert.Nil(t, n.Close())
+	n.Compact(now)
+}
+
+func (r Record) toResult() probe.Result {
+	return newResult(r.Name, r.Time, r.Status, r.RTT)
+}
+
+func TestDownsample(t *testing.T) {
+	s := newStore(t)
+	now := time.Now().UTC()
+	old := dayOf(now).Add(-5 * day)
+	for i := 0; i < 4; i++ {
+		status := probe.StatusUp
+		if i == 0 {
+			status = probe.StatusDown
+		}
+		assert.Nil(t, s.Append(newResult("web", old.Add(time.Duration(i)*20*time.Minute), status, 10*time.Millisecond)))
+	}
+	// a broken line is skipped
+	f, err := os.OpenFile(s.segment(rawDir, old), os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Nil(t, err)
+	f.WriteString("{broken\n")
+	f.Close()
+	// the day is changed, the old segment is downsampled
+	assert.Nil(t, s.Append(newResult("web", now, probe.StatusUp, time.Millisecond)))
+	assert.Nil(t, s.Close())
+
+	_, err = os.Stat(s.segment(rawDir, old))
+	assert.True(t, os.IsNotExist(err))
+	assert.Empty(t, s.Records("web", old, old.Add(day)))
+
+	points := s.Series("web", old, now.Add(time.Second), day)
+	assert.Equal(t, 2, len(points))
+	assert.Equal(t, int64(4), points[0].Count)
+	assert.Equal(t, int64(1), points[0].Down)
+	assert.Equal(t, 75.0, points[0].Uptime)
+	assert.Equal(t, 10*time.Millisecond, points[0].RTT)
+	assert.Equal(t, int64(1), points[1].Count)
+
+	hourly := s.Series("web", old, old.Add(day), time.Hour)
+	assert.Equal(t, 2, len(hourly))
+	assert.Equal(t, int64(3), hourly[0].Count)
+
+	// the late records are merged into the downsampled data
+	assert.Nil(t, s.Append(newResult("web", old.Add(time.Hour), probe.StatusUp, 10*time.Millisecond)))
+	s.Compact(now)
+	assert.Nil(t, s.Close())
+	hourly = s.Series("web", old, old.Add(day), time.Hour)
+	assert.Equal(t, 2, len(hourly))
+	assert.Equal(t, int64(2), hourly[1].Count)
+
+	// the expired segments are removed
+	s.Compact(now.Add(6 * day))
+	assert.Empty(t, s.Series("web", old, old.Add(day), time.Hour))
+	files, _ := filepath.Glob(filepath.Join(s.Dir, "*", "*"+segmentExt))
+	assert.Equal(t, 1, len(files))
+}
+
+func TestAppendWhileQuery(t *testing.T) {
+	s := newStore(t)
+	defer s.Close()
+	now := time.Now().UTC()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, s.Append(newResult("web", now.Add(time.Duration(i)*time.Millisecond), probe.StatusUp, time.Millisecond)))
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		assert.LessOrEqual(t, len(s.Records("web", now, now.Add(time.Second))), 100)
+	}
+	<-done
+	assert.Equal(t, 100, len(s.Records("web", now, now.Add(time.Second))))
+	points := s.Series("web", now, now.Add(time.Second), 0)
+	assert.Equal(t, int64(100), points[0].Count)
+}
+
+// newLongStore return the store with the records of every 10 minutes in the last `days` days of the probes
+func newLongStore(t testing.TB, days int, probes ...string) (*Store, time.Time) {
+	s, err := Open(Settings{Dir: t.TempDir(), Retention: 90 * day, Raw: time.Duration(days+1) * day})
+	assert.Nil(t, err)
+	now := time.Now().UTC()
+	start := dayOf(now).Add(-time.Duration(days) * day)
+	for ts := start; ts.Before(now); ts = ts.Add(10 * time.Minute) {
+		for _, name := range probes {
+			assert.Nil(t, s.Append(newResult(name, ts, probe.StatusUp, time.Millisecond)))
+		}
+	}
+	// the compactions of the day changes reset the cache
+	s.compacting.Wait()
+	return s, start
+}
+
+func TestLongQuery(t *testing.T) {
+	days := 20
+	s, start := newLongStore(t, days, "web", "tcp")
+	defer s.Close()
+	now := time.Now().UTC()
+
+	points := s.Series("web", start, now, 0)
+	assert.Equal(t, 1, len(points))
+	total := points[0].Count
+
+	// all of the segments of the query are kept in the cache, so they're not read again by the next query
+	assert.Equal(t, days+1, len(s.raw.segments))
+	indexes := map[string]*segmentIndex[Record]{}
+	for f, idx := range s.raw.segments {
+		indexes[f] = idx
+	}
+	assert.Equal(t, total, s.Series("tcp", start, now, 0)[0].Count)
+	assert.Equal(t, total, s.Series("web", start, now, 0)[0].Count)
+	for f, idx := range s.raw.segments {
+		assert.Same(t, indexes[f], idx, f)
+	}
+}
+
+func BenchmarkSeries(b *testing.B) {
+	names := make([]string, 20)
+	for i := range names {
+		names[i] = fmt.Sprintf("probe-%d", i)
+	}
+	s, start := newLongStore(b, 30, names...)
+	defer s.Close()
+	now := time.Now().UTC()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			s.Series(name, start, now, 0)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	assert.Nil(t, Default())
+	s := newStore(t)
+	SetDefault(s)
+	defer SetDefault(nil)
+	assert.Equal(t, s, Default())
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/conf"
+	log "github.com/sirupsen/logrus"
+)
+
+// apiRoutes registers the routes of the RESTful API,
+// the routes which change the state of the probes always require the authentication
+func apiRoutes(r chi.Router, auth conf.HTTPAuth) {
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/probes", listProbesHandler)
+		r.Get("/probes/{name}", getProbeHandler)
+		r.Get("/probes/{name}/history", historyHandler)
+		r.Get("/probes/{name}/series", seriesHandler)
+		r.Get("/sla", slaHandler)
+		r.Get("/events", eventsHandler)
+		r.Get("/maintenance", listMaintenanceHandler)
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireAuth(auth))
+			r.Post("/probes/{name}/run", controlHandler(runHandler))
+			r.Post("/probes/{name}/pause", controlHandler(pauseHandler))
+			r.Post("/probes/{name}/resume", controlHandler(resumeHandler))
+			r.Post("/probes/{name}/mute", controlHandler(muteHandler))
+			r.Delete("/probes/{name}/mute", controlHandler(unmuteHandler))
+			r.Post("/maintenance", addMaintenanceHandler)
+			r.Delete("/maintenance/{name}", deleteMaintenanceHandler)
+		})
+	})
+}
+
+// apiError is the error response of the API
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("[Web] Failed to write the response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, apiError{Error: err.Error()})
+}
+
+// urlParam returns the unescaped URL parameter, the name of the probe could contain spaces
+func urlParam(req *http.Request, key string) string {
+	v := chi.URLParam(req, key)
+	if s, err := url.PathUnescape(v); err == nil {
+		return s
+	}
+	return v
+}
@@ -0,0 +1,101 @@
+package web
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/megaease/easeprobe/conf"
+	log "github.com/sirupsen/logrus"
+)
+
+// publicPaths are always open, the status page only shows the public information,
+// and the health checks are used by the orchestrator, e.g. Kubernetes
+var publicPaths = []string{"/status", "/status.json", "/healthz", "/readyz"}
+
+var errAuthRequired = errors.New("the authentication (settings.http.auth) is required to change the state of the probes")
+
+// authEnabled returns true if the basic or bearer token authentication is configured
+func authEnabled(auth conf.HTTPAuth) bool {
+	return auth.Username != "" || auth.Token != ""
+}
+
+// isOpenPath returns true if the path is the open path or under it
+func isOpenPath(path string, open []string) bool {
+	for _, p := range open {
+		p = strings.TrimSuffix(strings.TrimSpace(p), "/")
+		if p == "" {
+			continue
+		}
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// secureEqual compares the strings in constant time
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// authorized checks the basic or bearer token credentials of the request
+func authorized(auth conf.HTTPAuth, req *http.Request) bool {
+	if auth.Token != "" {
+		h := req.Header.Get("Authorization")
+		if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") && secureEqual(strings.TrimSpace(h[7:]), auth.Token) {
+			return true
+		}
+	}
+	if auth.Username != "" {
+		user, pass, ok := req.BasicAuth()
+		if ok && secureEqual(user, auth.Username) && secureEqual(pass, auth.Password) {
+			return true
+		}
+	}
+	return false
+}
+
+// authMiddleware requires the basic or bearer token authentication except for the open paths
+func authMiddleware(auth conf.HTTPAuth) func(http.Handler) http.Handler {
+	open := append(append([]string{}, publicPaths...), auth.Open...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if isOpenPath(req.URL.Path, open) || authorized(auth, req) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			unauthorized(w, req, auth)
+		})
+	}
+}
+
+// requireAuth protects the routes which change the state of the probes, they are forbidden if
+// the authentication is not configured, and the credentials are required even for the open paths
+func requireAuth(auth conf.HTTPAuth) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if !authEnabled(auth) {
+				log.Debugf("[Web] Forbidden request without the authentication: %s %s from %s", req.Method, req.URL.Path, req.RemoteAddr)
+				writeError(w, http.StatusForbidden, errAuthRequired)
+				return
+			}
+			if !authorized(auth, req) {
+				unauthorized(w, req, auth)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, req *http.Request, auth conf.HTTPAuth) {
+	log.Debugf("[Web] Unauthorized request: %s %s from %s", req.Method, req.URL.Path, req.RemoteAddr)
+	if auth.Username != "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="EaseProbe", charset="UTF-8"`)
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="EaseProbe"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
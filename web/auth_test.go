@@ -0,0 +1,133 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthServer(auth conf.HTTPAuth) *httptest.Server {
+	r := chi.NewRouter()
+	r.Use(authMiddleware(auth))
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	r.Get("/metrics", ok)
+	r.Get("/status", ok)
+	r.Get("/readyz", ok)
+	r.Get("/api/v1/probes", ok)
+	return httptest.NewServer(r)
+}
+
+func authRequest(t *testing.T, url string, set func(req *http.Request)) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.Nil(t, err)
+	if set != nil {
+		set(req)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestAuthEnabled(t *testing.T) {
+	assert.False(t, authEnabled(conf.HTTPAuth{}))
+	assert.False(t, authEnabled(conf.HTTPAuth{Open: []string{"/metrics"}}))
+	assert.True(t, authEnabled(conf.HTTPAuth{Username: "admin"}))
+	assert.True(t, authEnabled(conf.HTTPAuth{Token: "token"}))
+}
+
+func TestIsOpenPath(t *testing.T) {
+	open := []string{"/metrics", "/api/v1/probes/", " ", ""}
+	assert.True(t, isOpenPath("/metrics", open))
+	assert.True(t, isOpenPath("/api/v1/probes", open))
+	assert.True(t, isOpenPath("/api/v1/probes/web", open))
+	assert.False(t, isOpenPath("/metrics2", open))
+	assert.False(t, isOpenPath("/", open))
+	assert.False(t, isOpenPath("/api/v1/maintenance", open))
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	srv := newAuthServer(conf.HTTPAuth{Username: "admin", Password: "secret", Token: "abc", Open: []string{"/metrics"}})
+	defer srv.Close()
+	api := srv.URL + "/api/v1/probes"
+
+	// the open paths and the status page
+	assert.Equal(t, http.StatusOK, authRequest(t, srv.URL+"/metrics", nil).StatusCode)
+	assert.Equal(t, http.StatusOK, authRequest(t, srv.URL+"/status", nil).StatusCode)
+	assert.Equal(t, http.StatusOK, authRequest(t, srv.URL+"/readyz", nil).StatusCode)
+
+	resp := authRequest(t, api, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+
+	// basic authentication
+	resp = authRequest(t, api, func(req *http.Request) { req.SetBasicAuth("admin", "secret") })
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = authRequest(t, api, func(req *http.Request) { req.SetBasicAuth("admin", "wrong") })
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// bearer token
+	resp = authRequest(t, api, func(req *http.Request) { req.Header.Set("Authorization", "Bearer abc") })
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = authRequest(t, api, func(req *http.Request) { req.Header.Set("Authorization", "bearer abc") })
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = authRequest(t, api, func(req *http.Request) { req.Header.Set("Authorization", "Bearer xyz") })
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// only the bearer token is configured
+	srv2 := newAuthServer(conf.HTTPAuth{Token: "abc"})
+	defer srv2.Close()
+	resp = authRequest(t, srv2.URL+"/metrics", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Bearer")
+	resp = authRequest(t, srv2.URL+"/metrics", func(req *http.Request) { req.SetBasicAuth("", "abc") })
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestControlAuth(t *testing.T) {
+	SetProbers(newDummyProbers())
+	defer SetProbers(nil)
+
+	request := func(srv *httptest.Server, method, path string, set func(req *http.Request)) int {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		assert.Nil(t, err)
+		if set != nil {
+			set(req)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// the control and maintenance APIs are forbidden without the authentication
+	r := chi.NewRouter()
+	apiRoutes(r, conf.HTTPAuth{})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	assert.Equal(t, http.StatusOK, request(srv, http.MethodGet, "/api/v1/probes", nil))
+	assert.Equal(t, http.StatusOK, request(srv, http.MethodGet, "/api/v1/maintenance", nil))
+	for _, path := range []string{"/api/v1/probes/Database/run", "/api/v1/probes/Database/pause",
+		"/api/v1/probes/Database/resume", "/api/v1/probes/Database/mute", "/api/v1/maintenance"} {
+		assert.Equal(t, http.StatusForbidden, request(srv, http.MethodPost, path, nil), path)
+	}
+	assert.Equal(t, http.StatusForbidden, request(srv, http.MethodDelete, "/api/v1/probes/Database/mute", nil))
+	assert.Equal(t, http.StatusForbidden, request(srv, http.MethodDelete, "/api/v1/maintenance/name", nil))
+
+	// the credentials are required even if the path is open
+	auth := conf.HTTPAuth{Username: "admin", Password: "secret", Open: []string{"/api"}}
+	r = chi.NewRouter()
+	r.Use(authMiddleware(auth))
+	apiRoutes(r, auth)
+	srv2 := httptest.NewServer(r)
+	defer srv2.Close()
+	assert.Equal(t, http.StatusOK, request(srv2, http.MethodGet, "/api/v1/probes", nil))
+	assert.Equal(t, http.StatusUnauthorized, request(srv2, http.MethodPost, "/api/v1/probes/Database/pause", nil))
+	basic := func(req *http.Request) { req.SetBasicAuth("admin", "secret") }
+	assert.Equal(t, http.StatusOK, request(srv2, http.MethodPost, "/api/v1/probes/Database/pause", basic))
+	assert.Equal(t, http.StatusOK, request(srv2, http.MethodPost, "/api/v1/probes/Database/resume", basic))
+}
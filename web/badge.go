@@ -0,0 +1,197 @@
+package web
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/conf"
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// the colours of the badges
+const (
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+	badgeBlue   = "#007ec6"
+	badgeGrey   = "#9f9f9f"
+)
+
+var statusBadgeColor = map[probe.Status]string{
+	probe.StatusInit:    badgeBlue,
+	probe.StatusUp:      badgeGreen,
+	probe.StatusDown:    badgeRed,
+	probe.StatusUnknown: badgeYellow,
+	probe.StatusBad:     badgeYellow,
+}
+
+// badgeFuncType returns the label, the value and the colour of the badge
+type badgeFuncType func(b conf.Badge, p probe.Prober) (string, string, string)
+
+// badgeRoutes registers the routes of the badges, the label could be changed by ?label=
+func badgeRoutes(r chi.Router) {
+	r.Get("/badge/{name}", badgeHandler(statusBadge))
+	r.Get("/badge/{name}/sla", badgeHandler(slaBadge))
+	r.Get("/badge/{name}/rtt", badgeHandler(rttBadge))
+}
+
+// badgeSettings returns the badge settings of the configuration
+func badgeSettings() conf.Badge {
+	if c := conf.Get(); c != nil {
+		return normalizeBadge(c.Settings.HTTPServer.Badge)
+	}
+	return normalizeBadge(conf.Badge{})
+}
+
+// normalizeBadge sets the default values of the badge settings
+func normalizeBadge(b conf.Badge) conf.Badge {
+	if strings.TrimSpace(b.StatusLabel) == "" {
+		b.StatusLabel = "status"
+	}
+	if strings.TrimSpace(b.SLALabel) == "" {
+		b.SLALabel = "sla"
+	}
+	if strings.TrimSpace(b.RTTLabel) == "" {
+		b.RTTLabel = "rtt"
+	}
+	if b.SLAGood <= 0 {
+		b.SLAGood = 99.9
+	}
+	if b.SLAWarn <= 0 || b.SLAWarn > b.SLAGood {
+		b.SLAWarn = math.Min(99, b.SLAGood)
+	}
+	if b.RTTGood <= 0 {
+		b.RTTGood = 500 * time.Millisecond
+	}
+	if b.RTTWarn < b.RTTGood {
+		b.RTTWarn = 4 * b.RTTGood
+	}
+	return b
+}
+
+func statusBadge(b conf.Badge, p probe.Prober) (string, string, string) {
+	r := probe.Snapshot(p)
+	switch {
+	case probe.GetControl(p.Name()).Paused:
+		return b.StatusLabel, "paused", badgeGrey
+	case r.Maintenance != "":
+		return b.StatusLabel, "maintenance", badgeBlue
+	}
+	color, ok := statusBadgeColor[r.Status]
+	if !ok {
+		color = badgeGrey
+	}
+	return b.StatusLabel, r.Status.String(), color
+}
+
+func slaBadge(b conf.Badge, p probe.Prober) (string, string, string) {
+	r := probe.Snapshot(p)
+	sla := r.SLAPercent()
+	color := badgeRed
+	switch {
+	case sla >= b.SLAGood:
+		color = badgeGreen
+	case sla >= b.SLAWarn:
+		color = badgeYellow
+	}
+	return b.SLALabel, strconv.FormatFloat(sla, 'f', 2, 64) + "%", color
+}
+
+// averageRTT returns the average round trip time of the history, or the latest one if no history
+func averageRTT(r *probe.Result) time.Duration {
+	records := r.History.Records()
+	if len(records) == 0 {
+		return r.RoundTripTime
+	}
+	var total time.Duration
+	for _, rec := range records {
+		total += rec.RTT
+	}
+	return total / time.Duration(len(records))
+}
+
+func rttBadge(b conf.Badge, p probe.Prober) (string, string, string) {
+	r := probe.Snapshot(p)
+	rtt := averageRTT(&r)
+	color := badgeRed
+	switch {
+	case rtt <= b.RTTGood:
+		color = badgeGreen
+	case rtt <= b.RTTWarn:
+		color = badgeYellow
+	}
+	return b.RTTLabel, rtt.Round(time.Millisecond).String(), color
+}
+
+// badgeTextWidth estimates the width of the text in the 11px Verdana font
+func badgeTextWidth(s string) int {
+	return int(math.Ceil(float64(utf8.RuneCountInString(s))*6.5)) + 10
+}
+
+// renderBadge renders the shields-style flat badge
+func renderBadge(label, value, color string) string {
+	lw, vw := badgeTextWidth(label), badgeTextWidth(value)
+	w := lw + vw
+	label, value = html.EscapeString(label), html.EscapeString(value)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>`+
+		`<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>`+
+		`</g></svg>`,
+		w, lw, vw, label, value, color, lw/2, lw+vw/2)
+}
+
+// badgeMaxAge returns the seconds until the next probe, so the badge is cached for the probe interval
+func badgeMaxAge(p probe.Prober, now time.Time) int {
+	next := probe.Snapshot(p).StartTime.Add(p.Interval())
+	if age := next.Sub(now); age > 0 && age <= p.Interval() {
+		return int(math.Ceil(age.Seconds()))
+	}
+	return int(p.Interval().Seconds())
+}
+
+func writeBadge(w http.ResponseWriter, code int, svg string) {
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.WriteHeader(code)
+	if _, err := w.Write([]byte(svg)); err != nil {
+		log.Errorf("[Web] Failed to write the badge: %v", err)
+	}
+}
+
+func badgeHandler(fn badgeFuncType) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		b := badgeSettings()
+		label := strings.TrimSpace(req.URL.Query().Get("label"))
+		p := findProber(urlParam(req, "name"))
+		if p == nil {
+			if label == "" {
+				label = b.StatusLabel
+			}
+			w.Header().Set("Cache-Control", "no-cache")
+			writeBadge(w, http.StatusNotFound, renderBadge(label, "not found", badgeGrey))
+			return
+		}
+
+		l, value, color := fn(b, p)
+		if label == "" {
+			label = l
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", badgeMaxAge(p, time.Now())))
+		if t := probe.Snapshot(p).StartTime; !t.IsZero() {
+			w.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+		}
+		writeBadge(w, http.StatusOK, renderBadge(label, value, color))
+	}
+}
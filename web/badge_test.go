@@ -0,0 +1,121 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/conf"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadgeSettings(t *testing.T) {
+	b := badgeSettings()
+	assert.Equal(t, "status", b.StatusLabel)
+	assert.Equal(t, "sla", b.SLALabel)
+	assert.Equal(t, "rtt", b.RTTLabel)
+	assert.Equal(t, 99.9, b.SLAGood)
+	assert.Equal(t, float64(99), b.SLAWarn)
+	assert.Equal(t, 500*time.Millisecond, b.RTTGood)
+	assert.Equal(t, 2*time.Second, b.RTTWarn)
+}
+
+func TestBadgeValues(t *testing.T) {
+	b := badgeSettings()
+	list := newDummyProbers()
+
+	_, v, c := statusBadge(b, list[0])
+	assert.Equal(t, "up", v)
+	assert.Equal(t, badgeGreen, c)
+	_, v, c = statusBadge(b, list[1])
+	assert.Equal(t, "down", v)
+	assert.Equal(t, badgeRed, c)
+	list[1].Result().Maintenance = "upgrade"
+	_, v, c = statusBadge(b, list[1])
+	assert.Equal(t, "maintenance", v)
+	assert.Equal(t, badgeBlue, c)
+	probe.Pause(list[1].Name())
+	defer probe.Resume(list[1].Name())
+	_, v, c = statusBadge(b, list[1])
+	assert.Equal(t, "paused", v)
+	assert.Equal(t, badgeGrey, c)
+
+	l, v, c := slaBadge(b, list[0])
+	assert.Equal(t, "sla", l)
+	assert.Equal(t, "100.00%", v)
+	assert.Equal(t, badgeGreen, c)
+	_, _, c = slaBadge(b, list[2])
+	assert.Equal(t, badgeYellow, c)
+	_, v, c = slaBadge(b, list[3])
+	assert.Equal(t, "50.00%", v)
+	assert.Equal(t, badgeRed, c)
+
+	r := list[0].Result()
+	r.RoundTripTime = 3 * time.Second
+	_, v, c = rttBadge(b, list[0])
+	assert.Equal(t, "3s", v)
+	assert.Equal(t, badgeRed, c)
+	r.History.Append(probe.HistoryRecord{RTT: 100 * time.Millisecond})
+	r.History.Append(probe.HistoryRecord{RTT: 300 * time.Millisecond})
+	_, v, c = rttBadge(b, list[0])
+	assert.Equal(t, "200ms", v)
+	assert.Equal(t, badgeGreen, c)
+	b.RTTGood = 100 * time.Millisecond
+	_, _, c = rttBadge(b, list[0])
+	assert.Equal(t, badgeYellow, c)
+}
+
+func TestBadgeMaxAge(t *testing.T) {
+	p := newDummyProber("badge", "http", probe.StatusUp, 100, nil)
+	now := time.Now()
+	assert.Equal(t, 60, badgeMaxAge(p, now))
+	p.result.StartTime = now.Add(-20 * time.Second)
+	assert.Equal(t, 40, badgeMaxAge(p, now))
+}
+
+func TestBadgeHandler(t *testing.T) {
+	SetProbers(newDummyProbers())
+	defer SetProbers(nil)
+
+	r := chi.NewRouter()
+	badgeRoutes(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp := doRequest(t, http.MethodGet, srv.URL+"/badge/Web%20Site", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/svg+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "public, max-age=60", resp.Header.Get("Cache-Control"))
+
+	svg, code := getDashboard(t, srv.URL+"/badge/Web%20API/sla?label=<api>")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, "&lt;api&gt;: 90.00%")
+	assert.Contains(t, svg, badgeRed)
+
+	svg, code = getDashboard(t, srv.URL+"/badge/Database/rtt")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, svg, "rtt: 0s")
+
+	svg, code = getDashboard(t, srv.URL+"/badge/none")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Contains(t, svg, "status: not found")
+}
+
+func TestNormalizeBadge(t *testing.T) {
+	b := normalizeBadge(conf.Badge{StatusLabel: "health", SLAGood: 95, SLAWarn: 96, RTTGood: time.Second, RTTWarn: time.Millisecond})
+	assert.Equal(t, "health", b.StatusLabel)
+	assert.Equal(t, float64(95), b.SLAGood)
+	assert.Equal(t, float64(95), b.SLAWarn)
+	assert.Equal(t, time.Second, b.RTTGood)
+	assert.Equal(t, 4*time.Second, b.RTTWarn)
+
+	b = normalizeBadge(conf.Badge{SLAWarn: 90, RTTWarn: 3 * time.Second})
+	assert.Equal(t, float64(90), b.SLAWarn)
+	assert.Equal(t, 3*time.Second, b.RTTWarn)
+}
@@ -0,0 +1,70 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/channel"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// probeControl is the control state of the prober
+type probeControl struct {
+	Name string `json:"name"`
+	probe.Control
+}
+
+// controlHandler returns the handler which finds the prober by the name and changes its state
+func controlHandler(fn func(w http.ResponseWriter, req *http.Request, p probe.Prober)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := urlParam(req, "name")
+		p := findProber(name)
+		if p == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("probe [%s] is not found", name))
+			return
+		}
+		fn(w, req, p)
+	}
+}
+
+// runHandler runs the probe immediately, sends the result to the channels of the prober,
+// and appends it into the time-series store, the data file is saved from the prober's result
+func runHandler(w http.ResponseWriter, req *http.Request, p probe.Prober) {
+	log.Infof("[Web] %s / %s - Run the probe by the operator", p.Kind(), p.Name())
+	res := p.Probe()
+	channel.SendResult(p, res)
+	if err := store.Default().Append(res); err != nil {
+		log.Errorf("[Web] %s / %s - Cannot append the result into the store: %v", p.Kind(), p.Name(), err)
+	}
+	writeJSON(w, http.StatusOK, newProbeStatus(p))
+}
+
+func pauseHandler(w http.ResponseWriter, req *http.Request, p probe.Prober) {
+	log.Infof("[Web] %s / %s - Pause the probe by the operator", p.Kind(), p.Name())
+	writeJSON(w, http.StatusOK, probeControl{Name: p.Name(), Control: probe.Pause(p.Name())})
+}
+
+func resumeHandler(w http.ResponseWriter, req *http.Request, p probe.Prober) {
+	log.Infof("[Web] %s / %s - Resume the probe by the operator", p.Kind(), p.Name())
+	writeJSON(w, http.StatusOK, probeControl{Name: p.Name(), Control: probe.Resume(p.Name())})
+}
+
+// muteHandler mutes the notifications of the probe, e.g. ?duration=30m
+func muteHandler(w http.ResponseWriter, req *http.Request, p probe.Prober) {
+	d, err := time.ParseDuration(strings.TrimSpace(req.URL.Query().Get("duration")))
+	if err != nil || d <= 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid duration [%s], e.g. ?duration=30m", req.URL.Query().Get("duration")))
+		return
+	}
+	log.Infof("[Web] %s / %s - Mute the probe for %s by the operator", p.Kind(), p.Name(), d)
+	writeJSON(w, http.StatusOK, probeControl{Name: p.Name(), Control: probe.Mute(p.Name(), d)})
+}
+
+func unmuteHandler(w http.ResponseWriter, req *http.Request, p probe.Prober) {
+	log.Infof("[Web] %s / %s - Unmute the probe by the operator", p.Kind(), p.Name())
+	writeJSON(w, http.StatusOK, probeControl{Name: p.Name(), Control: probe.Mute(p.Name(), 0)})
+}
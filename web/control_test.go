@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func doControl(t *testing.T, method, url string, v interface{}) int {
+	resp := doRequest(t, method, url, "")
+	defer resp.Body.Close()
+	if v != nil {
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func TestControlAPI(t *testing.T) {
+	SetProbers(newDummyProbers())
+	defer SetProbers(nil)
+	defer probe.SetControl("Database", probe.Control{})
+
+	srv := newAPIServer()
+	defer srv.Close()
+	url := srv.URL + "/api/v1/probes/Database"
+
+	var c probeControl
+	assert.Equal(t, http.StatusOK, doControl(t, http.MethodPost, url+"/pause", &c))
+	assert.Equal(t, "Database", c.Name)
+	assert.True(t, c.Paused)
+
+	var s probeStatus
+	assert.Equal(t, http.StatusOK, doControl(t, http.MethodGet, url, &s))
+	assert.True(t, s.Control.Paused)
+
+	assert.Equal(t, http.StatusOK, doControl(t, http.MethodPost, url+"/resume", &c))
+	assert.False(t, c.Paused)
+
+	assert.Equal(t, http.StatusOK, doControl(t, http.MethodPost, url+"/mute?duration=30m", &c))
+	assert.True(t, c.IsMuted(time.Now().Add(29*time.Minute)))
+	assert.False(t, c.IsMuted(time.Now().Add(31*time.Minute)))
+	for _, d := range []string{"", "abc", "-1m"} {
+		assert.Equal(t, http.StatusBadRequest, doControl(t, http.MethodPost, url+"/mute?duration="+d, nil))
+	}
+	assert.Equal(t, http.StatusOK, doControl(t, http.MethodDelete, url+"/mute", &c))
+	assert.True(t, c.MutedUntil.IsZero())
+
+	assert.Equal(t, http.StatusOK, doControl(t, http.MethodPost, url+"/run", &s))
+	assert.Equal(t, "Database", s.Name)
+
+	// the result of the run is appended into the store
+	st, err := store.Open(store.Settings{Dir: t.TempDir()})
+	assert.Nil(t, err)
+	store.SetDefault(st)
+	defer closeStore(st)
+	assert.Equal(t, http.StatusOK, doControl(t, http.MethodPost, url+"/run", &s))
+	assert.Equal(t, 1, len(st.Records("Database", s.StartTime.Add(-time.Second), s.StartTime.Add(time.Second))))
+
+	assert.Equal(t, http.StatusNotFound, doControl(t, http.MethodPost, srv.URL+"/api/v1/probes/none/pause", nil))
+	assert.Equal(t, http.StatusNotFound, doControl(t, http.MethodPost, srv.URL+"/api/v1/probes/none/run", nil))
+}
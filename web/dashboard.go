@@ -0,0 +1,232 @@
+package web
+
+import (
+	_ "embed" // embed the dashboard template
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed templates/dashboard.html
+var dashboardHTML string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"emoji":    func(s probe.Status) string { return s.Emoji() },
+	"duration": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+	"percent":  func(f float64) string { return strconv.FormatFloat(f, 'f', 2, 64) + "%" },
+	"time":     report.FormatTime,
+	"bars":     dashboardBars,
+}).Parse(dashboardHTML))
+
+// the sort keys of the probers
+const (
+	sortName     = "name"
+	sortKind     = "kind"
+	sortStatus   = "status"
+	sortRTT      = "rtt"
+	sortUpTime   = "uptime"
+	sortDownTime = "downtime"
+	sortSLA      = "sla"
+	sortLastDown = "lastdown"
+)
+
+// sortItem is the prober with the snapshot of its result, the result is copied once for sorting
+type sortItem struct {
+	probe.Prober
+	result probe.Result
+}
+
+var sortLess = map[string]func(a, b sortItem) bool{
+	sortName:   func(a, b sortItem) bool { return a.Name() < b.Name() },
+	sortKind:   func(a, b sortItem) bool { return a.Kind() < b.Kind() },
+	sortStatus: func(a, b sortItem) bool { return a.result.Status < b.result.Status },
+	sortRTT:    func(a, b sortItem) bool { return a.result.RoundTripTime < b.result.RoundTripTime },
+	sortUpTime: func(a, b sortItem) bool { return a.result.Stat.UpTime < b.result.Stat.UpTime },
+	sortDownTime: func(a, b sortItem) bool {
+		return a.result.Stat.DownTime < b.result.Stat.DownTime
+	},
+	sortSLA: func(a, b sortItem) bool { return a.result.SLAPercent() < b.result.SLAPercent() },
+	sortLastDown: func(a, b sortItem) bool {
+		return a.result.LatestDownTime.Before(b.result.LatestDownTime)
+	},
+}
+
+// sortProbers returns the sorted copy of the probers, the original order is kept if the key is unknown
+func sortProbers(probers []probe.Prober, key string, desc bool) []probe.Prober {
+	list := make([]probe.Prober, len(probers))
+	copy(list, probers)
+	less, ok := sortLess[strings.ToLower(key)]
+	if !ok {
+		return list
+	}
+	items := make([]sortItem, len(probers))
+	for i, p := range probers {
+		items[i] = sortItem{p, probe.Snapshot(p)}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+	for i, item := range items {
+		list[i] = item.Prober
+	}
+	return list
+}
+
+// getSortedProbers returns the probers which are sorted by the `sort` and `order` query
+func getSortedProbers(req *http.Request) []probe.Prober {
+	q := req.URL.Query()
+	return sortProbers(getProbers(), q.Get("sort"), strings.EqualFold(q.Get("order"), "desc"))
+}
+
+// option is the option of the select in the dashboard
+type option struct {
+	Value    string
+	Title    string
+	Selected bool
+}
+
+// column is the header of the dashboard table
+type column struct {
+	Title string
+	URL   string
+	Arrow string
+}
+
+// statusCount is the number of the probers in the status
+type statusCount struct {
+	probe.Status
+	Emoji string
+	Count int
+}
+
+// dashboard is the data of the dashboard template
+type dashboard struct {
+	Title       string
+	Refresh     int
+	RefreshText string
+	Name        string
+	Sort        string
+	Order       string
+	All         int
+	Total       int
+	PageNum     int
+	Prev        string
+	Next        string
+	Summary     []statusCount
+	Statuses    []option
+	Kinds       []option
+	Columns     []column
+	Probes      []probeStatus
+	Footer      string
+}
+
+var dashboardStatuses = []probe.Status{
+	probe.StatusUp, probe.StatusDown, probe.StatusUnknown, probe.StatusInit, probe.StatusBad,
+}
+
+// queryURL returns the URL of the current query with the new values
+func queryURL(req *http.Request, values map[string]string) string {
+	q := req.URL.Query()
+	for k, v := range values {
+		q.Set(k, v)
+	}
+	return "?" + q.Encode()
+}
+
+func newDashboard(req *http.Request, f *ProbeFilter) dashboard {
+	q := req.URL.Query()
+	all := getProbers()
+	refresh := getRefreshInterval(q.Get("refresh"))
+	d := dashboard{
+		Title:       global.GetEaseProbe().Name + " Dashboard",
+		Refresh:     int(refresh.Seconds()),
+		RefreshText: refresh.String(),
+		All:         len(all),
+		Name:        f.Name,
+		Sort:        strings.ToLower(q.Get("sort")),
+		Order:       strings.ToLower(q.Get("order")),
+		PageNum:     f.PageNum,
+		Footer:      global.FooterString(),
+	}
+
+	// the summary of all of the probers
+	counts := map[probe.Status]int{}
+	for _, p := range all {
+		counts[probe.Snapshot(p).Status]++
+	}
+	for _, s := range dashboardStatuses {
+		cnt := counts[s]
+		if cnt > 0 {
+			d.Summary = append(d.Summary, statusCount{s, s.Emoji(), cnt})
+		}
+		selected := f.Status != nil && *f.Status == s
+		d.Statuses = append(d.Statuses, option{s.String(), s.Title(), selected})
+	}
+	kinds := map[string]bool{}
+	for _, p := range all {
+		kinds[p.Kind()] = true
+	}
+	for k := range kinds {
+		d.Kinds = append(d.Kinds, option{k, k, strings.EqualFold(k, f.Kind)})
+	}
+	sort.Slice(d.Kinds, func(i, j int) bool { return d.Kinds[i].Value < d.Kinds[j].Value })
+
+	// the header links toggle the sort order
+	headers := []struct{ title, key string }{
+		{"Name", sortName}, {"Kind", sortKind}, {"Status", sortStatus}, {"RTT", sortRTT},
+		{"Uptime", sortUpTime}, {"Downtime", sortDownTime}, {"SLA", sortSLA}, {"History", ""},
+		{"Message", ""}, {"Last Down", sortLastDown},
+	}
+	for _, h := range headers {
+		c := column{Title: h.title}
+		if h.key != "" {
+			order := "asc"
+			if d.Sort == h.key {
+				// the arrow shows the current order, the link toggles it
+				c.Arrow = " ▼"
+				if d.Order != "desc" {
+					order, c.Arrow = "desc", " ▲"
+				}
+			}
+			c.URL = queryURL(req, map[string]string{"sort": h.key, "order": order, "pg": "1"})
+		}
+		d.Columns = append(d.Columns, c)
+	}
+	return d
+}
+
+func dashboardHandler(w http.ResponseWriter, req *http.Request) {
+	f, err := getFilter(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d := newDashboard(req, f)
+	page, total := f.Filter(getSortedProbers(req))
+	d.Total = total
+	for _, p := range page {
+		d.Probes = append(d.Probes, newProbeStatus(p))
+	}
+	if f.PageNum > 1 {
+		d.Prev = queryURL(req, map[string]string{"pg": strconv.Itoa(f.PageNum - 1)})
+	}
+	if f.PageNum*f.PageSize < total {
+		d.Next = queryURL(req, map[string]string{"pg": strconv.Itoa(f.PageNum + 1)})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, d); err != nil {
+		log.Errorf("[Web] Failed to render the dashboard: %v", err)
+	}
+}
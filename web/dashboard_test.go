@@ -0,0 +1,87 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortProbers(t *testing.T) {
+	probers := newDummyProbers()
+	assert.Equal(t, []string{"Cache", "Database", "Web API", "Web Site"}, names(sortProbers(probers, "name", false)))
+	assert.Equal(t, []string{"Web Site", "Database", "Web API", "Cache"}, names(sortProbers(probers, "SLA", true)))
+	assert.Equal(t, []string{"Web Site", "Database", "Web API", "Cache"}, names(sortProbers(probers, "status", false)))
+	assert.Equal(t, []string{"Web Site", "Web API", "Database", "Cache"}, names(sortProbers(probers, "none", false)))
+	// the original probers are not changed
+	assert.Equal(t, "Web Site", probers[0].Name())
+}
+
+func getDashboard(t *testing.T, url string) (string, int) {
+	resp := doRequest(t, http.MethodGet, url, "")
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	return string(body), resp.StatusCode
+}
+
+func TestDashboard(t *testing.T) {
+	list := newDummyProbers()
+	list[1].Result().Message = "<b>timeout</b>"
+	list[1].Result().LatestDownTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	appendHistory(list[1], 5)
+	SetProbers(list)
+	defer func() { probers = nil }()
+
+	r := chi.NewRouter()
+	r.Get("/", dashboardHandler)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	html, code := getDashboard(t, srv.URL+"/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, html, `<meta http-equiv="refresh" content="60">`)
+	assert.Contains(t, html, "Probes: 4 / 4")
+	assert.Contains(t, html, "Web Site")
+	assert.Contains(t, html, "Cache")
+	assert.Contains(t, html, "&lt;b&gt;timeout&lt;/b&gt;")
+	assert.Contains(t, html, "90.00%")
+	assert.Contains(t, html, `<option value="tcp">tcp</option>`)
+	down := probe.StatusDown
+	assert.Contains(t, html, down.Emoji())
+	assert.NotContains(t, html, "<script")
+	assert.Equal(t, 1, strings.Count(html, "<svg"))
+
+	// filter and sort
+	html, _ = getDashboard(t, srv.URL+"/?status=down&kind=tcp&refresh=5s")
+	assert.Contains(t, html, `content="5"`)
+	assert.Contains(t, html, "Probes: 1 / 4")
+	assert.Contains(t, html, "Cache")
+	assert.NotContains(t, html, "Web API")
+	assert.Contains(t, html, `<option value="tcp" selected>tcp</option>`)
+	assert.Contains(t, html, `<option value="down" selected>`)
+
+	html, _ = getDashboard(t, srv.URL+"/?sort=sla&order=asc")
+	assert.Contains(t, html, "SLA ▲")
+	assert.Contains(t, html, "order=desc")
+
+	// paging
+	html, _ = getDashboard(t, srv.URL+"/?sz=1&pg=2")
+	assert.Contains(t, html, "Previous")
+	assert.Contains(t, html, "Next")
+	assert.Contains(t, html, "Web API")
+	assert.NotContains(t, html, "Web Site<")
+
+	_, code = getDashboard(t, srv.URL+"/?pg=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	probers = nil
+	html, _ = getDashboard(t, srv.URL+"/")
+	assert.Contains(t, html, "No probe is found.")
+}
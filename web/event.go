@@ -0,0 +1,141 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/megaease/easeprobe/event"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+)
+
+// getEventFilter parses the filter and the replay from the query, e.g.
+// ?name=web&name=db&kind=http&transitions=true&replay=10
+// the reconnecting SSE client sends the `Last-Event-ID` header to get the missed events
+func getEventFilter(req *http.Request) (event.Filter, int, uint64) {
+	q := req.URL.Query()
+	f := event.Filter{}
+	for _, n := range q["name"] {
+		if n = strings.TrimSpace(n); n != "" {
+			f.Names = append(f.Names, n)
+		}
+	}
+	for _, k := range q["kind"] {
+		if k = strings.TrimSpace(k); k != "" {
+			f.Kinds = append(f.Kinds, k)
+		}
+	}
+	f.Transitions, _ = strconv.ParseBool(q.Get("transitions"))
+	replay := getNum(q.Get("replay"), 0, toInt)
+	lastID, _ := strconv.ParseUint(strings.TrimSpace(req.Header.Get("Last-Event-ID")), 10, 64)
+	return f, replay, lastID
+}
+
+// eventsHandler streams the events with Server-Sent Events, or WebSocket if the client requests the upgrade
+func eventsHandler(w http.ResponseWriter, req *http.Request) {
+	if strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		websocket.Server{Handshake: checkOrigin, Handler: wsEventsHandler}.ServeHTTP(w, req)
+		return
+	}
+	sseEventsHandler(w, req)
+}
+
+func sseEventsHandler(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	f, replay, lastID := getEventFilter(req)
+	sub, events := event.Subscribe(f, replay, lastID)
+	defer event.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(e event.Event) bool {
+		data, err := json.Marshal(e)
+		if err != nil {
+			log.Errorf("[Web] Failed to marshal the event: %v", err)
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, e := range events {
+		if !send(e) {
+			return
+		}
+	}
+	flusher.Flush()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok || !send(e) {
+				return
+			}
+		}
+	}
+}
+
+func wsEventsHandler(ws *websocket.Conn) {
+	defer ws.Close()
+	f, replay, lastID := getEventFilter(ws.Request())
+	sub, events := event.Subscribe(f, replay, lastID)
+	defer event.Unsubscribe(sub)
+
+	// the client doesn't send any message, reading is only to detect the closed connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var msg string
+		for websocket.Message.Receive(ws, &msg) == nil {
+		}
+	}()
+
+	for _, e := range events {
+		if err := websocket.JSON.Send(ws, e); err != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case <-closed:
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(ws, e); err != nil {
+				log.Debugf("[Web] Failed to send the event to %s: %v", ws.Request().RemoteAddr, err)
+				return
+			}
+		}
+	}
+}
+
+// checkOrigin accepts the non-browser clients without the origin, and the browser clients from the same host
+func checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || !strings.EqualFold(u.Host, req.Host) {
+		return fmt.Errorf("the origin [%s] is not allowed", origin)
+	}
+	config.Origin = u
+	return nil
+}
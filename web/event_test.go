@@ -0,0 +1,112 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/event"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestGetEventFilter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events?name=web&name=+&name=db&kind=http&transitions=true&replay=5", nil)
+	req.Header.Set("Last-Event-ID", "42")
+	f, replay, lastID := getEventFilter(req)
+	assert.Equal(t, []string{"web", "db"}, f.Names)
+	assert.Equal(t, []string{"http"}, f.Kinds)
+	assert.True(t, f.Transitions)
+	assert.Equal(t, 5, replay)
+	assert.Equal(t, uint64(42), lastID)
+
+	f, replay, lastID = getEventFilter(httptest.NewRequest(http.MethodGet, "/api/v1/events?transitions=x", nil))
+	assert.Equal(t, event.Filter{}, f)
+	assert.Equal(t, 0, replay)
+	assert.Equal(t, uint64(0), lastID)
+}
+
+// readSSE reads the data of the events from the stream
+func readSSE(t *testing.T, scanner *bufio.Scanner, n int) []event.Event {
+	events := []event.Event{}
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var e event.Event
+		assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e))
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestSSEEvents(t *testing.T) {
+	srv := newAPIServer()
+	defer srv.Close()
+
+	first := event.Publish(event.Event{Name: "sse", Kind: "http", Type: event.TypeTransition, Status: probe.StatusDown})
+	event.Publish(event.Event{Name: "other", Kind: "http", Type: event.TypeTransition})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/events?name=sse&replay=1", nil)
+	assert.Nil(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	scanner := bufio.NewScanner(resp.Body)
+
+	// the replay
+	events := readSSE(t, scanner, 1)
+	assert.Equal(t, first.ID, events[0].ID)
+	assert.Equal(t, probe.StatusDown, events[0].Status)
+
+	// the live events
+	event.Publish(event.Event{Name: "other", Type: event.TypeResult})
+	live := event.Publish(event.Event{Name: "sse", Type: event.TypeResult})
+	events = readSSE(t, scanner, 1)
+	assert.Equal(t, live.ID, events[0].ID)
+
+	// the reconnecting client gets the missed events
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/v1/events?name=sse", nil)
+	assert.Nil(t, err)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(first.ID, 10))
+	resp2, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp2.Body.Close()
+	events = readSSE(t, bufio.NewScanner(resp2.Body), 1)
+	assert.Equal(t, live.ID, events[0].ID)
+}
+
+func TestWebSocketEvents(t *testing.T) {
+	srv := newAPIServer()
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/events?kind=tcp&transitions=true"
+
+	// the cross origin request is rejected
+	_, err := websocket.Dial(url, "", "http://example.com")
+	assert.NotNil(t, err)
+
+	ws, err := websocket.Dial(url, "", srv.URL)
+	assert.Nil(t, err)
+	defer ws.Close()
+
+	assert.Eventually(t, func() bool {
+		// the subscription is ready after the handshake, so publish the events until it's received
+		event.Publish(event.Event{Name: "ws", Kind: "tcp", Type: event.TypeResult})
+		event.Publish(event.Event{Name: "ws", Kind: "http", Type: event.TypeTransition})
+		event.Publish(event.Event{Name: "ws", Kind: "tcp", Type: event.TypeTransition})
+		var got event.Event
+		ws.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		return websocket.JSON.Receive(ws, &got) == nil && got.Kind == "tcp" && got.Type == event.TypeTransition
+	}, time.Second, 10*time.Millisecond)
+}
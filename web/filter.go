@@ -0,0 +1,108 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+)
+
+// ProbeFilter is the filter of the probers
+type ProbeFilter struct {
+	Name       string            // the name contains the string (case-insensitive)
+	Kind       string            // the kind of the probe
+	Status     *probe.Status     // the current status of the probe
+	Labels     map[string]string // the probe has all of the labels, empty value matches any value
+	SLAGreater float64           // the SLA is greater than or equal to the value
+	SLALess    float64           // the SLA is less than or equal to the value
+	PageNum    int               // the page number, starts from 1
+	PageSize   int               // the number of the probers in one page
+}
+
+// NewProbeFilter return the filter which matches all of the probers
+func NewProbeFilter() *ProbeFilter {
+	return &ProbeFilter{
+		Labels:     map[string]string{},
+		SLAGreater: 0,
+		SLALess:    100,
+		PageNum:    1,
+		PageSize:   global.DefaultPageSize,
+	}
+}
+
+// parseLabels parses the labels in "key=value" or "key" format
+func parseLabels(list []string) map[string]string {
+	labels := map[string]string{}
+	for _, l := range list {
+		kv := strings.SplitN(l, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			continue
+		}
+		labels[key] = ""
+		if len(kv) == 2 {
+			labels[key] = strings.TrimSpace(kv[1])
+		}
+	}
+	return labels
+}
+
+// Check checks the filter is valid or not
+func (f *ProbeFilter) Check() error {
+	if f.SLAGreater < 0 || f.SLAGreater > 100 || f.SLALess < 0 || f.SLALess > 100 {
+		return fmt.Errorf("the SLA range [%.2f, %.2f] must be in [0, 100]", f.SLAGreater, f.SLALess)
+	}
+	if f.SLAGreater > f.SLALess {
+		return fmt.Errorf("the SLA range [%.2f, %.2f] is invalid", f.SLAGreater, f.SLALess)
+	}
+	if f.PageNum < 1 {
+		return fmt.Errorf("the page number [%d] must be greater than 0", f.PageNum)
+	}
+	if f.PageSize < 1 {
+		return fmt.Errorf("the page size [%d] must be greater than 0", f.PageSize)
+	}
+	return nil
+}
+
+// Match returns true if the prober matches the filter
+func (f *ProbeFilter) Match(p probe.Prober) bool {
+	r := probe.Snapshot(p)
+	if f.Name != "" && !strings.Contains(strings.ToLower(p.Name()), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.Kind != "" && !strings.EqualFold(p.Kind(), f.Kind) {
+		return false
+	}
+	if f.Status != nil && r.Status != *f.Status {
+		return false
+	}
+	labels := p.LabelMap()
+	for k, v := range f.Labels {
+		if val, ok := labels[k]; !ok || (v != "" && val != v) {
+			return false
+		}
+	}
+	sla := r.SLAPercent()
+	return sla >= f.SLAGreater && sla <= f.SLALess
+}
+
+// Filter returns the probers of the page, and the total number of the matched probers
+func (f *ProbeFilter) Filter(probers []probe.Prober) ([]probe.Prober, int) {
+	matched := []probe.Prober{}
+	for _, p := range probers {
+		if f.Match(p) {
+			matched = append(matched, p)
+		}
+	}
+	total := len(matched)
+	start := (f.PageNum - 1) * f.PageSize
+	if start >= total {
+		return []probe.Prober{}, total
+	}
+	end := start + f.PageSize
+	if end > total {
+		end = total
+	}
+	return matched[start:end], total
+}
@@ -0,0 +1,133 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyProber struct {
+	name   string
+	kind   string
+	labels prometheus.Labels
+	result *probe.Result
+}
+
+func (d *dummyProber) LabelMap() prometheus.Labels          { return d.labels }
+func (d *dummyProber) SetLabelMap(labels prometheus.Labels) { d.labels = labels }
+func (d *dummyProber) Kind() string                         { return d.kind }
+func (d *dummyProber) Name() string                         { return d.name }
+func (d *dummyProber) Channels() []string                   { return nil }
+func (d *dummyProber) Timeout() time.Duration               { return time.Second }
+func (d *dummyProber) Interval() time.Duration              { return time.Minute }
+func (d *dummyProber) Result() *probe.Result                { return d.result }
+func (d *dummyProber) Config(global.ProbeSettings) error    { return nil }
+func (d *dummyProber) Probe() probe.Result                  { return *d.result }
+
+var _ probe.Prober = (*dummyProber)(nil)
+
+// newDummyProber creates the prober with the status and the SLA
+func newDummyProber(name, kind string, status probe.Status, sla float64, labels prometheus.Labels) *dummyProber {
+	r := probe.NewResult()
+	r.Name = name
+	r.Endpoint = kind + "://" + name
+	r.Status = status
+	r.Stat.UpTime = time.Duration(sla * float64(time.Minute))
+	r.Stat.DownTime = time.Duration((100 - sla) * float64(time.Minute))
+	return &dummyProber{name: name, kind: kind, labels: labels, result: r}
+}
+
+func newDummyProbers() []probe.Prober {
+	return []probe.Prober{
+		newDummyProber("Web Site", "http", probe.StatusUp, 100, prometheus.Labels{"env": "prod", "team": "web"}),
+		newDummyProber("Web API", "http", probe.StatusDown, 90, prometheus.Labels{"env": "test", "team": "web"}),
+		newDummyProber("Database", "tcp", probe.StatusUp, 99.5, prometheus.Labels{"env": "prod"}),
+		newDummyProber("Cache", "tcp", probe.StatusDown, 50, nil),
+	}
+}
+
+func names(probers []probe.Prober) []string {
+	list := []string{}
+	for _, p := range probers {
+		list = append(list, p.Name())
+	}
+	return list
+}
+
+func TestParseLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{"env": "prod", "team": ""},
+		parseLabels([]string{"env=prod", " team ", "=x", ""}))
+}
+
+func TestFilterCheck(t *testing.T) {
+	f := NewProbeFilter()
+	assert.Nil(t, f.Check())
+
+	f.SLAGreater, f.SLALess = 90, 80
+	assert.NotNil(t, f.Check())
+	f.SLAGreater, f.SLALess = -1, 80
+	assert.NotNil(t, f.Check())
+	f.SLAGreater, f.SLALess = 0, 101
+	assert.NotNil(t, f.Check())
+
+	f = NewProbeFilter()
+	f.PageNum = 0
+	assert.NotNil(t, f.Check())
+	f = NewProbeFilter()
+	f.PageSize = 0
+	assert.NotNil(t, f.Check())
+}
+
+func TestFilter(t *testing.T) {
+	probers := newDummyProbers()
+
+	f := NewProbeFilter()
+	list, total := f.Filter(probers)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 4, len(list))
+
+	f.Name = "web"
+	_, total = f.Filter(probers)
+	assert.Equal(t, 2, total)
+
+	f = NewProbeFilter()
+	f.Kind = "TCP"
+	list, _ = f.Filter(probers)
+	assert.Equal(t, []string{"Database", "Cache"}, names(list))
+
+	f = NewProbeFilter()
+	f.Status = getStatus("down")
+	list, _ = f.Filter(probers)
+	assert.Equal(t, []string{"Web API", "Cache"}, names(list))
+
+	f = NewProbeFilter()
+	f.Labels = map[string]string{"env": "prod"}
+	list, _ = f.Filter(probers)
+	assert.Equal(t, []string{"Web Site", "Database"}, names(list))
+	f.Labels = map[string]string{"team": ""}
+	list, _ = f.Filter(probers)
+	assert.Equal(t, []string{"Web Site", "Web API"}, names(list))
+
+	f = NewProbeFilter()
+	f.SLAGreater, f.SLALess = 60, 99.9
+	list, _ = f.Filter(probers)
+	assert.Equal(t, []string{"Web API", "Database"}, names(list))
+
+	// paging
+	f = NewProbeFilter()
+	f.PageSize = 3
+	list, total = f.Filter(probers)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 3, len(list))
+	f.PageNum = 2
+	list, _ = f.Filter(probers)
+	assert.Equal(t, []string{"Cache"}, names(list))
+	f.PageNum = 3
+	list, total = f.Filter(probers)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, 0, len(list))
+}
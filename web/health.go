@@ -0,0 +1,29 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/health"
+)
+
+// healthRoutes registers the liveness and readiness probes of EaseProbe itself
+func healthRoutes(r chi.Router) {
+	r.Get("/healthz", healthzHandler)
+	r.Get("/readyz", readyzHandler)
+}
+
+func healthzHandler(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, health.Live(time.Now()))
+}
+
+// readyzHandler returns 503 if EaseProbe is not ready
+func readyzHandler(w http.ResponseWriter, req *http.Request) {
+	r := health.Ready(time.Now())
+	code := http.StatusOK
+	if !r.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, r)
+}
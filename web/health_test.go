@@ -0,0 +1,47 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	health.Reset()
+	defer health.Reset()
+
+	r := chi.NewRouter()
+	healthRoutes(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp := doRequest(t, http.MethodGet, srv.URL+"/healthz", "")
+	var l health.Liveness
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&l))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", l.Status)
+
+	var ready health.Readiness
+	resp = doRequest(t, http.MethodGet, srv.URL+"/readyz", "")
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&ready))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.False(t, ready.Ready)
+
+	health.SetConfig("config.yaml")
+	health.SetExpected(1)
+	health.Schedule("http", "web", time.Minute, time.Second, 0)
+	resp = doRequest(t, http.MethodGet, srv.URL+"/readyz", "")
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&ready))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, ready.Ready)
+	assert.Equal(t, 1, ready.Scheduled)
+}
@@ -0,0 +1,153 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/megaease/easeprobe/store"
+)
+
+// dashboardHistorySize is the number of the recent records shown in the dashboard
+const dashboardHistorySize = 30
+
+// probeHistory is the execution history of the prober
+type probeHistory struct {
+	Name    string                `json:"name"`
+	MaxLen  int                   `json:"maxlen"`
+	Total   int                   `json:"total"`
+	Uptime  float64               `json:"uptime"` // the percentage of the up records
+	Records []probe.HistoryRecord `json:"records"`
+}
+
+// getSince parses the `since` query, it could be a duration (e.g. 1h) or a time (e.g. 2024-01-01T00:00:00Z)
+func getSince(since string) (time.Time, error) {
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since [%s], it should be a duration or RFC3339 time", since)
+}
+
+func findProber(name string) probe.Prober {
+	for _, p := range getProbers() {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func newProbeHistory(name string, h *probe.History, since time.Time, limit int) probeHistory {
+	return newHistoryOf(name, h.MaxLen(), h.Since(since), limit)
+}
+
+// newHistoryOf returns the history of the records, only the last `limit` records are kept if it's set
+func newHistoryOf(name string, maxLen int, records []probe.HistoryRecord, limit int) probeHistory {
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	ph := probeHistory{
+		Name:    name,
+		MaxLen:  maxLen,
+		Total:   len(records),
+		Uptime:  0,
+		Records: records,
+	}
+	up := 0
+	for _, r := range records {
+		if r.Status == probe.StatusUp {
+			up++
+		}
+	}
+	if len(records) > 0 {
+		ph.Uptime = float64(up) / float64(len(records)) * 100
+	}
+	return ph
+}
+
+// historyHandler returns the history of the prober, e.g. ?since=1h&until=30m&limit=100,
+// the records are from the time-series store if the since is set and the store is enabled,
+// otherwise they are from the recent records kept by the prober, and the messages are only kept in the latter.
+func historyHandler(w http.ResponseWriter, req *http.Request) {
+	name := urlParam(req, "name")
+	p := findProber(name)
+	if p == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("probe [%s] is not found", name))
+		return
+	}
+	q := req.URL.Query()
+	since, err := getSince(q.Get("since"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	until := time.Now()
+	if s := strings.TrimSpace(q.Get("until")); s != "" {
+		if until, err = getTime(s); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	limit := getNum(q.Get("limit"), 0, toInt)
+	h := probe.Snapshot(p).History
+
+	records := []probe.HistoryRecord{}
+	if st := store.Default(); st != nil && !since.IsZero() {
+		for _, r := range st.Records(name, since, until) {
+			records = append(records, r.HistoryRecord())
+		}
+	} else {
+		for _, r := range h.Since(since) {
+			if !r.Time.Before(until) {
+				break
+			}
+			records = append(records, r)
+		}
+	}
+	writeJSON(w, http.StatusOK, newHistoryOf(name, h.MaxLen(), records, limit))
+}
+
+// historyBars renders the recent records as the inline SVG bars,
+// the color is the status and the height is the round trip time
+func historyBars(records []probe.HistoryRecord) template.HTML {
+	if len(records) > dashboardHistorySize {
+		records = records[len(records)-dashboardHistorySize:]
+	}
+	if len(records) == 0 {
+		return "-"
+	}
+	const width, height, gap = 4, 20, 1
+	var max time.Duration
+	for _, r := range records {
+		if r.RTT > max {
+			max = r.RTT
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg width="%d" height="%d" xmlns="http://www.w3.org/2000/svg">`,
+		len(records)*(width+gap), height)
+	for i, r := range records {
+		h := height
+		if max > 0 {
+			// keep a minimal height for the visibility
+			h = 4 + int(float64(height-4)*float64(r.RTT)/float64(max))
+		}
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s %s %s</title></rect>`,
+			i*(width+gap), height-h, width, h, report.StatusColor(r.Status).Hex(),
+			template.HTMLEscapeString(report.FormatTime(r.Time)), r.Status.Title(),
+			r.RTT.Round(time.Millisecond))
+	}
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func appendHistory(p probe.Prober, n int) {
+	now := time.Now().Add(-time.Duration(n) * time.Minute)
+	for i := 0; i < n; i++ {
+		status := probe.StatusUp
+		if i%4 == 0 {
+			status = probe.StatusDown
+		}
+		p.Result().History.Append(probe.HistoryRecord{
+			Time:    now.Add(time.Duration(i) * time.Minute),
+			Status:  status,
+			RTT:     time.Duration(i+1) * time.Millisecond,
+			Message: "record",
+		})
+	}
+}
+
+func TestGetSince(t *testing.T) {
+	since, err := getSince("")
+	assert.Nil(t, err)
+	assert.True(t, since.IsZero())
+
+	since, err = getSince("1h")
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), since, time.Second)
+
+	since, err = getSince("2024-01-01T00:00:00Z")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), since)
+
+	_, err = getSince("yesterday")
+	assert.NotNil(t, err)
+}
+
+func TestHistoryAPI(t *testing.T) {
+	list := newDummyProbers()
+	appendHistory(list[1], 8)
+	SetProbers(list)
+	defer func() { probers = nil }()
+
+	srv := newAPIServer()
+	defer srv.Close()
+	api := srv.URL + "/api/v1/probes/" + url.PathEscape("Web API") + "/history"
+
+	get := func(query string) (probeHistory, int) {
+		resp := doRequest(t, http.MethodGet, api+query, "")
+		defer resp.Body.Close()
+		var h probeHistory
+		if resp.StatusCode == http.StatusOK {
+			assert.Nil(t, json.NewDecoder(resp.Body).Decode(&h))
+		}
+		return h, resp.StatusCode
+	}
+
+	h, code := get("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Web API", h.Name)
+	assert.Equal(t, 8, h.Total)
+	assert.Equal(t, 75.0, h.Uptime)
+	assert.Equal(t, probe.StatusDown, h.Records[0].Status)
+	assert.Equal(t, time.Millisecond, h.Records[0].RTT)
+
+	h, _ = get("?limit=2")
+	assert.Equal(t, 2, h.Total)
+	assert.Equal(t, 8*time.Millisecond, h.Records[1].RTT)
+
+	h, _ = get("?since=150s")
+	assert.Equal(t, 2, h.Total)
+
+	_, code = get("?since=invalid")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	resp := doRequest(t, http.MethodGet, srv.URL+"/api/v1/probes/none/history", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// no history
+	h2 := newProbeHistory("empty", probe.NewHistory(10), time.Time{}, 0)
+	assert.Equal(t, 0.0, h2.Uptime)
+	assert.NotNil(t, h2.Records)
+}
+
+func TestHistoryBars(t *testing.T) {
+	assert.Equal(t, "-", string(historyBars(nil)))
+
+	p := newDummyProber("bars", "http", probe.StatusUp, 100, nil)
+	appendHistory(p, dashboardHistorySize+5)
+	svg := string(historyBars(p.Result().History.Records()))
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Equal(t, dashboardHistorySize, strings.Count(svg, "<rect"))
+	assert.Contains(t, svg, "#E01E5A")
+	assert.Contains(t, svg, "#36A64F")
+}
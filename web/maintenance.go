@@ -0,0 +1,81 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/megaease/easeprobe/maintenance"
+)
+
+// maintenanceWindow is the maintenance window with its current state
+type maintenanceWindow struct {
+	maintenance.Window
+	Active bool `json:"active"`
+}
+
+func listMaintenanceHandler(w http.ResponseWriter, req *http.Request) {
+	now := time.Now()
+	list := []maintenanceWindow{}
+	for _, m := range maintenance.GetWindows() {
+		list = append(list, maintenanceWindow{Window: m, Active: m.IsActive(now)})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// maintenanceRequest is the maintenance window in the request, the duration is the string as in the configuration,
+// e.g. "30m", the integer nanoseconds returned by the list API are accepted as well
+type maintenanceRequest struct {
+	maintenance.Window
+	Duration json.RawMessage `json:"duration,omitempty"`
+}
+
+// window return the maintenance window of the request
+func (r maintenanceRequest) window() (maintenance.Window, error) {
+	m := r.Window
+	if len(r.Duration) == 0 {
+		return m, nil
+	}
+	var s string
+	if err := json.Unmarshal(r.Duration, &s); err == nil {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return m, fmt.Errorf("invalid duration [%s]: %v", s, err)
+		}
+		m.Duration = d
+		return m, nil
+	}
+	if err := json.Unmarshal(r.Duration, &m.Duration); err != nil {
+		return m, fmt.Errorf("invalid duration %s: %v", r.Duration, err)
+	}
+	return m, nil
+}
+
+// addMaintenanceHandler adds the maintenance window at runtime. The window is only kept in memory,
+// it is lost when EaseProbe restarts, so the permanent windows should be added into the configuration file.
+func addMaintenanceHandler(w http.ResponseWriter, req *http.Request) {
+	var r maintenanceRequest
+	if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid maintenance window: %v", err))
+		return
+	}
+	m, err := r.window()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid maintenance window: %v", err))
+		return
+	}
+	if err := maintenance.AddWindow(m); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, m)
+}
+
+func deleteMaintenanceHandler(w http.ResponseWriter, req *http.Request) {
+	if err := maintenance.RemoveWindow(urlParam(req, "name")); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/conf"
+	"github.com/megaease/easeprobe/maintenance"
+	"github.com/stretchr/testify/assert"
+)
+
+// testAuth is the authentication of the API server in the tests, the control APIs require it
+var testAuth = conf.HTTPAuth{Token: "token"}
+
+func newAPIServer() *httptest.Server {
+	r := chi.NewRouter()
+	apiRoutes(r, testAuth)
+	return httptest.NewServer(r)
+}
+
+func doRequest(t *testing.T, method, url, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.Nil(t, err)
+	req.Header.Set("Authorization", "Bearer "+testAuth.Token)
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	return resp
+}
+
+func TestMaintenanceAPI(t *testing.T) {
+	maintenance.SetWindows(nil)
+	defer maintenance.SetWindows(nil)
+
+	srv := newAPIServer()
+	defer srv.Close()
+	url := srv.URL + "/api/v1/maintenance"
+
+	// add the windows
+	resp := doRequest(t, http.MethodPost, url, `{"name": "always", "time": "00:00-24:00", "probes": ["web"]}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, url, `{"name": "past", "start": "2020-01-01 00:00", "end": "2020-01-02 00:00"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// the duration is the string as in the configuration, or the nanoseconds as in the list
+	resp = doRequest(t, http.MethodPost, url, `{"name": "cron", "cron": "0 2 * * *", "duration": "1h30m"}`)
+	var m maintenance.Window
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&m))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 90*time.Minute, m.Duration)
+	resp = doRequest(t, http.MethodPost, url, `{"name": "nanoseconds", "cron": "0 3 * * *", "duration": 60000000000}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	duration := map[string]time.Duration{}
+	for _, w := range maintenance.GetWindows() {
+		duration[w.Name] = w.Duration
+	}
+	assert.Equal(t, 90*time.Minute, duration["cron"])
+	assert.Equal(t, time.Minute, duration["nanoseconds"])
+	assert.Nil(t, maintenance.RemoveWindow("cron"))
+	assert.Nil(t, maintenance.RemoveWindow("nanoseconds"))
+
+	// invalid windows
+	for _, body := range []string{`{"name": "bad"}`, `{"name": "bad", "time": "25:00-26:00"}`, `not json`,
+		`{"name": "bad", "cron": "0 2 * * *", "duration": "1 hour"}`,
+		`{"name": "bad", "cron": "0 2 * * *", "duration": true}`,
+		`{"name": "bad", "cron": "0 2 * * *"}`} {
+		resp = doRequest(t, http.MethodPost, url, body)
+		var e apiError
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&e))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.NotEmpty(t, e.Error)
+	}
+
+	// list the windows
+	resp = doRequest(t, http.MethodGet, url, "")
+	var list []maintenanceWindow
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&list))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, len(list))
+	active := map[string]bool{}
+	for _, m := range list {
+		active[m.Name] = m.Active
+	}
+	assert.Equal(t, map[string]bool{"always": true, "past": false}, active)
+
+	// delete the window
+	resp = doRequest(t, http.MethodDelete, url+"/past", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = doRequest(t, http.MethodDelete, url+"/past", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 1, len(maintenance.GetWindows()))
+}
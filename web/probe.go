@@ -0,0 +1,92 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// probeStatus is the current status of the prober, the name is in the result
+type probeStatus struct {
+	Kind    string            `json:"kind"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	SLA     float64           `json:"sla"`
+	Control probe.Control     `json:"control"`
+	probe.Result
+}
+
+// probeList is one page of the probers
+type probeList struct {
+	Total    int           `json:"total"`
+	PageNum  int           `json:"page"`
+	PageSize int           `json:"size"`
+	Probes   []probeStatus `json:"probes"`
+}
+
+func newProbeStatus(p probe.Prober) probeStatus {
+	r := probe.Snapshot(p)
+	return probeStatus{
+		Kind:    p.Kind(),
+		Labels:  p.LabelMap(),
+		SLA:     r.SLAPercent(),
+		Control: probe.GetControl(p.Name()), // the control state is changed by the operator without probing
+		Result:  r,
+	}
+}
+
+func getProbers() []probe.Prober {
+	if probers == nil {
+		return []probe.Prober{}
+	}
+	return *probers
+}
+
+// getFilter parses the filter from the query, e.g.
+// ?name=web&kind=http&status=down&label=env=prod&gte=90&lte=99.9&pg=1&sz=20
+func getFilter(req *http.Request) (*ProbeFilter, error) {
+	q := req.URL.Query()
+	f := NewProbeFilter()
+	f.Name = strings.TrimSpace(q.Get("name"))
+	f.Kind = strings.TrimSpace(q.Get("kind"))
+	f.Status = getStatus(strings.TrimSpace(q.Get("status")))
+	f.Labels = parseLabels(q["label"])
+	f.SLAGreater = getNum(q.Get("gte"), f.SLAGreater, toFloat)
+	f.SLALess = getNum(q.Get("lte"), f.SLALess, toFloat)
+	f.PageNum = getNum(q.Get("pg"), f.PageNum, toInt)
+	f.PageSize = getNum(q.Get("sz"), f.PageSize, toInt)
+	if err := f.Check(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// listProbesHandler returns one page of the filtered probers, they could be sorted by ?sort=sla&order=desc
+func listProbesHandler(w http.ResponseWriter, req *http.Request) {
+	f, err := getFilter(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	page, total := f.Filter(getSortedProbers(req))
+	list := probeList{
+		Total:    total,
+		PageNum:  f.PageNum,
+		PageSize: f.PageSize,
+		Probes:   []probeStatus{},
+	}
+	for _, p := range page {
+		list.Probes = append(list.Probes, newProbeStatus(p))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func getProbeHandler(w http.ResponseWriter, req *http.Request) {
+	name := urlParam(req, "name")
+	if p := findProber(name); p != nil {
+		writeJSON(w, http.StatusOK, newProbeStatus(p))
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("probe [%s] is not found", name))
+}
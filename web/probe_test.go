@@ -0,0 +1,78 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func getProbeList(t *testing.T, url string) (probeList, int) {
+	resp := doRequest(t, http.MethodGet, url, "")
+	defer resp.Body.Close()
+	var list probeList
+	if resp.StatusCode == http.StatusOK {
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&list))
+	}
+	return list, resp.StatusCode
+}
+
+func TestProbesAPI(t *testing.T) {
+	SetProbers(newDummyProbers())
+	defer func() { probers = nil }()
+
+	srv := newAPIServer()
+	defer srv.Close()
+	api := srv.URL + "/api/v1/probes"
+
+	list, code := getProbeList(t, api)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 4, list.Total)
+	assert.Equal(t, 1, list.PageNum)
+	assert.Equal(t, global.DefaultPageSize, list.PageSize)
+	assert.Equal(t, "Web Site", list.Probes[0].Name)
+	assert.Equal(t, "http", list.Probes[0].Kind)
+	assert.Equal(t, "prod", list.Probes[0].Labels["env"])
+	assert.Equal(t, 100.0, list.Probes[0].SLA)
+	assert.Equal(t, probe.StatusUp, list.Probes[0].Status)
+
+	list, _ = getProbeList(t, api+"?kind=http&status=down")
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, "Web API", list.Probes[0].Name)
+	assert.Equal(t, 10*60.0, list.Probes[0].Stat.DownTime.Seconds())
+
+	list, _ = getProbeList(t, api+"?label=env=prod&label=team&lte=100")
+	assert.Equal(t, 1, list.Total)
+
+	list, _ = getProbeList(t, api+"?gte=95&sz=1&pg=2")
+	assert.Equal(t, 2, list.Total)
+	assert.Equal(t, 1, len(list.Probes))
+	assert.Equal(t, "Database", list.Probes[0].Name)
+
+	_, code = getProbeList(t, api+"?gte=99&lte=10")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// single probe
+	resp := doRequest(t, http.MethodGet, api+"/"+url.PathEscape("Web API"), "")
+	var s probeStatus
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&s))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Web API", s.Name)
+	assert.Equal(t, "http://Web API", s.Endpoint)
+	assert.Equal(t, 90.0, s.SLA)
+
+	resp = doRequest(t, http.MethodGet, api+"/none", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// no probers
+	probers = nil
+	list, _ = getProbeList(t, api)
+	assert.Equal(t, 0, list.Total)
+	assert.NotNil(t, list.Probes)
+}
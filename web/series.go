@@ -0,0 +1,137 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/megaease/easeprobe/store"
+)
+
+// maxSeriesPoints is the max number of the points of the time series query
+const maxSeriesPoints = 10000
+
+// dashboardSeriesWindow is the time span of the series bars in the dashboard, one bar per hour
+const dashboardSeriesWindow = 24 * time.Hour
+
+// probeSeries is the time series of the prober
+type probeSeries struct {
+	Name   string        `json:"name"`
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Step   time.Duration `json:"step"`
+	Points []store.Point `json:"points"`
+}
+
+// seriesHandler returns the time series of the prober from the time-series store,
+// e.g. ?from=7d&to=2024-01-08&step=1h, the `from` is 24h before by default,
+// the `to` is now by default, and the `step` is the resolution of the store by default
+func seriesHandler(w http.ResponseWriter, req *http.Request) {
+	name := urlParam(req, "name")
+	if findProber(name) == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("probe [%s] is not found", name))
+		return
+	}
+	st := store.Default()
+	if st == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("the time-series store is disabled"))
+		return
+	}
+
+	q := req.URL.Query()
+	var err error
+	to := time.Now()
+	if s := strings.TrimSpace(q.Get("to")); s != "" {
+		if to, err = getTime(s); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	from := to.Add(-24 * time.Hour)
+	if s := strings.TrimSpace(q.Get("from")); s != "" {
+		if from, err = getTime(s); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("the period [%s, %s) is invalid",
+			from.Format(time.RFC3339), to.Format(time.RFC3339)))
+		return
+	}
+	step := st.Resolution
+	if s := strings.TrimSpace(q.Get("step")); s != "" {
+		if step, err = time.ParseDuration(s); err != nil || step < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid step [%s], it should be a positive duration", s))
+			return
+		}
+	}
+	if step > 0 && to.Sub(from)/step > maxSeriesPoints {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("too many points, the step [%s] is too small for the period", step))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, probeSeries{
+		Name:   name,
+		From:   from,
+		To:     to,
+		Step:   step,
+		Points: st.Series(name, from, to, step),
+	})
+}
+
+// pointStatus returns the representative status of the point for the color
+func pointStatus(p store.Point) probe.Status {
+	switch {
+	case p.Down == 0:
+		return probe.StatusUp
+	case p.Up == 0:
+		return probe.StatusDown
+	}
+	return probe.StatusUnknown
+}
+
+// seriesBars renders the points as the inline SVG bars,
+// the color is the availability and the height is the average round trip time
+func seriesBars(points []store.Point) template.HTML {
+	if len(points) == 0 {
+		return "-"
+	}
+	const width, height, gap = 4, 20, 1
+	var max time.Duration
+	for _, p := range points {
+		if p.RTT > max {
+			max = p.RTT
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg width="%d" height="%d" xmlns="http://www.w3.org/2000/svg">`,
+		len(points)*(width+gap), height)
+	for i, p := range points {
+		h := height
+		if max > 0 {
+			// keep a minimal height for the visibility
+			h = 4 + int(float64(height-4)*float64(p.RTT)/float64(max))
+		}
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%s %.2f%% %s</title></rect>`,
+			i*(width+gap), height-h, width, h, report.StatusColor(pointStatus(p)).Hex(),
+			template.HTMLEscapeString(report.FormatTime(p.Time)), p.Uptime, p.RTT.Round(time.Millisecond))
+	}
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// dashboardBars renders the hourly bars of the last 24 hours from the time-series store,
+// or the bars of the recent records if the store is disabled
+func dashboardBars(s probeStatus) template.HTML {
+	st := store.Default()
+	if st == nil {
+		return historyBars(s.History.Records())
+	}
+	now := time.Now()
+	return seriesBars(st.Series(s.Name, now.Add(-dashboardSeriesWindow), now, time.Hour))
+}
@@ -0,0 +1,164 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupStore opens the default store with the records of the last `n` minutes of the prober,
+// the records are at the beginning of the minutes
+func setupStore(t *testing.T, p probe.Prober, n int) (*store.Store, time.Time) {
+	st, err := store.Open(store.Settings{Dir: t.TempDir()})
+	assert.Nil(t, err)
+	now := time.Now().UTC().Truncate(time.Minute)
+	for i := n; i > 0; i-- {
+		r := p.Result().Clone()
+		r.StartTime = now.Add(-time.Duration(i) * time.Minute)
+		r.Status = probe.StatusUp
+		if i%4 == 0 {
+			r.Status = probe.StatusDown
+		}
+		r.RoundTripTime = time.Duration(i) * time.Millisecond
+		assert.Nil(t, st.Append(r))
+	}
+	store.SetDefault(st)
+	return st, now
+}
+
+func closeStore(st *store.Store) {
+	st.Close()
+	store.SetDefault(nil)
+}
+
+func TestSeriesAPI(t *testing.T) {
+	list := newDummyProbers()
+	SetProbers(list)
+	defer func() { probers = nil }()
+
+	srv := newAPIServer()
+	defer srv.Close()
+	api := srv.URL + "/api/v1/probes/" + url.PathEscape("Web API") + "/series"
+
+	get := func(query string) (probeSeries, int) {
+		resp := doRequest(t, http.MethodGet, api+query, "")
+		defer resp.Body.Close()
+		var s probeSeries
+		if resp.StatusCode == http.StatusOK {
+			assert.Nil(t, json.NewDecoder(resp.Body).Decode(&s))
+		}
+		return s, resp.StatusCode
+	}
+
+	// the store is disabled
+	_, code := get("")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	st, now := setupStore(t, list[1], 8)
+	defer closeStore(st)
+
+	s, code := get("?from=1h&step=0s")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Web API", s.Name)
+	assert.Equal(t, time.Duration(0), s.Step)
+	assert.Equal(t, 1, len(s.Points))
+	assert.Equal(t, int64(8), s.Points[0].Count)
+	assert.Equal(t, int64(2), s.Points[0].Down)
+	assert.Equal(t, 75.0, s.Points[0].Uptime)
+
+	s, _ = get("")
+	assert.Equal(t, time.Hour, s.Step)
+	assert.Equal(t, 24*time.Hour, s.To.Sub(s.From))
+	total := int64(0)
+	for _, p := range s.Points {
+		total += p.Count
+	}
+	assert.Equal(t, int64(8), total)
+
+	s, _ = get("?from=" + now.Add(-3*time.Minute).Format(time.RFC3339) + "&step=1m")
+	assert.Equal(t, 3, len(s.Points))
+
+	for _, q := range []string{"?from=bad", "?to=bad", "?step=bad", "?step=-1m", "?from=1h&to=2h", "?from=30d&step=1s"} {
+		_, code = get(q)
+		assert.Equal(t, http.StatusBadRequest, code, q)
+	}
+
+	resp := doRequest(t, http.MethodGet, srv.URL+"/api/v1/probes/none/series", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHistoryStore(t *testing.T) {
+	list := newDummyProbers()
+	appendHistory(list[1], 2)
+	SetProbers(list)
+	defer func() { probers = nil }()
+	st, now := setupStore(t, list[1], 8)
+	defer closeStore(st)
+
+	srv := newAPIServer()
+	defer srv.Close()
+	api := srv.URL + "/api/v1/probes/" + url.PathEscape("Web API") + "/history"
+
+	get := func(query string) (probeHistory, int) {
+		resp := doRequest(t, http.MethodGet, api+query, "")
+		defer resp.Body.Close()
+		var h probeHistory
+		if resp.StatusCode == http.StatusOK {
+			assert.Nil(t, json.NewDecoder(resp.Body).Decode(&h))
+		}
+		return h, resp.StatusCode
+	}
+
+	// the recent records of the prober
+	h, _ := get("")
+	assert.Equal(t, 2, h.Total)
+	assert.Equal(t, "record", h.Records[0].Message)
+
+	// the records of the store
+	h, _ = get("?since=1h")
+	assert.Equal(t, 8, h.Total)
+	assert.Equal(t, 75.0, h.Uptime)
+	assert.Equal(t, "", h.Records[0].Message)
+	assert.Equal(t, 8*time.Millisecond, h.Records[0].RTT)
+
+	h, _ = get("?since=1h&until=" + now.Add(-2*time.Minute).Format(time.RFC3339))
+	assert.Equal(t, 6, h.Total)
+
+	_, code := get("?since=1h&until=bad")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestSeriesBars(t *testing.T) {
+	assert.Equal(t, "-", string(seriesBars(nil)))
+
+	now := time.Now()
+	svg := string(seriesBars([]store.Point{
+		{Time: now, Count: 2, Up: 2, Uptime: 100, RTT: time.Millisecond},
+		{Time: now, Count: 2, Down: 2, RTT: 2 * time.Millisecond},
+		{Time: now, Count: 2, Up: 1, Down: 1, Uptime: 50},
+	}))
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Equal(t, 3, strings.Count(svg, "<rect"))
+	assert.Contains(t, svg, "#36A64F")
+	assert.Contains(t, svg, "#E01E5A")
+	assert.Contains(t, svg, "50.00%")
+
+	// the dashboard uses the store if it's enabled
+	p := newDummyProber("bars", "http", probe.StatusUp, 100, nil)
+	appendHistory(p, 5)
+	s := newProbeStatus(p)
+	assert.Equal(t, 5, strings.Count(string(dashboardBars(s)), "<rect"))
+	st, _ := setupStore(t, p, 90)
+	defer closeStore(st)
+	bars := string(dashboardBars(s))
+	assert.True(t, strings.Count(bars, "<rect") >= 2)
+	assert.True(t, strings.Count(bars, "<rect") <= 3)
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/megaease/easeprobe/conf"
+	"github.com/megaease/easeprobe/event"
 	"github.com/megaease/easeprobe/global"
 	"github.com/megaease/easeprobe/probe"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -25,7 +26,10 @@ var probers *[]probe.Prober
 var webServer *http.Server
 
 func getRefreshInterval(refersh string) time.Duration {
-	interval := conf.Get().Settings.HTTPServer.AutoRefreshTime
+	interval := global.DefaultProbeInterval
+	if c := conf.Get(); c != nil && c.Settings.HTTPServer.AutoRefreshTime > 0 {
+		interval = c.Settings.HTTPServer.AutoRefreshTime
+	}
 	if strings.TrimSpace(refersh) == "" {
 		return interval
 	}
@@ -114,18 +118,47 @@ func Server() {
 	r.Use(middleware.RedirectSlashes)
 	r.Use(middleware.StripSlashes)
 
+	// Configure the authentication
+	if authEnabled(c.Settings.HTTPServer.Auth) {
+		log.Infof("[Web] Authentication is enabled, open paths: %v", c.Settings.HTTPServer.Auth.Open)
+		r.Use(authMiddleware(c.Settings.HTTPServer.Auth))
+	}
+
+	r.Get("/", dashboardHandler)
+	statusRoutes(r)
+	healthRoutes(r)
+	badgeRoutes(r)
 	r.Get("/metrics", promhttp.Handler().ServeHTTP)
+	apiRoutes(r, c.Settings.HTTPServer.Auth)
+
+	// Configure the TLS
+	tlsConfig, err := c.Settings.HTTPServer.TLS.ServerConfig()
+	if err != nil {
+		log.Fatalf("[Web] Invalid TLS configuration: %s", err)
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
 
 	server, err := net.Listen("tcp", host+":"+port)
 	if err != nil {
 		log.Fatalf("[Web] Failed to start the http server: %s", err)
 	}
-	log.Infof("[Web] HTTP server is listening on %s:%s", host, port)
+	log.Infof("[Web] HTTP server is listening on %s://%s:%s", scheme, host, port)
 
 	// Start the http server
 	go func() {
-		webServer = &http.Server{Handler: r}
-		if err := webServer.Serve(server); !errors.Is(err, http.ErrServerClosed) {
+		webServer = &http.Server{Handler: r, TLSConfig: tlsConfig}
+		// the event streaming connections are closed by closing the event bus
+		webServer.RegisterOnShutdown(event.Close)
+		var err error
+		if tlsConfig != nil {
+			err = webServer.ServeTLS(server, "", "")
+		} else {
+			err = webServer.Serve(server)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
 			log.Errorf("[Web] HTTP server error: %s", err)
 		}
 		log.Info("[Web] HTTP server is stopped.")
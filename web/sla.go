@@ -0,0 +1,158 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+)
+
+// slaReport is the JSON response of the SLA API, the probes are grouped if the group label is specified
+type slaReport struct {
+	From   time.Time         `json:"from,omitzero"`
+	To     time.Time         `json:"to,omitzero"`
+	Group  string            `json:"group,omitempty"`
+	Probes []report.SLA      `json:"probes,omitempty"`
+	Groups []report.SLAGroup `json:"groups,omitempty"`
+}
+
+// getTime parses the time of the SLA period, it could be a duration before now (e.g. 24h),
+// a date in the global time zone (e.g. 2024-01-01) or a RFC3339 time
+func getTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, global.GetTimeLocation()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time [%s], it should be a duration, a date or RFC3339 time", s)
+}
+
+// getPeriod parses the SLA period from the query, e.g. ?month=2024-01 or ?from=2024-01-01&to=2024-01-15,
+// the `to` is exclusive and it is now by default, the zero period means since the probe starts
+func getPeriod(req *http.Request) (time.Time, time.Time, error) {
+	q := req.URL.Query()
+	if month := strings.TrimSpace(q.Get("month")); month != "" {
+		from, err := time.ParseInLocation("2006-01", month, global.GetTimeLocation())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid month [%s], it should be 2006-01", month)
+		}
+		return from, from.AddDate(0, 1, 0), nil
+	}
+	if q.Get("from") == "" && q.Get("to") == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+	if q.Get("from") == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("the from of the period is required")
+	}
+	from, err := getTime(q.Get("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to := time.Now()
+	if s := q.Get("to"); s != "" {
+		if to, err = getTime(s); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the period [%s, %s) is invalid",
+			from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	return from, to, nil
+}
+
+// slaTitle return the title of the SLA report with the period
+func slaTitle(from, to time.Time) string {
+	if from.IsZero() {
+		return "SLA Report"
+	}
+	return fmt.Sprintf("SLA Report from %s to %s", report.FormatTime(from), report.FormatTime(to))
+}
+
+// slaFileName return the file name of the downloaded SLA report
+func slaFileName(from, to time.Time, ext string) string {
+	if from.IsZero() {
+		return "sla." + ext
+	}
+	const f = "20060102T150405"
+	loc := global.GetTimeLocation()
+	return fmt.Sprintf("sla-%s-%s.%s", from.In(loc).Format(f), to.In(loc).Format(f), ext)
+}
+
+func writeDownload(w http.ResponseWriter, contentType, file, body string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body))
+}
+
+// slaHandler returns the SLA of the filtered probers for the period, e.g.
+// ?month=2024-01&format=csv&group=env&kind=http, the format could be json (default), csv or md
+func slaHandler(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(q.Get("format")))
+	if format == "markdown" {
+		format = "md"
+	}
+	if format != "" && format != "json" && format != "csv" && format != "md" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid format [%s], it should be json, csv or md", format))
+		return
+	}
+	f, err := getFilter(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	from, to, err := getPeriod(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	slas := []report.SLA{}
+	for _, p := range getProbers() {
+		if probe.Snapshot(p).Status == probe.StatusBad || !f.Match(p) {
+			continue
+		}
+		if from.IsZero() {
+			slas = append(slas, report.NewSLA(p))
+		} else {
+			slas = append(slas, report.NewSLARange(p, from, to))
+		}
+	}
+	group := strings.TrimSpace(q.Get("group"))
+	title := slaTitle(from, to)
+
+	switch format {
+	case "csv":
+		body := report.SLACSV(slas)
+		if group != "" {
+			body = report.SLAGroupCSV(group, report.GroupSLA(slas, group))
+		}
+		writeDownload(w, "text/csv; charset=utf-8", slaFileName(from, to, "csv"), body)
+	case "md":
+		body := report.SLAMarkdown(title, slas)
+		if group != "" {
+			body = report.SLAGroupMarkdown(title, group, report.GroupSLA(slas, group))
+		}
+		writeDownload(w, "text/markdown; charset=utf-8", slaFileName(from, to, "md"), body)
+	default:
+		r := slaReport{From: from, Group: group, Probes: slas}
+		if !from.IsZero() {
+			r.To = to
+		}
+		if group != "" {
+			r.Probes, r.Groups = nil, report.GroupSLA(slas, group)
+		}
+		writeJSON(w, http.StatusOK, r)
+	}
+}
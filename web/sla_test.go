@@ -0,0 +1,135 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/global"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPeriod(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	period := func(query string) (time.Time, time.Time, error) {
+		return getPeriod(httptest.NewRequest(http.MethodGet, "/api/v1/sla?"+query, nil))
+	}
+
+	from, to, err := period("")
+	assert.Nil(t, err)
+	assert.True(t, from.IsZero() && to.IsZero())
+
+	from, to, err = period("month=2022-02")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), to)
+
+	from, to, err = period("from=2022-01-01&to=2022-01-02T12:00:00Z")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2022, 1, 2, 12, 0, 0, 0, time.UTC), to)
+
+	from, to, err = period("from=24h")
+	assert.Nil(t, err)
+	assert.InDelta(t, 24*time.Hour, to.Sub(from), float64(time.Second))
+
+	for _, q := range []string{"month=2022", "from=yesterday", "to=bad", "from=2022-01-02&to=2022-01-01", "to=2000-01-01"} {
+		_, _, err = period(q)
+		assert.NotNil(t, err, q)
+	}
+}
+
+func TestSLAFileName(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	assert.Equal(t, "sla.csv", slaFileName(time.Time{}, time.Time{}, "csv"))
+	from := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "sla-20220201T000000-20220301T000000.md", slaFileName(from, from.AddDate(0, 1, 0), "md"))
+	assert.Equal(t, "SLA Report", slaTitle(time.Time{}, time.Time{}))
+	assert.Equal(t, "SLA Report from 2022-02-01 00:00:00 to 2022-03-01 00:00:00", slaTitle(from, from.AddDate(0, 1, 0)))
+}
+
+func TestSLAAPI(t *testing.T) {
+	global.InitEaseProbeWithTime("EaseProbe", "icon", "2006-01-02 15:04:05", "UTC")
+	list := newDummyProbers()
+	month := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	web := list[0].(*dummyProber).result
+	web.Daily = probe.Daily{{Date: "2022-02-10", UpTime: 9 * time.Hour, DownTime: time.Hour}}
+	web.Incidents = probe.Incidents{{Start: month.Add(time.Hour), End: month.Add(2 * time.Hour), Status: probe.StatusDown}}
+	SetProbers(list)
+	defer SetProbers(nil)
+
+	srv := newAPIServer()
+	defer srv.Close()
+	api := srv.URL + "/api/v1/sla"
+
+	// since the probes start
+	resp := doRequest(t, http.MethodGet, api, "")
+	var r slaReport
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&r))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 4, len(r.Probes))
+	assert.True(t, r.From.IsZero())
+	assert.Equal(t, 90.0, r.Probes[1].SLA)
+
+	// the month with the filter
+	resp = doRequest(t, http.MethodGet, api+"?month=2022-02&kind=http", "")
+	r = slaReport{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&r))
+	resp.Body.Close()
+	assert.Equal(t, month, r.From)
+	assert.Equal(t, month.AddDate(0, 1, 0), r.To)
+	assert.Equal(t, 2, len(r.Probes))
+	assert.Equal(t, "Web Site", r.Probes[0].Name)
+	assert.Equal(t, 90.0, r.Probes[0].SLA)
+	assert.Equal(t, 1, r.Probes[0].Failures)
+	assert.Equal(t, time.Hour, r.Probes[0].LongestOutage)
+	assert.Equal(t, float64(0), r.Probes[1].SLA)
+
+	// grouped by the label
+	resp = doRequest(t, http.MethodGet, api+"?group=env", "")
+	r = slaReport{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&r))
+	resp.Body.Close()
+	assert.Equal(t, "env", r.Group)
+	assert.Nil(t, r.Probes)
+	assert.Equal(t, []string{"", "prod", "test"}, []string{r.Groups[0].Name, r.Groups[1].Name, r.Groups[2].Name})
+	assert.Equal(t, 2, len(r.Groups[1].Probes))
+
+	// CSV download
+	resp = doRequest(t, http.MethodGet, api+"?month=2022-02&format=csv&group=env", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="sla-20220201T000000-20220301T000000.csv"`, resp.Header.Get("Content-Disposition"))
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	resp.Body.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(rows))
+	assert.Equal(t, "env", rows[0][0])
+
+	// Markdown download
+	resp = doRequest(t, http.MethodGet, api+"?format=markdown", "")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "text/markdown; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, report.SLAMarkdown("SLA Report", report.SLAs(list)), string(body))
+
+	resp = doRequest(t, http.MethodGet, api+"?format=md&group=team", "")
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.True(t, strings.Contains(string(body), "### team: web"))
+
+	// bad requests
+	for _, q := range []string{"?format=xml", "?month=bad", "?gte=200"} {
+		resp = doRequest(t, http.MethodGet, api+q, "")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, q)
+	}
+}
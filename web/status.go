@@ -0,0 +1,89 @@
+package web
+
+import (
+	_ "embed" // embed the status page template
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/report"
+	"github.com/megaease/easeprobe/statuspage"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed templates/status.html
+var statusHTML string
+
+var statusTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"emoji":    func(s probe.Status) string { return s.Emoji() },
+	"duration": func(d time.Duration) string { return d.Round(time.Second).String() },
+	"uptime":   uptimeText,
+	"day":      dayClass,
+	"time":     report.FormatTime,
+}).Parse(statusHTML))
+
+// statusRoutes registers the routes of the public status page
+func statusRoutes(r chi.Router) {
+	r.Get("/status", statusPageHandler)
+	r.Get("/status.json", statusJSONHandler)
+}
+
+// uptimeText returns the uptime percentage, or "N/A" if there is no data
+func uptimeText(percent float64) string {
+	if percent < 0 {
+		return "N/A"
+	}
+	return strconv.FormatFloat(percent, 'f', 2, 64) + "%"
+}
+
+// dayClass returns the CSS class of the daily uptime bar
+func dayClass(percent float64) string {
+	switch {
+	case percent < 0:
+		return "none"
+	case percent >= 99.9:
+		return "up"
+	case percent >= 95:
+		return "partial"
+	default:
+		return "down"
+	}
+}
+
+// buildStatusReport returns the report of the status page, false if the status page is disabled
+func buildStatusReport() (statuspage.Report, bool) {
+	page := statuspage.GetPage()
+	if page == nil {
+		return statuspage.Report{}, false
+	}
+	return page.Build(getProbers(), time.Now()), true
+}
+
+func statusPageHandler(w http.ResponseWriter, req *http.Request) {
+	r, ok := buildStatusReport()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	data := struct {
+		statuspage.Report
+		Refresh int
+	}{r, int(getRefreshInterval("").Seconds())}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusTemplate.Execute(w, data); err != nil {
+		log.Errorf("[Web] Failed to render the status page: %v", err)
+	}
+}
+
+func statusJSONHandler(w http.ResponseWriter, req *http.Request) {
+	r, ok := buildStatusReport()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	writeJSON(w, http.StatusOK, r)
+}
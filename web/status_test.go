@@ -0,0 +1,82 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/megaease/easeprobe/probe"
+	"github.com/megaease/easeprobe/statuspage"
+	"github.com/stretchr/testify/assert"
+)
+
+// publicProber is the dummy prober which could be shown on the status page
+type publicProber struct {
+	*dummyProber
+}
+
+func (p publicProber) IsPublic() bool { return true }
+
+func newStatusServer() *httptest.Server {
+	r := chi.NewRouter()
+	statusRoutes(r)
+	return httptest.NewServer(r)
+}
+
+func TestStatusPage(t *testing.T) {
+	list := newDummyProbers()
+	list[0] = publicProber{list[0].(*dummyProber)}
+	list[0].Result().Daily.Add(time.Now(), true, time.Hour)
+	list[1].Result().Incidents.Update(time.Now(), probe.StatusDown, "10.0.0.1 is refused")
+	SetProbers(list)
+	defer SetProbers(nil)
+
+	srv := newStatusServer()
+	defer srv.Close()
+
+	// the status page is disabled
+	statuspage.SetPage(statuspage.Page{})
+	for _, path := range []string{"/status", "/status.json"} {
+		resp := doRequest(t, http.MethodGet, srv.URL+path, "")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	statuspage.SetPage(statuspage.Page{Title: "Public Status", Days: 30, Services: []statuspage.Service{
+		{Name: "Website", Probes: []string{"Web Site", "Web API"}},
+	}})
+	defer statuspage.SetPage(statuspage.Page{})
+
+	resp := doRequest(t, http.MethodGet, srv.URL+"/status.json", "")
+	var r statuspage.Report
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&r))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Public Status", r.Title)
+	assert.Equal(t, statuspage.StatePartialOutage, r.State)
+	assert.Equal(t, 30, len(r.Services[0].Days))
+	assert.Equal(t, []string{"Web Site"}, []string{r.Services[0].Probes[0].Name})
+	assert.Equal(t, 1, len(r.Incidents))
+	assert.Equal(t, "", r.Incidents[0].Probe)
+
+	html, code := getDashboard(t, srv.URL+"/status")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, html, "Public Status")
+	assert.Contains(t, html, "Partial Outage")
+	assert.Contains(t, html, "Web Site")
+	assert.NotContains(t, html, "Web API")
+	assert.NotContains(t, html, "10.0.0.1")
+	assert.NotContains(t, html, "http://")
+}
+
+func TestDayClass(t *testing.T) {
+	assert.Equal(t, "none", dayClass(-1))
+	assert.Equal(t, "up", dayClass(100))
+	assert.Equal(t, "partial", dayClass(98))
+	assert.Equal(t, "down", dayClass(50))
+	assert.Equal(t, "N/A", uptimeText(-1))
+	assert.Equal(t, "99.50%", uptimeText(99.5))
+}